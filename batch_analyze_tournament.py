@@ -12,6 +12,14 @@ from concurrent.futures import ProcessPoolExecutor, as_completed
 import sys
 import papermill as pm
 
+def find_minimal_csv(matchup_folder):
+    """Return the combined minimal CSV of a matchup (plain, .gz or .zst), or None."""
+    for name in ("all_games_minimal.csv", "all_games_minimal.csv.gz", "all_games_minimal.csv.zst"):
+        csv_file = matchup_folder / name
+        if csv_file.exists():
+            return csv_file
+    return None
+
 def find_matchup_folders(tournament_folder):
     """Find all matchup_XXX folders in the tournament directory."""
     matchup_folders = []
@@ -23,10 +31,10 @@ def find_matchup_folders(tournament_folder):
     
     for item in sorted(tournament_path.iterdir()):
         if item.is_dir() and item.name.startswith('matchup_'):
-            csv_file = item / "all_games_minimal.csv"
+            csv_file = find_minimal_csv(item)
             json_file = item / "simulation_summary.json"
             
-            if csv_file.exists() and json_file.exists():
+            if csv_file is not None and json_file.exists():
                 matchup_folders.append(item)
             else:
                 print(f"⚠️  Skipping {item.name} - missing CSV or JSON file")
@@ -43,7 +51,7 @@ def analyze_matchup(matchup_folder, notebook_path, ENABLE_FIRST_ROUND_ANALYSIS=F
     
     try:
         # Paths
-        csv_path = str((matchup_folder / "all_games_minimal.csv").resolve())
+        csv_path = str(find_minimal_csv(matchup_folder).resolve())
         json_path = str((matchup_folder / "simulation_summary.json").resolve())
         folder_path = str(matchup_folder.resolve())
         
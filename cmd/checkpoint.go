@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"dbg_abm/internal/analysis"
+	"dbg_abm/internal/engine"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// simulationManifestFile is written into the export directory of a concurrent batch run
+const simulationManifestFile = "simulation_manifest.json"
+
+// defaultCheckpointEvery is the number of simulations run between two checkpoints
+const defaultCheckpointEvery = 10000
+
+// SimulationManifest records how far a batch of simulations got so that it can be resumed
+type SimulationManifest struct {
+	Version           int                       `json:"version"`
+	RulesHash         string                    `json:"rules_hash"`
+	DistributionsHash string                    `json:"distributions_hash"`
+	Team1Strategy     string                    `json:"team1_strategy"`
+	Team2Strategy     string                    `json:"team2_strategy"`
+	NumSimulations    int                       `json:"num_simulations"`
+	BaseSeed          int64                     `json:"base_seed,omitempty"`
+	CSVExportMode     int                       `json:"csv_export_mode"`
+	Compress          string                    `json:"compress,omitempty"`
+	Completed         int                       `json:"completed"` // Simulations run by finished checkpoints
+	Stats             *analysis.SimulationStats `json:"stats,omitempty"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+}
+
+func newSimulationManifest(config SimulationConfig) SimulationManifest {
+	return SimulationManifest{
+		Version:           1,
+		RulesHash:         config.GameRules.Hash(),
+		DistributionsHash: engine.DistributionsHash(),
+		Team1Strategy:     config.Team1Strategy,
+		Team2Strategy:     config.Team2Strategy,
+		NumSimulations:    config.NumSimulations,
+		BaseSeed:          config.BaseSeed,
+		CSVExportMode:     config.CSVExportMode,
+		Compress:          config.Compress,
+	}
+}
+
+// compatible reports why prev cannot be resumed with the manifest m (nil if it can)
+func (m SimulationManifest) compatible(prev *SimulationManifest) error {
+	switch {
+	case prev.RulesHash != m.RulesHash:
+		return fmt.Errorf("game rules changed (hash %.12s, manifest has %.12s)", m.RulesHash, prev.RulesHash)
+	case prev.DistributionsHash != m.DistributionsHash:
+		return fmt.Errorf("distributions changed (hash %.12s, manifest has %.12s)", m.DistributionsHash, prev.DistributionsHash)
+	case prev.Team1Strategy != m.Team1Strategy || prev.Team2Strategy != m.Team2Strategy:
+		return fmt.Errorf("strategies changed (%s vs %s, manifest has %s vs %s)", m.Team1Strategy, m.Team2Strategy, prev.Team1Strategy, prev.Team2Strategy)
+	case prev.NumSimulations != m.NumSimulations:
+		return fmt.Errorf("number of simulations changed (%d, manifest has %d)", m.NumSimulations, prev.NumSimulations)
+	case prev.BaseSeed != m.BaseSeed:
+		return fmt.Errorf("seed changed (%d, manifest has %d)", m.BaseSeed, prev.BaseSeed)
+	case prev.CSVExportMode != m.CSVExportMode:
+		return fmt.Errorf("CSV export mode changed (%d, manifest has %d)", m.CSVExportMode, prev.CSVExportMode)
+	case prev.Compress != m.Compress:
+		return fmt.Errorf("compression changed (%q, manifest has %q)", m.Compress, prev.Compress)
+	case prev.Stats == nil && prev.Completed > 0:
+		return fmt.Errorf("manifest has no statistics for its %d completed simulations", prev.Completed)
+	}
+	return nil
+}
+
+func loadSimulationManifest(path string) (*SimulationManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read simulation manifest: %w", err)
+	}
+	var m SimulationManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse simulation manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// save writes the manifest atomically (temp file + rename)
+func (m *SimulationManifest) save(path string) error {
+	m.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write simulation manifest: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// removeGameFilesAfter deletes the per-game exports (-e, --rounds, --csv 1 and 3) of the
+// simulations after the first `completed` ones. They belong to the interrupted chunk,
+// which is played again under new game IDs on resume.
+func removeGameFilesAfter(dir string, completed int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		// Per-game files are named sim_<SimID>_<game ID>..., SimIDs start at 1
+		rest, ok := strings.CutPrefix(entry.Name(), "sim_")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		id, _, ok := strings.Cut(rest, "_")
+		if !ok {
+			continue
+		}
+		if simID, err := strconv.Atoi(id); err == nil && simID > completed {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RunResumableSimulations runs RunParallelSimulations in checkpoints of `every` simulations
+// and records the progress in simulation_manifest.json. With resume set it continues the
+// run recorded in the export directory instead of starting over.
+func RunResumableSimulations(config SimulationConfig, every int, resume bool) (*analysis.SimulationStats, error) {
+	if every <= 0 {
+		every = defaultCheckpointEvery
+	}
+	manifestPath := filepath.Join(config.Exportpath, simulationManifestFile)
+	manifest := newSimulationManifest(config)
+
+	if resume {
+		prev, err := loadSimulationManifest(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := manifest.compatible(prev); err != nil {
+			return nil, fmt.Errorf("cannot resume from %s: %w", config.Exportpath, err)
+		}
+		manifest.Completed = prev.Completed
+		manifest.Stats = prev.Stats
+		if config.Dashboard != nil && prev.Stats != nil {
+			config.Dashboard.Restore(prev.Stats)
+		}
+		if config.Metrics != nil && prev.Stats != nil {
+			config.Metrics.Restore(config.MatchupIndex, prev.Stats)
+		}
+		if err := removeGameFilesAfter(config.Exportpath, manifest.Completed); err != nil {
+			return nil, err
+		}
+	} else {
+		// Written before the first game, so that a run interrupted before its first
+		// checkpoint can be resumed as well
+		if err := os.MkdirAll(config.Exportpath, 0755); err != nil {
+			return nil, err
+		}
+		if err := manifest.save(manifestPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Small fresh runs need no intermediate checkpoints
+	if !resume && config.NumSimulations <= every {
+		stats, err := RunParallelSimulations(config)
+		if err != nil {
+			return stats, err
+		}
+		manifest.Completed = config.NumSimulations
+		manifest.Stats = stats
+		if err := manifest.save(manifestPath); err != nil {
+			fmt.Fprintf(console, "Warning: %v\n", err)
+		}
+		return stats, nil
+	}
+
+	if !config.SuppressOutput {
+		fmt.Fprintf(console, "Starting %d simulations with %d concurrent workers (checkpoint every %d)...\n",
+			config.NumSimulations, config.MaxConcurrent, every)
+		if manifest.Completed > 0 {
+			fmt.Fprintf(console, "Resuming from checkpoint: %d/%d simulations already completed\n", manifest.Completed, config.NumSimulations)
+		}
+	}
+
+	summaryPath := filepath.Join(config.Exportpath, "simulation_summary.json")
+	for manifest.Completed < config.NumSimulations {
+		chunk := config
+		chunk.NumSimulations = every
+		if rest := config.NumSimulations - manifest.Completed; chunk.NumSimulations > rest {
+			chunk.NumSimulations = rest
+		}
+		chunk.SimOffset = manifest.Completed
+		chunk.AppendCSV = config.AppendCSV || manifest.Completed > 0
+		chunk.SuppressOutput = true
+
+		stats, err := RunParallelSimulations(chunk)
+		if errors.Is(err, context.Canceled) && stats != nil {
+			// Export the completed checkpoints plus the interrupted chunk as partial
+			// summary; the manifest keeps pointing at the last complete checkpoint
+			if manifest.Stats == nil {
+				manifest.Stats = stats
+			} else {
+				manifest.Stats.Merge(stats)
+			}
+			manifest.Stats.Partial = true
+			if err := exportSummary(manifest.Stats, summaryPath); err != nil && !config.SuppressOutput {
+				fmt.Fprintf(console, "Warning: Failed to export summary: %v\n", err)
+			}
+			if !config.SuppressOutput {
+				fmt.Fprintf(console, "⚠️  Partial results: %d/%d simulations completed (resume with --resume %s)\n",
+					manifest.Stats.CompletedSims, config.NumSimulations, config.Exportpath)
+			}
+			return manifest.Stats, err
+		}
+		if err != nil {
+			return manifest.Stats, err
+		}
+		if manifest.Stats == nil {
+			manifest.Stats = stats
+		} else {
+			manifest.Stats.Merge(stats)
+		}
+		manifest.Completed += chunk.NumSimulations
+
+		if err := manifest.save(manifestPath); err != nil {
+			return manifest.Stats, err
+		}
+		if err := exportSummary(manifest.Stats, summaryPath); err != nil && !config.SuppressOutput {
+			fmt.Fprintf(console, "Warning: Failed to export summary: %v\n", err)
+		}
+		if !config.SuppressOutput && config.Dashboard == nil {
+			fmt.Fprintf(console, "Checkpoint: %d/%d simulations completed\n", manifest.Completed, config.NumSimulations)
+		}
+	}
+
+	if !config.SuppressOutput {
+		analysis.PrintEnhancedStats(console, manifest.Stats)
+		fmt.Fprintf(console, "\nResults exported to: %s/\n", config.Exportpath)
+		fmt.Fprintln(console, "- Summary statistics: simulation_summary.json")
+		fmt.Fprintf(console, "- Checkpoint manifest: %s\n", simulationManifestFile)
+	}
+	return manifest.Stats, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestResumeRunInterruptedBeforeCheckpoint interrupts a run smaller than one checkpoint
+// and resumes it: the manifest must exist, and the per-game files of the interrupted
+// chunk must not be left next to the ones of the re-run.
+func TestResumeRunInterruptedBeforeCheckpoint(t *testing.T) {
+	if err := engine.LoadDistributions("../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	rules, err := engine.ProfileRules("cs2_mr12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	config := SimulationConfig{
+		NumSimulations: 40, MaxConcurrent: 1, MemoryLimit: 1024,
+		Team1Name: "A", Team1Strategy: "all_in", Team2Name: "B", Team2Strategy: "min_max_v4",
+		GameRules: rules, CSVExportMode: 3, Exportpath: dir, SuppressOutput: true, BaseSeed: 7,
+	}
+
+	// Interrupted once the first game is in, with the games before the interrupt exported
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := make(chan SimulationResult)
+	go func() {
+		for range stream {
+			cancel()
+		}
+	}()
+	interrupted := config
+	interrupted.Context, interrupted.ResultStream = ctx, stream
+	_, err = RunResumableSimulations(interrupted, 100, false)
+	close(stream)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("interrupted run: error %v, want context.Canceled", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "sim_*_minimal.csv")); len(files) == 0 {
+		t.Fatal("the interrupted run exported no games")
+	}
+	manifest, err := loadSimulationManifest(filepath.Join(dir, simulationManifestFile))
+	if err != nil {
+		t.Fatalf("no manifest after the interrupted run: %v", err)
+	}
+	if manifest.Completed != 0 {
+		t.Fatalf("manifest has %d completed simulations, want 0", manifest.Completed)
+	}
+
+	stats, err := RunResumableSimulations(config, 10, true)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if stats.CompletedSims != 40 {
+		t.Errorf("%d simulations after the resume, want 40", stats.CompletedSims)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "sim_*_minimal.csv"))
+	if len(files) != 40 {
+		names := make([]string, len(files))
+		for i, f := range files {
+			names[i] = filepath.Base(f)
+		}
+		t.Errorf("%d per-game files, want 40: %s", len(files), strings.Join(names, ", "))
+	}
+}
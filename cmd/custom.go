@@ -2,6 +2,7 @@ package main
 
 import (
 	"dbg_abm/internal/engine"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,46 +20,71 @@ func ValidateAndPrepareCustomizations(gameRulesPath, abmModelsPath, exportPath s
 	config := &CustomConfig{}
 
 	// Load ABM models first (mandatory for simulations to run)
-	fmt.Println("🔧 Loading ABM probability models...")
+	fmt.Fprintln(console, "🔧 Loading ABM probability models...")
 	if err := engine.LoadDistributions(abmModelsPath); err != nil {
 		return nil, fmt.Errorf("failed to load ABM models (required): %w", err)
 	}
 	if abmModelsPath != "" {
-		fmt.Printf("✅ ABM probability models loaded successfully from: %s\n", abmModelsPath)
+		fmt.Fprintf(console, "✅ ABM probability models loaded successfully from: %s\n", abmModelsPath)
 	} else {
-		fmt.Println("✅ ABM probability models loaded successfully from default location")
+		fmt.Fprintln(console, "✅ ABM probability models loaded successfully from default location")
 	}
 
 	// Validate and load game rules
-	fmt.Println("🔧 Validating game configuration...")
+	fmt.Fprintln(console, "🔧 Validating game configuration...")
 
-	imported := false
-	// Load game rules (handles default fallback internally)
-	config.GameRules, imported = engine.NewGameRules(gameRulesPath)
-	if imported {
-		fmt.Printf("✅ Custom game rules loaded successfully. Custom game rules loaded from: %s\n", gameRulesPath)
+	// Load game rules; a rules file that cannot be used stops the run instead of falling back to defaults
+	rules, warnings, err := engine.NewGameRules(gameRulesPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(console, "Warning: %s\n", w)
+	}
+	config.GameRules = rules
+	if gameRulesPath != "" && gameRulesPath != "default" {
+		fmt.Fprintf(console, "✅ Custom game rules loaded successfully. Custom game rules loaded from: %s (profile %s)\n", gameRulesPath, config.GameRules.Profile)
 	} else {
-		fmt.Println("✅ Using default game rules.")
+		fmt.Fprintf(console, "✅ Using default game rules (profile %s).\n", config.GameRules.Profile)
 	}
 
-	// Apply custom CSF r value if set in game rules
-	engine.SetCustomCSFRValue(config.GameRules.Custom_CSF_r_value)
+	// A custom CSF r value travels with the rules and is applied per game
+	if r := config.GameRules.Custom_CSF_r_value; r >= 0 {
+		fmt.Fprintf(console, "Using custom CSF r value: %.2f (overriding distributions value: %.2f)\n", r, engine.GetCSFRValue())
+	}
 
 	// Validate export path
 	if err := validateExportPath(exportPath); err != nil {
 		return nil, fmt.Errorf("export path validation failed: %w", err)
 	}
 	config.ExportPath = exportPath
-	fmt.Printf("✅ Export path validated: %s\n", exportPath)
+	fmt.Fprintf(console, "✅ Export path validated: %s\n", exportPath)
+
+	// Record the resolved rules next to the results
+	if err := writeGameRules(config.GameRules, filepath.Join(exportPath, gameRulesFile)); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", gameRulesFile, err)
+	}
 
 	// Mark configuration as validated
 	config.IsValidated = true
-	fmt.Println("✅ All configurations validated successfully!")
-	fmt.Println()
+	fmt.Fprintln(console, "✅ All configurations validated successfully!")
+	fmt.Fprintln(console)
 
 	return config, nil
 }
 
+// gameRulesFile holds the resolved game rules (profile plus overrides) of a run
+const gameRulesFile = "game_rules.json"
+
+// writeGameRules writes rules as indented JSON
+func writeGameRules(rules engine.GameRules, path string) error {
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
 // validateExportPath ensures the export path is valid and accessible
 func validateExportPath(exportPath string) error {
 	// Check if path is absolute or relative
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"dbg_abm/internal/analysis"
+	"dbg_abm/internal/tournament"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// dashboardInterval is the time between two frames of the --tui dashboard
+const dashboardInterval = time.Second
+
+// Dashboard is the live terminal view of --tui. It redraws a block of lines in place from
+// the atomic counters of the SimulationStats of the running batch and, in tournaments,
+// from the matchup events. Output printed while a frame is shown ends up below it.
+type Dashboard struct {
+	mu    sync.Mutex
+	out   io.Writer
+	width int
+	lines int // Height of the last frame, overwritten by the next one
+
+	start    time.Time
+	total    int64                     // Games of the whole run (an estimate for adaptive tournaments)
+	restored int64                     // Games of earlier invocations (resume), not part of the throughput
+	done     dashCounters              // Games of the finished batches of this invocation
+	cur      *analysis.SimulationStats // Batch in progress, nil between batches
+	names    [2]string
+	peakMB   uint64 // Highest heap seen by the dashboard or the memory monitor
+	forcedGC uint32 // Forced GC runs of the finished batches
+
+	// Tournament state
+	tournament   bool
+	participants []string
+	index        map[string]int
+	finished     map[int]dashSeries // Summaries of finished matchups by matchup index
+	matchup      *tournament.Event  // Running matchup (nil between matchups)
+	matchupBase  dashCounters       // Games run before the running matchup started
+	totalMatchup int
+	perMatchup   int64 // Games per matchup (the total is known once the matchups are)
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// dashCounters is a snapshot of the game counters of SimulationStats
+type dashCounters struct {
+	games, failed, team1Wins, team2Wins, draws, overtime int64
+}
+
+func (c *dashCounters) add(o dashCounters) {
+	c.games += o.games
+	c.failed += o.failed
+	c.team1Wins += o.team1Wins
+	c.team2Wins += o.team2Wins
+	c.draws += o.draws
+	c.overtime += o.overtime
+}
+
+func (c dashCounters) sub(o dashCounters) dashCounters {
+	return dashCounters{c.games - o.games, c.failed - o.failed, c.team1Wins - o.team1Wins, c.team2Wins - o.team2Wins, c.draws - o.draws, c.overtime - o.overtime}
+}
+
+func snapshotCounters(stats *analysis.SimulationStats) dashCounters {
+	if stats == nil {
+		return dashCounters{}
+	}
+	return dashCounters{
+		games:     atomic.LoadInt64(&stats.CompletedSims),
+		failed:    atomic.LoadInt64(&stats.FailedSims),
+		team1Wins: atomic.LoadInt64(&stats.Team1Wins),
+		team2Wins: atomic.LoadInt64(&stats.Team2Wins),
+		draws:     atomic.LoadInt64(&stats.Draws),
+		overtime:  atomic.LoadInt64(&stats.OvertimeGames),
+	}
+}
+
+// dashSeries is the result of a finished matchup
+type dashSeries struct {
+	row, col             int
+	team1Wins, team2Wins int
+	games                int
+}
+
+// NewDashboard creates a dashboard that draws to out. Terminal width is taken from
+// $COLUMNS (default 100); longer lines are cut so that redrawing stays in place.
+func NewDashboard(out io.Writer) *Dashboard {
+	width := 100
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 20 {
+		width = n
+	}
+	return &Dashboard{out: out, width: width, finished: make(map[int]dashSeries)}
+}
+
+// isTerminal reports whether f is a character device (the dashboard needs ANSI cursor control)
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Start begins drawing a run of total games
+func (d *Dashboard) Start(total int64) {
+	d.mu.Lock()
+	d.start = time.Now()
+	d.total = total
+	d.stop = make(chan struct{})
+	d.stopped = make(chan struct{})
+	d.mu.Unlock()
+
+	go func() {
+		defer close(d.stopped)
+		ticker := time.NewTicker(dashboardInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.mu.Lock()
+				if d.cur != nil || d.tournament {
+					d.draw()
+				}
+				d.mu.Unlock()
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// StartTournament begins drawing a tournament between participants with games per matchup
+func (d *Dashboard) StartTournament(participants []string, games int) {
+	d.mu.Lock()
+	d.tournament = true
+	d.perMatchup = int64(games)
+	d.participants = participants
+	d.index = make(map[string]int, len(participants))
+	for i, name := range participants {
+		d.index[name] = i
+	}
+	d.mu.Unlock()
+	d.Start(0)
+}
+
+// Stop ends the dashboard; a tournament gets a final frame
+func (d *Dashboard) Stop() {
+	if d.stop == nil {
+		return
+	}
+	close(d.stop)
+	<-d.stopped
+	d.stop = nil
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tournament {
+		d.draw()
+	}
+}
+
+// Restore counts the games of an earlier invocation of a resumed batch
+func (d *Dashboard) Restore(stats *analysis.SimulationStats) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c := snapshotCounters(stats)
+	d.restored += c.games + c.failed
+	d.done.add(c)
+	d.matchupBase.add(c)
+}
+
+// Track makes stats the batch in progress
+func (d *Dashboard) Track(stats *analysis.SimulationStats) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cur = stats
+	if stats.Config != nil {
+		d.names = [2]string{stats.Config.Team1Strategy, stats.Config.Team2Strategy}
+	}
+}
+
+// Untrack adds the finished batch to the totals and draws its last frame
+func (d *Dashboard) Untrack() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.done.add(snapshotCounters(d.cur))
+	if d.cur != nil {
+		d.forcedGC += atomic.LoadUint32(&d.cur.TotalGCRuns)
+	}
+	d.cur = nil
+	d.draw()
+}
+
+// Event updates the matchup matrix from a tournament progress event
+func (d *Dashboard) Event(e tournament.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch e.Type {
+	case tournament.EventTournamentStart:
+		d.totalMatchup = e.TotalMatchup
+		d.total = int64(e.TotalMatchup) * d.perMatchup
+	case tournament.EventMatchupStart:
+		d.matchup = &e
+		d.matchupBase = d.run()
+		d.names = [2]string{e.Match.Team1Strategy, e.Match.Team2Strategy}
+	case tournament.EventMatchupDone, tournament.EventMatchupTopUp:
+		row, ok1 := d.index[e.Match.Team1Strategy]
+		col, ok2 := d.index[e.Match.Team2Strategy]
+		if ok1 && ok2 && e.Summary != nil {
+			d.finished[e.Matchup] = dashSeries{row, col, e.Summary.Team1Wins, e.Summary.Team2Wins, e.Summary.Games}
+		}
+		if e.Type == tournament.EventMatchupDone {
+			d.matchup = nil
+		}
+	}
+}
+
+// run returns the counters of all games run by this invocation so far
+func (d *Dashboard) run() dashCounters {
+	c := d.done
+	c.add(snapshotCounters(d.cur))
+	return c
+}
+
+// draw writes a frame over the previous one. Must be called with d.mu held.
+func (d *Dashboard) draw() {
+	run := d.run()
+	elapsed := time.Since(d.start)
+
+	// Progress: finished matchups plus the running one, or the games of the batch run
+	progress := run.games + run.failed
+	live := run // Counters the win rates are shown for
+	if d.tournament {
+		live = run.sub(d.matchupBase)
+		progress = 0
+		for _, s := range d.finished {
+			progress += int64(s.games)
+		}
+		if d.matchup != nil {
+			progress += live.games
+		}
+	}
+	fraction := 0.0
+	if d.total > 0 {
+		fraction = min(1, float64(progress)/float64(d.total))
+	}
+
+	var lines []string
+	title := "DBG simulation"
+	if d.names[0] != "" {
+		title += " - " + d.names[0] + " vs " + d.names[1]
+	}
+	lines = append(lines, title)
+
+	const barWidth = 30
+	filled := int(fraction * barWidth)
+	lines = append(lines, fmt.Sprintf("Progress    [%s%s] %5.1f%%  %d/%d games (%d failed)",
+		strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled), fraction*100, progress, d.total, run.failed))
+
+	ranNow := run.games + run.failed - d.restored
+	rate := float64(ranNow) / elapsed.Seconds()
+	eta := "-"
+	if rate > 0 && d.total > progress {
+		eta = (time.Duration(float64(d.total-progress)/rate) * time.Second).Round(time.Second).String()
+	}
+	lines = append(lines, fmt.Sprintf("Throughput  %.1f games/s   elapsed %s   ETA %s", rate, elapsed.Round(time.Second), eta))
+
+	if n := live.team1Wins + live.team2Wins + live.draws; n > 0 {
+		rates := make([]string, 2)
+		for i, wins := range []int64{live.team1Wins, live.team2Wins} {
+			low, high := tournament.WilsonInterval(int(wins), int(n), 0.95)
+			rates[i] = fmt.Sprintf("%s %.1f%% [%.1f%%, %.1f%%]", d.names[i], float64(wins)/float64(n)*100, low*100, high*100)
+		}
+		line := fmt.Sprintf("Win rate    %s   %s   OT %.1f%%", rates[0], rates[1], float64(live.overtime)/float64(n)*100)
+		if live.draws > 0 {
+			line += fmt.Sprintf("   draws %.1f%%", float64(live.draws)/float64(n)*100)
+		}
+		lines = append(lines, line)
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	forced := d.forcedGC
+	d.peakMB = max(d.peakMB, mem.HeapAlloc>>20)
+	if d.cur != nil {
+		forced += atomic.LoadUint32(&d.cur.TotalGCRuns)
+		d.peakMB = max(d.peakMB, atomic.LoadUint64(&d.cur.PeakMemoryUsage))
+	}
+	lines = append(lines, fmt.Sprintf("Memory      heap %d MB   sys %d MB   peak %d MB   GC %d (%d forced)",
+		mem.HeapAlloc>>20, mem.Sys>>20, d.peakMB, mem.NumGC, forced))
+
+	if d.tournament {
+		lines = append(lines, d.matrixLines(live)...)
+	}
+
+	var b strings.Builder
+	if d.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dF", d.lines) // Back to the first line of the last frame
+	}
+	b.WriteString("\x1b[J") // Clear it
+	for _, line := range lines {
+		if r := []rune(line); len(r) > d.width {
+			line = string(r[:d.width])
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	io.WriteString(d.out, b.String())
+	d.lines = len(lines)
+}
+
+// matrixLines renders the win rate of every participant (rows) against every other
+// (columns); the running matchup is marked with * and shows its live counters
+func (d *Dashboard) matrixLines(live dashCounters) []string {
+	n := len(d.participants)
+	wins := make([][]int64, n)
+	games := make([][]int64, n)
+	running := make([][]bool, n)
+	for i := range wins {
+		wins[i] = make([]int64, n)
+		games[i] = make([]int64, n)
+		running[i] = make([]bool, n)
+	}
+	add := func(row, col int, w1, w2, g int64) {
+		wins[row][col] += w1
+		wins[col][row] += w2
+		games[row][col] += g
+		games[col][row] += g
+	}
+	for _, s := range d.finished {
+		add(s.row, s.col, int64(s.team1Wins), int64(s.team2Wins), int64(s.games))
+	}
+	var lines []string
+	if d.matchup != nil {
+		m := d.matchup
+		lines = append(lines, fmt.Sprintf("Matchup     %d/%d  %s vs %s  (%d games)",
+			m.Matchup+1, d.totalMatchup, m.Match.Team1Strategy, m.Match.Team2Strategy, live.games))
+		row, ok1 := d.index[m.Match.Team1Strategy]
+		col, ok2 := d.index[m.Match.Team2Strategy]
+		if _, done := d.finished[m.Matchup]; ok1 && ok2 && !done {
+			add(row, col, live.team1Wins, live.team2Wins, live.team1Wins+live.team2Wins+live.draws)
+			running[row][col], running[col][row] = true, true
+		}
+	}
+
+	nameW := 8
+	for _, name := range d.participants {
+		nameW = max(nameW, len(name))
+	}
+	cellW := max(nameW, 7)
+	header := padRight("", nameW)
+	for _, name := range d.participants {
+		header += "  " + padRight(name, cellW)
+	}
+	lines = append(lines, "", header)
+	for i, name := range d.participants {
+		line := padRight(name, nameW)
+		for j := range d.participants {
+			cell := "·"
+			switch {
+			case i == j:
+				cell = "-"
+			case games[i][j] > 0:
+				cell = fmt.Sprintf("%.1f%%", float64(wins[i][j])/float64(games[i][j])*100)
+				if running[i][j] {
+					cell += "*"
+				}
+			}
+			line += "  " + padRight(cell, cellW)
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+// padRight pads s with spaces to width characters
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/tournament"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Distributed mode: a coordinator (the tournament process) hands out batches of
+// games to worker processes connected over TCP. Messages are newline-delimited JSON.
+
+// defaultDistBatch is the number of games sent to a worker at once
+const defaultDistBatch = 1000
+
+// Message types of the coordinator/worker protocol
+const (
+	msgHello    = "hello"    // worker -> coordinator: identification and distributions hash
+	msgReject   = "reject"   // coordinator -> worker: the worker cannot take part (Error says why)
+	msgBatch    = "batch"    // coordinator -> worker: games to run
+	msgResult   = "result"   // worker -> coordinator: outcomes of a batch
+	msgShutdown = "shutdown" // coordinator -> worker: no more work, disconnect
+)
+
+type distMessage struct {
+	Type              string      `json:"type"`
+	Worker            string      `json:"worker,omitempty"`
+	Cores             int         `json:"cores,omitempty"`
+	DistributionsHash string      `json:"distributions_hash,omitempty"`
+	Batch             *distBatch  `json:"batch,omitempty"`
+	Result            *distResult `json:"result,omitempty"`
+	Error             string      `json:"error,omitempty"`
+}
+
+// distBatch is a contiguous range of games of one matchup. Game i of the batch is
+// seeded with Seed+Offset+i, exactly like a local run of the same matchup.
+type distBatch struct {
+	ID       int64                `json:"id"`
+	Match    tournament.MatchSpec `json:"match"`
+	Rules    engine.GameRules     `json:"rules"`
+	Seed     int64                `json:"seed"`
+	Offset   int                  `json:"offset"`
+	NumGames int                  `json:"num_games"`
+
+	// The worker checks that it plays the rules the coordinator resolved, so workers
+	// need not be started with the coordinator's -g
+	RulesHash string  `json:"rules_hash"`
+	CSFRValue float64 `json:"csf_r_value"`
+}
+
+// distResult carries the compact outcomes of a batch back to the coordinator
+type distResult struct {
+	ID       int64                    `json:"id"`
+	Outcomes []tournament.GameOutcome `json:"outcomes"`
+	Failed   int                      `json:"failed,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+// pendingBatch is a batch waiting for (or assigned to) a worker
+type pendingBatch struct {
+	ctx   context.Context
+	batch distBatch
+	done  chan distResult
+}
+
+// Coordinator accepts worker connections and runs tournament matchups on them
+type Coordinator struct {
+	ln        net.Listener
+	hash      string
+	batchSize int
+
+	queue   chan *pendingBatch
+	closed  chan struct{}
+	nextID  int64
+	workers int64
+	wg      sync.WaitGroup
+
+	connMu sync.Mutex
+	conns  map[net.Conn]bool
+}
+
+// NewCoordinator listens on addr for workers. batchSize is the number of games per batch.
+// Only workers started with the same distributions are accepted; the rules travel with
+// every batch.
+func NewCoordinator(addr string, batchSize int) (*Coordinator, error) {
+	if batchSize <= 0 {
+		batchSize = defaultDistBatch
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("coordinator cannot listen on %s: %w", addr, err)
+	}
+	c := &Coordinator{
+		ln:        ln,
+		hash:      engine.DistributionsHash(),
+		batchSize: batchSize,
+		queue:     make(chan *pendingBatch, 1024),
+		closed:    make(chan struct{}),
+		conns:     make(map[net.Conn]bool),
+	}
+	go c.acceptLoop()
+	return c, nil
+}
+
+// Addr returns the address the coordinator listens on
+func (c *Coordinator) Addr() string {
+	return c.ln.Addr().String()
+}
+
+// Close stops accepting workers and tells the idle ones to shut down. Workers still
+// busy with a batch (only after an interrupt) are disconnected.
+func (c *Coordinator) Close() {
+	close(c.closed)
+	c.ln.Close()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.connMu.Lock()
+		for conn := range c.conns {
+			conn.Close()
+		}
+		c.connMu.Unlock()
+		<-done
+	}
+}
+
+func (c *Coordinator) acceptLoop() {
+	for {
+		conn, err := c.ln.Accept()
+		if err != nil {
+			return // Listener closed
+		}
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.serveWorker(conn)
+		}()
+	}
+}
+
+// serveWorker runs batches on one worker connection until the coordinator closes
+// or the worker disconnects. A batch lost with the connection is queued again.
+func (c *Coordinator) serveWorker(conn net.Conn) {
+	c.connMu.Lock()
+	c.conns[conn] = true
+	c.connMu.Unlock()
+	defer func() {
+		c.connMu.Lock()
+		delete(c.conns, conn)
+		c.connMu.Unlock()
+		conn.Close()
+	}()
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+
+	var hello distMessage
+	if err := dec.Decode(&hello); err != nil || hello.Type != msgHello {
+		return
+	}
+	if hello.DistributionsHash != c.hash {
+		enc.Encode(distMessage{Type: msgReject, Error: fmt.Sprintf(
+			"distributions hash %.12s does not match the coordinator's %.12s", hello.DistributionsHash, c.hash)})
+		fmt.Fprintf(console, "  Rejected worker %s (%s): different distributions file\n", hello.Worker, conn.RemoteAddr())
+		return
+	}
+	n := atomic.AddInt64(&c.workers, 1)
+	defer atomic.AddInt64(&c.workers, -1)
+	fmt.Fprintf(console, "  Worker %s connected from %s (%d cores, %d workers online)\n", hello.Worker, conn.RemoteAddr(), hello.Cores, n)
+
+	for {
+		var p *pendingBatch
+		select {
+		case p = <-c.queue:
+		case <-c.closed:
+			enc.Encode(distMessage{Type: msgShutdown})
+			return
+		}
+		if p.ctx.Err() != nil {
+			continue // The matchup was cancelled while the batch was queued
+		}
+
+		var reply distMessage
+		err := enc.Encode(distMessage{Type: msgBatch, Batch: &p.batch})
+		if err == nil {
+			err = dec.Decode(&reply)
+		}
+		if err != nil || reply.Type != msgResult || reply.Result == nil {
+			fmt.Fprintf(console, "  Worker %s disconnected, re-queueing batch %d\n", hello.Worker, p.batch.ID)
+			c.requeue(p)
+			return
+		}
+		p.done <- *reply.Result
+	}
+}
+
+// requeue hands the batch of a lost worker to the next one. It does not block the
+// worker's goroutine on a full queue: the batch waits in its own goroutine until a
+// worker takes it, the matchup is cancelled or the coordinator closes.
+func (c *Coordinator) requeue(p *pendingBatch) {
+	go func() {
+		select {
+		case c.queue <- p:
+		case <-p.ctx.Done():
+		case <-c.closed:
+		}
+	}()
+}
+
+// Runner returns a tournament.MatchupRunner that splits every matchup into batches
+// and runs them on the connected workers
+func (c *Coordinator) Runner() tournament.MatchupRunner {
+	return func(ctx context.Context, index int, m tournament.MatchSpec, rules engine.GameRules, spec tournament.SeriesSpec) (tournament.SeriesResult, error) {
+		series := tournament.SeriesResult{Match: m}
+		if atomic.LoadInt64(&c.workers) == 0 {
+			fmt.Fprintf(console, "  Waiting for workers on %s...\n", c.Addr())
+		}
+
+		var batches []*pendingBatch
+		for start := 0; start < spec.NumGames; start += c.batchSize {
+			b := distBatch{
+				ID:       atomic.AddInt64(&c.nextID, 1),
+				Match:    m,
+				Rules:    rules,
+				Seed:     spec.Seed,
+				Offset:   spec.Offset + start,
+				NumGames: c.batchSize,
+
+				RulesHash: rules.Hash(),
+				CSFRValue: rules.CSFRValue(),
+			}
+			if rest := spec.NumGames - start; b.NumGames > rest {
+				b.NumGames = rest
+			}
+			if spec.RandomSeeds {
+				// Unseeded tournaments still need distinct, recorded seeds per game
+				b.Seed = rand.Int63n(1<<62) + 1
+				b.Offset = 0
+			}
+			p := &pendingBatch{ctx: ctx, batch: b, done: make(chan distResult, 1)}
+			batches = append(batches, p)
+			select {
+			case c.queue <- p:
+			case <-ctx.Done():
+				return series, ctx.Err()
+			}
+		}
+
+		failed := 0
+		for _, p := range batches {
+			select {
+			case r := <-p.done:
+				if r.Error != "" {
+					return series, fmt.Errorf("worker failed batch %d: %s", r.ID, r.Error)
+				}
+				series.GameResults = append(series.GameResults, r.Outcomes...)
+				failed += r.Failed
+			case <-ctx.Done():
+				return series, ctx.Err()
+			}
+		}
+		if failed > 0 {
+			fmt.Fprintf(console, "  Warning: %d games of matchup %d failed on workers\n", failed, index+1)
+		}
+		return series, nil
+	}
+}
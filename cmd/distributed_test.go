@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/tournament"
+	"encoding/json"
+	"net"
+	"os"
+	"sort"
+	"testing"
+	"time"
+)
+
+// TestCoordinatorWorkerLocalhost runs a matchup on a worker over localhost. A first
+// worker drops the connection after receiving a batch, which must be handed on to the
+// second one, and the outcomes must match a local run with the same seeds.
+func TestCoordinatorWorkerLocalhost(t *testing.T) {
+	if err := engine.LoadDistributions("../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	coord, err := NewCoordinator("127.0.0.1:0", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A worker that takes a batch and disconnects without answering
+	flaky, err := net.Dial("tcp", coord.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewEncoder(flaky).Encode(distMessage{Type: msgHello, Worker: "flaky", Cores: 1, DistributionsHash: engine.DistributionsHash()})
+	lost := make(chan int64, 1)
+	go func() {
+		var msg distMessage
+		if err := json.NewDecoder(flaky).Decode(&msg); err == nil && msg.Batch != nil {
+			lost <- msg.Batch.ID
+		}
+		flaky.Close()
+	}()
+
+	rules, err := engine.ProfileRules("cs2_mr12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tournament.MatchSpec{Team1Name: "A", Team1Strategy: "all_in", Team2Name: "B", Team2Strategy: "min_max_v4"}
+	spec := tournament.SeriesSpec{NumGames: 10, Seed: 42}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	type runResult struct {
+		series tournament.SeriesResult
+		err    error
+	}
+	done := make(chan runResult, 1)
+	go func() {
+		series, err := coord.Runner()(ctx, 0, m, rules, spec)
+		done <- runResult{series, err}
+	}()
+
+	// The real worker joins once the flaky one lost its batch. It has the default rules
+	// (no -g), the rules come with the batches.
+	select {
+	case <-lost:
+	case <-ctx.Done():
+		t.Fatal("the flaky worker got no batch")
+	}
+	workerDir := t.TempDir()
+	workerDone := make(chan error, 1)
+	go func() {
+		workerDone <- runWorker(coord.Addr(), &SimulationConfig{MaxConcurrent: 2, MemoryLimit: 1024, Exportpath: workerDir, GameRules: engine.GameRules{}, Context: ctx})
+	}()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("distributed matchup: %v", res.err)
+	}
+	coord.Close()
+	if err := <-workerDone; err != nil {
+		t.Errorf("worker: %v", err)
+	}
+
+	local, err := tournament.RunMatchup(ctx, m, rules, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bySeed := func(games []tournament.GameOutcome) []tournament.GameOutcome {
+		sort.Slice(games, func(i, j int) bool { return games[i].Seed < games[j].Seed })
+		return games
+	}
+	got, want := bySeed(res.series.GameResults), bySeed(local.GameResults)
+	if len(got) != spec.NumGames || len(got) != len(want) {
+		t.Fatalf("%d games from the workers, %d local, want %d", len(got), len(want), spec.NumGames)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("game %d: worker %+v, local %+v", i, got[i], want[i])
+		}
+	}
+
+	// Workers only stream outcomes back, the coordinator writes the exports
+	if entries, _ := os.ReadDir(workerDir); len(entries) > 0 {
+		t.Errorf("worker wrote %d files into its export directory, e.g. %s", len(entries), entries[0].Name())
+	}
+}
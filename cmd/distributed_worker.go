@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/strategy"
+	"dbg_abm/internal/tournament"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// runWorker connects to a coordinator and runs the batches it receives with the
+// local worker pool until the coordinator shuts it down
+func runWorker(addr string, cfg *SimulationConfig) error {
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	conn, err := dialCoordinator(ctx, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Unblock the decoder when the worker is interrupted
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	hostname, _ := os.Hostname()
+	name := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	if err := enc.Encode(distMessage{
+		Type:              msgHello,
+		Worker:            name,
+		Cores:             cfg.MaxConcurrent,
+		DistributionsHash: engine.DistributionsHash(),
+	}); err != nil {
+		return fmt.Errorf("failed to register with coordinator: %w", err)
+	}
+	fmt.Fprintf(console, "Worker %s connected to coordinator %s\n", name, addr)
+
+	batches := 0
+	for {
+		var msg distMessage
+		if err := dec.Decode(&msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("lost connection to coordinator: %w", err)
+		}
+
+		switch msg.Type {
+		case msgReject:
+			return fmt.Errorf("coordinator rejected worker: %s", msg.Error)
+		case msgShutdown:
+			fmt.Fprintf(console, "Coordinator finished. Worker ran %d batches.\n", batches)
+			return nil
+		case msgBatch:
+			if msg.Batch == nil {
+				continue
+			}
+			b := msg.Batch
+			fmt.Fprintf(console, "Batch %d: %s vs %s, %d games\n", b.ID, b.Match.Team1Strategy, b.Match.Team2Strategy, b.NumGames)
+			result := runWorkerBatch(ctx, cfg, b)
+			if ctx.Err() != nil {
+				// Do not report a truncated batch; the coordinator re-queues it
+				return ctx.Err()
+			}
+			if err := enc.Encode(distMessage{Type: msgResult, Result: &result}); err != nil {
+				return fmt.Errorf("failed to send results to coordinator: %w", err)
+			}
+			batches++
+		}
+	}
+}
+
+// dialCoordinator connects to the coordinator, retrying while it is not up yet
+func dialCoordinator(ctx context.Context, addr string) (net.Conn, error) {
+	deadline := time.Now().Add(time.Minute)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("cannot reach coordinator %s: %w", addr, err)
+		}
+		select {
+		case <-time.After(2 * time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// runWorkerBatch plays a batch with the local worker pool and collects its outcomes
+func runWorkerBatch(ctx context.Context, cfg *SimulationConfig, b *distBatch) distResult {
+	result := distResult{ID: b.ID, Outcomes: make([]tournament.GameOutcome, 0, b.NumGames)}
+	if hash, r := b.Rules.Hash(), b.Rules.CSFRValue(); hash != b.RulesHash || r != b.CSFRValue {
+		result.Error = fmt.Sprintf("batch rules %.12s (CSF r %g) do not match the coordinator's %.12s (CSF r %g)", hash, r, b.RulesHash, b.CSFRValue)
+		return result
+	}
+	for _, s := range []string{b.Match.Team1Strategy, b.Match.Team2Strategy} {
+		if err := strategy.ValidateStrategy(s); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+
+	stream := make(chan SimulationResult, cfg.MaxConcurrent*2)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for r := range stream {
+			result.Outcomes = append(result.Outcomes, tournament.NewGameOutcome(r.Outcome, [2]int{r.Team1Score, r.Team2Score}, r.TotalRounds, r.OTCount, r.Seed))
+		}
+	}()
+
+	stats, err := RunParallelSimulations(SimulationConfig{
+		NumSimulations: b.NumGames,
+		MaxConcurrent:  cfg.MaxConcurrent,
+		MemoryLimit:    cfg.MemoryLimit,
+		Team1Name:      b.Match.Team1Name,
+		Team1Strategy:  b.Match.Team1Strategy,
+		Team2Name:      b.Match.Team2Name,
+		Team2Strategy:  b.Match.Team2Strategy,
+		GameRules:      b.Rules,
+		SuppressOutput: true,
+		StreamOnly:     true,           // The coordinator writes the exports
+		Exportpath:     cfg.Exportpath, // Only used for the error log
+		BaseSeed:       b.Seed,
+		SimOffset:      b.Offset,
+		ResultStream:   stream,
+		EventLog:       cfg.EventLog,
+		Context:        ctx,
+	})
+	close(stream)
+	<-collected
+	if err != nil && !errors.Is(err, context.Canceled) {
+		result.Error = err.Error()
+	}
+	if stats != nil {
+		result.Failed = int(stats.FailedSims)
+	}
+	return result
+}
@@ -11,11 +11,13 @@ import (
 // GameResult holds the essential results from a game simulation
 type GameResult struct {
 	GameID         string
-	Team1Won       bool
+	Outcome        engine.Outcome // Team1 win, Team2 win or draw
 	Team1Score     int
 	Team2Score     int
 	TotalRounds    int
 	WentToOvertime bool
+	OTCount        int   // Number of overtime periods played
+	Seed           int64 // RNG seed the game was played with
 	Team1Economics TeamGameEconomics
 	Team2Economics TeamGameEconomics
 	GameData       *engine.Game // Optional: full game data for advanced analysis
@@ -36,8 +38,11 @@ type TeamGameEconomics struct {
 }
 
 // StartGameWithValidatedRules runs a simulation with pre-validated GameRules (optimized for batch processing)
+// A seed of 0 lets the engine pick a random seed; the seed used is reported in the result.
+// If events is set, the game's events are written to it while the game runs.
 func StartGame_default(team1Name string, team1Strategy string, team2Name string, team2Strategy string,
-	gameRules engine.GameRules, simPrefix string, exportJSON bool, exportRounds bool, csvExportMode int, exportpath string) (*GameResult, error) {
+	gameRules engine.GameRules, simPrefix string, exportJSON bool, exportRounds bool, csvExportMode int, exportpath string, seed int64,
+	events *util.EventWriter) (*GameResult, error) {
 
 	ID := util.CreateGameID()
 	if simPrefix != "" {
@@ -45,7 +50,15 @@ func StartGame_default(team1Name string, team1Strategy string, team2Name string,
 	}
 
 	// Create a new game instance with pre-validated rules
-	game := engine.NewGame(ID, team1Name, team1Strategy, team2Name, team2Strategy, gameRules)
+	var game *engine.Game
+	if seed != 0 {
+		game = engine.NewSeededGame(ID, team1Name, team1Strategy, team2Name, team2Strategy, gameRules, seed)
+	} else {
+		game = engine.NewGame(ID, team1Name, team1Strategy, team2Name, team2Strategy, gameRules)
+	}
+	if events != nil {
+		game.Events = events.Handle
+	}
 
 	// Start the simulation
 	game.Start()
@@ -57,11 +70,13 @@ func StartGame_default(team1Name string, team1Strategy string, team2Name string,
 	// Extract results directly from the game object
 	result := &GameResult{
 		GameID:         ID,
-		Team1Won:       game.Is_T1_Winner,
+		Outcome:        game.Outcome,
 		Team1Score:     game.Score[0],
 		Team2Score:     game.Score[1],
 		TotalRounds:    len(game.Rounds),
 		WentToOvertime: game.OT,
+		OTCount:        game.OTcounter,
+		Seed:           game.Seed,
 		Team1Economics: team1Econ,
 		Team2Economics: team2Econ,
 		GameData:       game, // Store game for advanced analysis
@@ -71,10 +86,10 @@ func StartGame_default(team1Name string, team1Strategy string, team2Name string,
 	if exportJSON {
 		resultsDir := exportpath
 		os.MkdirAll(resultsDir, 0755)
-		resultsPath := filepath.Join(resultsDir, ID+".json")
+		resultsPath := filepath.Join(resultsDir, util.CompressedName(ID+".json"))
 		err := util.ExportResultsToJSON(game, resultsPath)
 		if err != nil {
-			fmt.Printf("Warning: Error exporting detailed results for %s: %v\n", ID, err)
+			fmt.Fprintf(console, "Warning: Error exporting detailed results for %s: %v\n", ID, err)
 		}
 	}
 
@@ -83,16 +98,16 @@ func StartGame_default(team1Name string, team1Strategy string, team2Name string,
 		resultsDir := exportpath
 		os.MkdirAll(resultsDir, 0755)
 		if csvExportMode == 1 {
-			csvPath := filepath.Join(resultsDir, ID+"_full.csv")
+			csvPath := filepath.Join(resultsDir, util.CompressedName(ID+"_full.csv"))
 			err := util.ExportGameAllDataCSV(game, csvPath)
 			if err != nil {
-				fmt.Printf("Warning: Error exporting full CSV for %s: %v\n", ID, err)
+				fmt.Fprintf(console, "Warning: Error exporting full CSV for %s: %v\n", ID, err)
 			}
 		} else if csvExportMode == 3 {
-			csvPath := filepath.Join(resultsDir, ID+"_minimal.csv")
+			csvPath := filepath.Join(resultsDir, util.CompressedName(ID+"_minimal.csv"))
 			err := util.ExportGameMinimalCSV(game, csvPath)
 			if err != nil {
-				fmt.Printf("Warning: Error exporting minimal CSV for %s: %v\n", ID, err)
+				fmt.Fprintf(console, "Warning: Error exporting minimal CSV for %s: %v\n", ID, err)
 			}
 		}
 	}
@@ -103,23 +118,23 @@ func StartGame_default(team1Name string, team1Strategy string, team2Name string,
 		os.MkdirAll(resultsDir, 0755)
 
 		// Export simple JSON version (compact with key metrics only)
-		roundsPathSimple := filepath.Join(resultsDir, ID+"_rounds_simple.json")
-		fmt.Printf("📊 Exporting simplified JSON round data to: %s\n", roundsPathSimple)
+		roundsPathSimple := filepath.Join(resultsDir, util.CompressedName(ID+"_rounds_simple.json"))
+		fmt.Fprintf(console, "📊 Exporting simplified JSON round data to: %s\n", roundsPathSimple)
 		err := util.ExportRoundsToJSONSimple(game, roundsPathSimple)
 		if err != nil {
-			fmt.Printf("Warning: Error exporting simple rounds for %s: %v\n", ID, err)
+			fmt.Fprintf(console, "Warning: Error exporting simple rounds for %s: %v\n", ID, err)
 		} else {
-			fmt.Printf("✅ Simplified JSON round data exported successfully\n")
+			fmt.Fprintf(console, "✅ Simplified JSON round data exported successfully\n")
 		}
 
 		// Also export full version with all details
-		roundsPath := filepath.Join(resultsDir, ID+"_rounds_full.json")
-		fmt.Printf("📊 Exporting full round data to: %s\n", roundsPath)
+		roundsPath := filepath.Join(resultsDir, util.CompressedName(ID+"_rounds_full.json"))
+		fmt.Fprintf(console, "📊 Exporting full round data to: %s\n", roundsPath)
 		err = util.ExportRoundsToJSON(game, roundsPath)
 		if err != nil {
-			fmt.Printf("Warning: Error exporting full rounds for %s: %v\n", ID, err)
+			fmt.Fprintf(console, "Warning: Error exporting full rounds for %s: %v\n", ID, err)
 		} else {
-			fmt.Printf("✅ Full round data exported successfully\n")
+			fmt.Fprintf(console, "✅ Full round data exported successfully\n")
 		}
 	}
 
@@ -1,19 +1,35 @@
 package main
 
 import (
+	"context"
 	"dbg_abm/internal/engine"
+	"dbg_abm/internal/store"
 	"dbg_abm/internal/strategy"
+	"dbg_abm/internal/tournament"
+	"dbg_abm/util"
+	"errors"
 	"fmt"
 	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"syscall"
 	"time"
 )
 
+// console receives the human-readable output of the CLI. It is stderr when the event
+// log owns stdout (--events -).
+var console = os.Stdout
+
 // Main entry point for the CS:GO Economy Simulation
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		os.Exit(runReport(os.Args[2:]))
+	}
+
 	// Default configuration using unified analysis package
 	config := SimulationConfig{
 		NumSimulations:        1,                                               // Default to single simulation
@@ -39,9 +55,47 @@ func main() {
 	tournamentFormat := "roundrobin"
 	games := 1000
 	strategiesCSV := ""
+	sampling := tournament.SamplingSpec{Mode: tournament.SamplingFixed}
+	workerAddr := ""
+	tui := false
+	metricsAddr := ""
+
+	// A run configuration file replaces the defaults; the options below override it
+	if path := findRunConfig(args); path != "" {
+		rc := newRunConfig(config)
+		if err := loadRunConfig(path, &rc); err != nil {
+			fmt.Fprintf(console, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		rc.apply(&config)
+		customOutputPath = rc.ExportPath
+		customGameRulesPath = rc.GameRules
+		customABMModelsPath = rc.Distributions
+		tui = rc.TUI
+		metricsAddr = rc.MetricsAddr
+		if t := rc.Tournament; t != nil {
+			tournamentMode = true
+			strategiesCSV = strings.Join(t.Strategies, ",")
+			if t.Format != "" {
+				tournamentFormat = t.Format
+			}
+			if t.Games > 0 {
+				games = t.Games
+			}
+			if t.Sampling != "" {
+				sampling.Mode = tournament.SamplingMode(t.Sampling)
+			}
+			sampling.TargetHalfWidth = t.TargetCI
+			sampling.MinGames = t.MinGames
+			sampling.MaxGames = t.MaxGames
+			sampling.BatchSize = t.BatchSize
+		}
+	}
 
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
+		case "--config":
+			i++ // Read above
 		case "-n", "--num":
 			if i+1 < len(args) {
 				fmt.Sscanf(args[i+1], "%d", &config.NumSimulations)
@@ -85,6 +139,22 @@ func main() {
 				fmt.Sscanf(args[i+1], "%d", &config.CSVExportMode)
 				i++
 			}
+		case "--jsonl":
+			config.JSONLExport = true
+		case "--round-table":
+			config.RoundTable = true
+		case "--tui":
+			tui = true
+		case "--metrics-addr":
+			if i+1 < len(args) {
+				metricsAddr = args[i+1]
+				i++
+			}
+		case "--compress":
+			if i+1 < len(args) {
+				config.Compress = args[i+1]
+				i++
+			}
 		case "-g", "--gamerules":
 			if i+1 < len(args) {
 				customGameRulesPath = args[i+1]
@@ -95,12 +165,32 @@ func main() {
 				customABMModelsPath = args[i+1]
 				i++
 			}
+		case "--seed":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &config.BaseSeed)
+				i++
+			}
 		case "-h", "--help":
 			printUsage()
 			return
 		case "--tournament":
 			tournamentMode = true
 		case "--format":
+			if i+1 < len(args) {
+				config.ExportFormat = args[i+1]
+				i++
+			}
+		case "--events":
+			if i+1 < len(args) {
+				config.EventsPath = args[i+1]
+				i++
+			}
+		case "--db":
+			if i+1 < len(args) {
+				config.DBPath = args[i+1]
+				i++
+			}
+		case "--tournament-format":
 			if i+1 < len(args) {
 				tournamentFormat = args[i+1]
 				i++
@@ -115,10 +205,111 @@ func main() {
 				strategiesCSV = args[i+1]
 				i++
 			}
+		case "--sampling":
+			if i+1 < len(args) {
+				sampling.Mode = tournament.SamplingMode(args[i+1])
+				i++
+			}
+		case "--target-ci":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%g", &sampling.TargetHalfWidth)
+				i++
+			}
+		case "--min-games":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &sampling.MinGames)
+				i++
+			}
+		case "--max-games":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &sampling.MaxGames)
+				i++
+			}
+		case "--resume":
+			if i+1 < len(args) {
+				customOutputPath = args[i+1]
+				config.Resume = true
+				i++
+			}
+		case "--coordinator":
+			if i+1 < len(args) {
+				config.CoordinatorAddr = args[i+1]
+				i++
+			}
+		case "--worker":
+			if i+1 < len(args) {
+				workerAddr = args[i+1]
+				i++
+			}
+		case "--dist-batch":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &config.DistBatch)
+				i++
+			}
+		case "--checkpoint-every":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &config.CheckpointEvery)
+				i++
+			}
+		case "--batch-size":
+			if i+1 < len(args) {
+				fmt.Sscanf(args[i+1], "%d", &sampling.BatchSize)
+				i++
+			}
+		}
+	}
+
+	if err := util.SetCompression(config.Compress); err != nil {
+		fmt.Fprintf(console, "❌ %v\n", err)
+		os.Exit(1)
+	}
+	if config.Compress == util.CompressNone {
+		config.Compress = "" // Same as the default in manifests
+	}
+
+	// --format only selects the export format; the tournament format has its own flag
+	switch config.ExportFormat {
+	case "", "csv", "parquet":
+	default:
+		fmt.Fprintf(console, "❌ Unknown export format '%s' (csv or parquet); use --tournament-format for the tournament format\n", config.ExportFormat)
+		os.Exit(1)
+	}
+
+	// Game event log; with "-" the events own stdout and the regular output goes to stderr
+	if config.EventsPath != "" {
+		eventLog, err := util.NewEventWriter(config.EventsPath)
+		if err != nil {
+			fmt.Fprintf(console, "❌ Cannot open event log: %v\n", err)
+			os.Exit(1)
+		}
+		if config.EventsPath == "-" {
+			console = os.Stderr
 		}
+		config.EventLog = eventLog
 	}
 
+	// Live dashboard for parallel runs and tournaments; it redraws in place, so it needs a terminal
+	if tui {
+		if isTerminal(console) {
+			config.Dashboard = NewDashboard(console)
+		} else {
+			fmt.Fprintln(console, "Warning: --tui needs a terminal, showing the regular output")
+		}
+	}
+	defer finishRun(&config, nil)
+
 	// Set the results directory - use custom path if specified, otherwise create timestamped directory
+	if config.Resume {
+		if _, err := os.Stat(customOutputPath); err != nil {
+			fmt.Fprintf(console, "Cannot resume: %v\n", err)
+			os.Exit(1)
+		}
+		// Only concurrent batches and tournaments keep a manifest
+		if !tournamentMode && (config.Sequential || config.NumSimulations == 1) {
+			fmt.Fprintln(console, "Cannot resume: --resume needs a concurrent batch run (-n > 1 without --sequential) or a tournament")
+			os.Exit(1)
+		}
+	}
 	if customOutputPath != "" {
 		config.Exportpath = filepath.Clean(customOutputPath)
 	} else {
@@ -127,43 +318,123 @@ func main() {
 
 	// Create the results directory
 	if err := os.MkdirAll(config.Exportpath, 0755); err != nil {
-		fmt.Printf("Error creating results directory: %v\n", err)
+		fmt.Fprintf(console, "Error creating results directory: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Validate and prepare all customizations before starting simulations
 	customConfig, err := ValidateAndPrepareCustomizations(customGameRulesPath, customABMModelsPath, config.Exportpath)
 	if err != nil {
-		fmt.Printf("❌ Configuration validation failed: %v\n", err)
+		fmt.Fprintf(console, "❌ Configuration validation failed: %v\n", err)
 		os.Exit(1)
 	}
 
 	config.GameRules = customConfig.GameRules
 
+	// Ctrl-C / SIGTERM stop the run and export what finished so far;
+	// a second signal falls back to the default behaviour and kills the process
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	// Closed before stopSignals cancels ctx on a normal exit, which is no interrupt
+	runDone := make(chan struct{})
+	defer close(runDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-runDone:
+			return
+		}
+		stopSignals()
+		fmt.Fprintln(console, "\n⚠️  Interrupt received: finishing games in flight and exporting partial results (press Ctrl-C again to abort)")
+	}()
+	config.Context = ctx
+
+	// Worker mode: run batches for a remote coordinator
+	if workerAddr != "" {
+		if err := runWorker(workerAddr, &config); err != nil {
+			finishRun(&config, err)
+			exitOnInterrupt(err, config.Exportpath)
+			fmt.Fprintf(console, "Worker error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Serve the progress of the run to Prometheus
+	if metricsAddr != "" {
+		mode := "batch"
+		if tournamentMode {
+			mode = "tournament"
+		}
+		metrics, err := NewMetricsServer(metricsAddr, mode)
+		if err != nil {
+			fmt.Fprintf(console, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		config.Metrics = metrics // Closed by finishRun
+		if !tournamentMode {
+			metrics.SetTarget(int64(config.NumSimulations))
+		}
+		fmt.Fprintf(console, "Serving metrics on http://%s/metrics\n", metrics.Addr())
+	}
+
+	// Record the run in the results database
+	if config.DBPath != "" {
+		kind := "batch"
+		if tournamentMode {
+			kind = "tournament"
+		}
+		if err := openResultStore(&config, kind); err != nil {
+			fmt.Fprintf(console, "❌ %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Validate strategies BEFORE starting any simulations
 	if err := strategy.ValidateStrategy(config.Team1Strategy); err != nil {
-		fmt.Printf("Invalid Strategy for Team 1: %v\n", err)
+		fmt.Fprintf(console, "Invalid Strategy for Team 1: %v\n", err)
 		os.Exit(1)
 	}
 	if err := strategy.ValidateStrategy(config.Team2Strategy); err != nil {
-		fmt.Printf("Invalid Strategy for Team 2: %v\n", err)
+		fmt.Fprintf(console, "Invalid Strategy for Team 2: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Confirm strategies being used
 	if !config.SuppressOutput {
-		fmt.Printf("Confirmed Team 1 strategy: %s\n", config.Team1Strategy)
-		fmt.Printf("Confirmed Team 2 strategy: %s\n", config.Team2Strategy)
+		fmt.Fprintf(console, "Confirmed Team 1 strategy: %s\n", config.Team1Strategy)
+		fmt.Fprintf(console, "Confirmed Team 2 strategy: %s\n", config.Team2Strategy)
 	}
 
 	// Run tournament mode
 	if tournamentMode {
+		if strategiesCSV == "" && config.Resume {
+			// Take the tournament setup from the checkpoint
+			if manifest, err := tournament.LoadManifest(config.Exportpath); err == nil {
+				strategiesCSV = strings.Join(manifest.Participants, ",")
+				tournamentFormat = string(manifest.Format)
+				games = manifest.NumGames
+				sampling = manifest.Sampling
+				fmt.Sscanf(manifest.Options["csv_export_mode"], "%d", &config.CSVExportMode)
+				config.Sequential = manifest.Options["sequential"] == "true"
+				config.JSONLExport = manifest.Options["jsonl"] == "true"
+				config.RoundTable = manifest.Options["round_table"] == "true"
+				config.ExportFormat = manifest.Options["export_format"]
+				config.Compress = manifest.Options["compress"]
+				if err := util.SetCompression(config.Compress); err != nil {
+					fmt.Fprintf(console, "❌ Cannot resume: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		}
 		if strategiesCSV == "" {
-			fmt.Println("--strategies is required for tournament mode")
+			fmt.Fprintln(console, "--strategies is required for tournament mode")
 			os.Exit(1)
 		}
-		if err := runTournament(&config, customConfig, strategiesCSV, tournamentFormat, games); err != nil {
-			fmt.Printf("Error running tournament: %v\n", err)
+		if err := runTournament(&config, customConfig, strategiesCSV, tournamentFormat, games, sampling); err != nil {
+			finishRun(&config, err)
+			exitOnInterrupt(err, config.Exportpath)
+			fmt.Fprintf(console, "Error running tournament: %v\n", err)
 			os.Exit(1)
 		}
 		return
@@ -172,7 +443,7 @@ func main() {
 	// Run simulation(s)
 	if config.NumSimulations == 1 {
 		// Single simulation mode
-		fmt.Println("Running single simulation...")
+		fmt.Fprintln(console, "Running single simulation...")
 		result, err := StartGame_default(
 			config.Team1Name,
 			config.Team1Strategy,
@@ -184,77 +455,167 @@ func main() {
 			config.ExportRounds,
 			config.CSVExportMode,
 			config.Exportpath,
+			config.BaseSeed,
+			config.EventLog,
 		)
 		if err != nil {
-			fmt.Printf("Error running simulation: %v\n", err)
+			fmt.Fprintf(console, "Error running simulation: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Simulation completed. Game ID: %s\n", result.GameID)
-		if result.Team1Won {
-			fmt.Printf("Winner: %s (%d-%d)\n", config.Team1Name, result.Team1Score, result.Team2Score)
+		fmt.Fprintf(console, "Simulation completed. Game ID: %s\n", result.GameID)
+		if result.Outcome.IsDraw() {
+			fmt.Fprintf(console, "Draw (%d-%d)\n", result.Team1Score, result.Team2Score)
+		} else if result.Outcome.Team1Won() {
+			fmt.Fprintf(console, "Winner: %s (%d-%d)\n", config.Team1Name, result.Team1Score, result.Team2Score)
 		} else {
-			fmt.Printf("Winner: %s (%d-%d)\n", config.Team2Name, result.Team2Score, result.Team1Score)
+			fmt.Fprintf(console, "Winner: %s (%d-%d)\n", config.Team2Name, result.Team2Score, result.Team1Score)
 		}
 	} else if config.Sequential {
 		// Sequential simulations mode
 		err := sequentialsimulation(config, customConfig.GameRules)
 		if err != nil {
-			fmt.Printf("Error running sequential simulations: %v\n", err)
+			finishRun(&config, err)
+			exitOnInterrupt(err, config.Exportpath)
+			fmt.Fprintf(console, "Error running sequential simulations: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
 		// Multiple simulations mode
-		_, err := RunParallelSimulations(config)
+		if config.Dashboard != nil {
+			config.Dashboard.Start(int64(config.NumSimulations))
+		}
+		_, err := RunResumableSimulations(config, config.CheckpointEvery, config.Resume)
+		if config.Dashboard != nil {
+			config.Dashboard.Stop()
+		}
 		if err != nil {
-			fmt.Printf("Error running parallel simulations: %v\n", err)
+			finishRun(&config, err)
+			exitOnInterrupt(err, config.Exportpath)
+			fmt.Fprintf(console, "Error running parallel simulations: %v\n", err)
 			os.Exit(1)
 		}
 	}
 }
 
+// finishRun flushes the event log, closes the metrics endpoint and records the outcome of
+// the run in the results database. It is called before every exit after a failed run.
+func finishRun(config *SimulationConfig, runErr error) {
+	if config.EventLog != nil {
+		if err := config.EventLog.Close(); err != nil {
+			fmt.Fprintf(console, "Warning: Failed to write event log: %v\n", err)
+		}
+		config.EventLog = nil
+	}
+	if config.Metrics != nil {
+		config.Metrics.Close()
+		config.Metrics = nil
+	}
+	closeResultStore(config, runErr)
+}
+
+// exitOnInterrupt ends the process with exit code 130 if err stems from an interrupt
+func exitOnInterrupt(err error, exportpath string) {
+	if !errors.Is(err, context.Canceled) {
+		return
+	}
+	fmt.Fprintf(console, "Run interrupted. Partial results exported to: %s/\n", exportpath)
+	os.Exit(130)
+}
+
 // Print usage information for command-line arguments
 func printUsage() {
-	fmt.Println("CS:GO Economy Simulation Usage:")
-	fmt.Println("  -n, --num <number>     Number of simulations to run (default: 1)")
-	fmt.Println("  -c, --cores <number>   Number of concurrent simulations (default: number of CPU cores)")
-	fmt.Println("  -m, --memory <number>  Memory limit in MB before forcing GC (default: 3000)")
-	fmt.Println("  -s, --sequential       Run simulations sequentially instead of in parallel")
-	fmt.Println("  -e, --export           Export individual game results as JSON files (works with both modes)")
-	fmt.Println("  -r, --rounds           Export round-by-round data for each game (single simulation only)")
-	fmt.Println("  --csv <mode>           CSV export mode: 0=none, 1=individual full, 2=combined full, 3=individual minimal, 4=combined minimal")
-	fmt.Println("  -o, --output <path>    Results output directory (default: results_YYYYMMDD_HHMMSS)")
-	fmt.Println("  -g, --gamerules <file> Path to JSON file with custom game rules (default: built-in defaults)")
-	fmt.Println("  -dist, --abmmodels <file> Path to ABM models JSON file (default: abm_models.json)")
-	fmt.Println("  -t1, --team1 <strategy> Team 1 strategy (default: all_in)")
-	fmt.Println("  -t2, --team2 <strategy> Team 2 strategy (default: default_half)")
-	fmt.Println("  --tournament            Run tournament mode instead of single/multi simulation")
-	fmt.Println("  --strategies <list>     Comma-separated strategy list for tournament (required)")
-	fmt.Println("  --format <name>         Tournament format (roundrobin)")
-	fmt.Println("  --games <number>        Games per matchup in tournament (default: 1000)")
-	fmt.Println("  -h, --help             Print this help message")
-	fmt.Println("\nGame Rules Configuration:")
-	fmt.Println("  You can customize game parameters using a JSON file. Example:")
-	fmt.Println("  go run ./cmd -g example_gamerules.json")
-	fmt.Println("  Missing fields will use default values automatically.")
+	fmt.Fprintln(console, "CS:GO Economy Simulation Usage:")
+	fmt.Fprintln(console, "  -n, --num <number>     Number of simulations to run (default: 1)")
+	fmt.Fprintln(console, "  -c, --cores <number>   Number of concurrent simulations (default: number of CPU cores)")
+	fmt.Fprintln(console, "  -m, --memory <number>  Memory limit in MB before forcing GC (default: 3000)")
+	fmt.Fprintln(console, "  -s, --sequential       Run simulations sequentially instead of in parallel")
+	fmt.Fprintln(console, "  -e, --export           Export individual game results as JSON files (works with both modes)")
+	fmt.Fprintln(console, "  -r, --rounds           Export round-by-round data for each game (single simulation only)")
+	fmt.Fprintln(console, "  --csv <mode>           CSV export mode: 0=none, 1=individual full, 2=combined full, 3=individual minimal, 4=combined minimal")
+	fmt.Fprintln(console, "  --jsonl                Also write one JSON record per game to all_games.jsonl")
+	fmt.Fprintln(console, "  --round-table          Also write the per-round economy table round_aggregates.csv")
+	fmt.Fprintln(console, "  --metrics-addr <addr>  Serve progress, throughput, heap and GC metrics for Prometheus on <addr>/metrics (e.g. :9100)")
+	fmt.Fprintln(console, "  --tui                  Live dashboard (throughput, ETA, win rates with CIs, memory, tournament matrix) instead of progress output")
+	fmt.Fprintln(console, "  --compress <none|gzip|zstd> Compress CSV, JSON and JSONL exports (.gz / .zst)")
+	fmt.Fprintln(console, "  --db <path>            Also store run metadata, games and rounds in a SQLite database")
+	fmt.Fprintln(console, "  --events <path|->      Write buy, outcome, funds, side switch and OT events of every game as JSON Lines (- for stdout)")
+	fmt.Fprintln(console, "  -o, --output <path>    Results output directory (default: results_YYYYMMDD_HHMMSS)")
+	fmt.Fprintln(console, "  -g, --gamerules <file> Path to a JSON, YAML or TOML file with custom game rules (default: built-in defaults)")
+	fmt.Fprintln(console, "                         or profile:<name>[+<file>] for a built-in profile ("+strings.Join(engine.RuleProfiles(), ", ")+"), optionally with an override file")
+	fmt.Fprintln(console, "  --config <file>        Run configuration file (JSON, YAML or TOML) with the options of the run; options on the command line override it")
+	fmt.Fprintln(console, "  -dist, --abmmodels <file> Path to ABM models JSON file (default: abm_models.json)")
+	fmt.Fprintln(console, "  --seed <number>        Seed game i with <number>+i for reproducible runs (default: random seeds)")
+	fmt.Fprintln(console, "  -t1, --team1 <strategy> Team 1 strategy (default: all_in)")
+	fmt.Fprintln(console, "  -t2, --team2 <strategy> Team 2 strategy (default: default_half)")
+	fmt.Fprintln(console, "  --tournament            Run tournament mode instead of single/multi simulation")
+	fmt.Fprintln(console, "  --strategies <list>     Comma-separated strategy list for tournament (required)")
+	fmt.Fprintln(console, "  --format <name>         Export format for --csv 2/4 (csv, parquet)")
+	fmt.Fprintln(console, "  --tournament-format <name> Tournament format (roundrobin, doubleroundrobin)")
+	fmt.Fprintln(console, "  --games <number>        Games per matchup in tournament (default: 1000); average budget with adaptive sampling")
+	fmt.Fprintln(console, "  --sampling <mode>       Games per matchup: fixed, wilson (stop at target CI width) or sprt (stop at SPRT decision)")
+	fmt.Fprintln(console, "  --target-ci <width>     Wilson sampling: target half width of the win-rate interval (default: 0.02)")
+	fmt.Fprintln(console, "  --min-games <number>    Adaptive sampling: games before a matchup may stop (default: 100)")
+	fmt.Fprintln(console, "  --max-games <number>    Adaptive sampling: cap per matchup (default: 4x --games)")
+	fmt.Fprintln(console, "  --batch-size <number>   Adaptive sampling: games between stopping checks (default: 100)")
+	fmt.Fprintln(console, "  --coordinator <addr>    Tournament: listen on <addr> (e.g. :7070) and run the games on connected workers")
+	fmt.Fprintln(console, "  --worker <addr>         Run as worker for the coordinator at <addr> (uses -c cores)")
+	fmt.Fprintln(console, "  --dist-batch <number>   Games per batch sent to a worker (default: 1000)")
+	fmt.Fprintln(console, "  --resume <dir>          Resume an interrupted concurrent batch or tournament from the manifest in <dir>")
+	fmt.Fprintln(console, "  --checkpoint-every <n>  Simulations (tournament: games per matchup) between checkpoints (default: 10000 / 1000)")
+	fmt.Fprintln(console, "  -h, --help             Print this help message")
+	fmt.Fprintln(console, "\nReport:")
+	fmt.Fprintln(console, "  report <dir> [-o <file>] Render the exports in <dir> as a self-contained HTML report")
+	fmt.Fprintln(console, "\nGame Rules Configuration:")
+	fmt.Fprintln(console, "  You can customize game parameters using a JSON, YAML or TOML file. Example:")
+	fmt.Fprintln(console, "  go run ./cmd -g example_gamerules.yaml")
+	fmt.Fprintln(console, "  Missing fields will use default values automatically.")
 }
 
 // SimulationConfig unified configuration for all simulation types
 type SimulationConfig struct {
-	NumSimulations        int              `json:"num_simulations"`
-	MaxConcurrent         int              `json:"max_concurrent,omitempty"` // Only for concurrent
-	MemoryLimit           int              `json:"memory_limit,omitempty"`   // Only for concurrent
-	Team1Name             string           `json:"team1_name"`
-	Team1Strategy         string           `json:"team1_strategy"`
-	Team2Name             string           `json:"team2_name"`
-	Team2Strategy         string           `json:"team2_strategy"`
-	GameRules             engine.GameRules `json:"game_rules"`
-	ExportDetailedResults bool             `json:"export_detailed_results"`
-	ExportRounds          bool             `json:"export_rounds"`
-	Sequential            bool             `json:"sequential"`
-	AdvancedAnalysis      bool             `json:"advanced_analysis"`     // Enable advanced economic analysis
-	CSVExportMode         int              `json:"csv_export_mode"`       // 0=none, 1=individual full, 2=combined full, 3=individual minimal, 4=combined minimal
-	Exportpath            string           `json:"export_path,omitempty"` // Path for exporting results
-	SuppressOutput        bool             `json:"suppress_output"`       // Suppress terminal output during simulations
+	NumSimulations        int               `json:"num_simulations"`
+	MaxConcurrent         int               `json:"max_concurrent,omitempty"` // Only for concurrent
+	MemoryLimit           int               `json:"memory_limit,omitempty"`   // Only for concurrent
+	Team1Name             string            `json:"team1_name"`
+	Team1Strategy         string            `json:"team1_strategy"`
+	Team2Name             string            `json:"team2_name"`
+	Team2Strategy         string            `json:"team2_strategy"`
+	GameRules             engine.GameRules  `json:"game_rules"`
+	ExportDetailedResults bool              `json:"export_detailed_results"`
+	ExportRounds          bool              `json:"export_rounds"`
+	Sequential            bool              `json:"sequential"`
+	AdvancedAnalysis      bool              `json:"advanced_analysis"`          // Enable advanced economic analysis
+	CSVExportMode         int               `json:"csv_export_mode"`            // 0=none, 1=individual full, 2=combined full, 3=individual minimal, 4=combined minimal
+	Exportpath            string            `json:"export_path,omitempty"`      // Path for exporting results
+	SuppressOutput        bool              `json:"suppress_output"`            // Suppress terminal output during simulations
+	StreamOnly            bool              `json:"-"`                          // Only send results to ResultStream: no summary or combined exports (distributed workers)
+	BaseSeed              int64             `json:"base_seed,omitempty"`        // If set, game i is seeded with BaseSeed+i for reproducible runs
+	AppendCSV             bool              `json:"append_csv,omitempty"`       // Append to existing combined CSVs (modes 2/4) instead of overwriting them
+	SimOffset             int               `json:"-"`                          // Simulations already run in earlier batches (shifts sim IDs and seeds)
+	CheckpointEvery       int               `json:"checkpoint_every,omitempty"` // Simulations (games per matchup in tournaments) between checkpoints
+	Resume                bool              `json:"-"`                          // Continue the run recorded in the manifest of Exportpath
+	CoordinatorAddr       string            `json:"-"`                          // Tournament: listen here and run matchups on connected workers
+	DistBatch             int               `json:"-"`                          // Games per batch sent to a worker (default 1000)
+	JSONLExport           bool              `json:"jsonl_export,omitempty"`     // Also stream one JSON record per game into all_games.jsonl
+	RoundTable            bool              `json:"round_table,omitempty"`      // Also aggregate the rounds into round_aggregates.csv
+	ExportFormat          string            `json:"export_format,omitempty"`    // Combined export format for CSV modes 2/4: csv (default) or parquet
+	Compress              string            `json:"compress,omitempty"`         // Compression of CSV/JSON/JSONL exports: none (default), gzip or zstd
+	DBPath                string            `json:"db_path,omitempty"`          // SQLite results database that every game of the run is added to
+	Store                 *store.DB         `json:"-"`                          // Open results database (DBPath)
+	RunID                 int64             `json:"-"`                          // Run of this invocation in Store
+	MatchupIndex          int               `json:"-"`                          // Matchup of the run the games belong to (tournaments)
+	EventsPath            string            `json:"events_path,omitempty"`      // JSON Lines event log of every game ("-" for stdout)
+	EventLog              *util.EventWriter `json:"-"`                          // Open event log (EventsPath)
+	Dashboard             *Dashboard        `json:"-"`                          // Live terminal view (--tui)
+	Metrics               *MetricsServer    `json:"-"`                          // Prometheus endpoint (--metrics-addr)
+
+	// ResultStream, if set, receives every successfully finished game (without GameData).
+	// The caller owns the channel and must keep draining it while the run is in progress.
+	ResultStream chan<- SimulationResult `json:"-"`
+
+	// Context, if set, interrupts the run when cancelled: no new games are started,
+	// games in flight finish and the results so far are exported as partial.
+	Context context.Context `json:"-"`
 }
 
 // Validate validates the simulation configuration
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"dbg_abm/internal/analysis"
+	"dbg_abm/internal/tournament"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsServer serves the progress of a run on /metrics in the Prometheus text format.
+// Like the dashboard it reads the atomic counters of the SimulationStats of the running
+// batch; finished batches are folded into per-matchup totals.
+type MetricsServer struct {
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+
+	mode     string // batch or tournament
+	start    time.Time
+	target   int64 // Games of the whole run (an estimate for adaptive tournaments)
+	matchups map[int]*matchupMetrics
+
+	peakBytes        uint64 // Highest heap seen by a scrape or the memory monitor
+	perMatchup       int64
+	totalMatchups    int
+	finishedMatchups int
+}
+
+// matchupMetrics are the counters of one matchup (a batch run is matchup 0)
+type matchupMetrics struct {
+	team1, team2 string
+	done         dashCounters              // Finished batches
+	cur          *analysis.SimulationStats // Batch in progress
+	forcedGC     uint32
+	peakMB       uint64
+	summaryGames int64 // Games reported by the tournament when the matchup finished
+	restored     int64 // Games of an earlier invocation (resumed batch)
+	finished     bool
+}
+
+// progress returns the games of the matchup including those of earlier invocations
+func (m *matchupMetrics) progress() int64 {
+	return max(m.counters().games, m.summaryGames) + m.restored
+}
+
+func (m *matchupMetrics) counters() dashCounters {
+	c := m.done
+	c.add(snapshotCounters(m.cur))
+	return c
+}
+
+// NewMetricsServer listens on addr (e.g. :9100 or 127.0.0.1:9100) and serves /metrics
+// until Close
+func NewMetricsServer(addr, mode string) (*MetricsServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for metrics on %s: %w", addr, err)
+	}
+	m := &MetricsServer{
+		listener: ln,
+		mode:     mode,
+		start:    time.Now(),
+		matchups: make(map[int]*matchupMetrics),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", m.serveMetrics)
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go m.server.Serve(ln)
+	return m, nil
+}
+
+// Addr returns the address the server listens on
+func (m *MetricsServer) Addr() string {
+	return m.listener.Addr().String()
+}
+
+// Close stops serving
+func (m *MetricsServer) Close() error {
+	return m.server.Close()
+}
+
+// SetTarget sets the number of games of the run
+func (m *MetricsServer) SetTarget(games int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.target = games
+}
+
+// SetGamesPerMatchup sets the games of every tournament matchup (the run's target follows
+// once the number of matchups is known)
+func (m *MetricsServer) SetGamesPerMatchup(games int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.perMatchup = int64(games)
+}
+
+func (m *MetricsServer) matchup(index int) *matchupMetrics {
+	mm, ok := m.matchups[index]
+	if !ok {
+		mm = &matchupMetrics{}
+		m.matchups[index] = mm
+	}
+	return mm
+}
+
+// Restore counts the games of an earlier invocation of a resumed batch
+func (m *MetricsServer) Restore(index int, stats *analysis.SimulationStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.matchup(index).restored += atomic.LoadInt64(&stats.CompletedSims)
+}
+
+// Track makes stats the batch in progress of the matchup
+func (m *MetricsServer) Track(index int, stats *analysis.SimulationStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm := m.matchup(index)
+	mm.cur = stats
+	if stats.Config != nil {
+		mm.team1, mm.team2 = stats.Config.Team1Strategy, stats.Config.Team2Strategy
+	}
+}
+
+// Untrack adds the finished batch of the matchup to its totals
+func (m *MetricsServer) Untrack(index int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mm := m.matchup(index)
+	if mm.cur == nil {
+		return
+	}
+	mm.done.add(snapshotCounters(mm.cur))
+	mm.forcedGC += atomic.LoadUint32(&mm.cur.TotalGCRuns)
+	mm.peakMB = max(mm.peakMB, atomic.LoadUint64(&mm.cur.PeakMemoryUsage))
+	mm.cur = nil
+}
+
+// Event records the tournament progress
+func (m *MetricsServer) Event(e tournament.Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch e.Type {
+	case tournament.EventTournamentStart:
+		m.totalMatchups = e.TotalMatchup
+		m.target = int64(e.TotalMatchup) * m.perMatchup
+	case tournament.EventMatchupStart:
+		mm := m.matchup(e.Matchup)
+		mm.team1, mm.team2 = e.Match.Team1Strategy, e.Match.Team2Strategy
+	case tournament.EventMatchupDone, tournament.EventMatchupTopUp:
+		mm := m.matchup(e.Matchup)
+		if e.Summary != nil {
+			mm.summaryGames = int64(e.Summary.Games)
+		}
+		if e.Type == tournament.EventMatchupDone && !mm.finished {
+			mm.finished = true
+			m.finishedMatchups++
+		}
+	}
+}
+
+// serveMetrics writes all metrics in the Prometheus text exposition format
+func (m *MetricsServer) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	indices := make([]int, 0, len(m.matchups))
+	for i := range m.matchups {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	var b strings.Builder
+	var completed, progress int64
+	var forcedGC uint32
+	var peakMB uint64
+	metric(&b, "dbg_simulations_completed_total", "counter", "Simulations completed by this process, by matchup")
+	for _, i := range indices {
+		mm := m.matchups[i]
+		c := mm.counters()
+		completed += c.games
+		progress += mm.progress()
+		fmt.Fprintf(&b, "dbg_simulations_completed_total%s %d\n", mm.labels(i), c.games)
+	}
+	metric(&b, "dbg_simulations_failed_total", "counter", "Simulations that failed, by matchup")
+	for _, i := range indices {
+		mm := m.matchups[i]
+		fmt.Fprintf(&b, "dbg_simulations_failed_total%s %d\n", mm.labels(i), mm.counters().failed)
+	}
+	metric(&b, "dbg_team1_wins_total", "counter", "Games won by team 1, by matchup")
+	for _, i := range indices {
+		mm := m.matchups[i]
+		fmt.Fprintf(&b, "dbg_team1_wins_total%s %d\n", mm.labels(i), mm.counters().team1Wins)
+	}
+	metric(&b, "dbg_draws_total", "counter", "Games that ended in a draw, by matchup")
+	for _, i := range indices {
+		mm := m.matchups[i]
+		fmt.Fprintf(&b, "dbg_draws_total%s %d\n", mm.labels(i), mm.counters().draws)
+	}
+	metric(&b, "dbg_matchup_progress_ratio", "gauge", "Completed share of the games of a matchup (including resumed games)")
+	for _, i := range indices {
+		mm := m.matchups[i]
+		target := m.perMatchup
+		if m.mode == "batch" {
+			target = m.target
+		}
+		ratio := 0.0
+		switch {
+		case mm.finished:
+			ratio = 1
+		case target > 0:
+			ratio = min(1, float64(mm.progress())/float64(target))
+		}
+		fmt.Fprintf(&b, "dbg_matchup_progress_ratio%s %g\n", mm.labels(i), ratio)
+
+		forcedGC += mm.forcedGC
+		peakMB = max(peakMB, mm.peakMB)
+		if mm.cur != nil {
+			forcedGC += atomic.LoadUint32(&mm.cur.TotalGCRuns)
+			peakMB = max(peakMB, atomic.LoadUint64(&mm.cur.PeakMemoryUsage))
+		}
+	}
+
+	elapsed := time.Since(m.start).Seconds()
+	gauge(&b, "dbg_simulations_target", "Games the run will play (an estimate with adaptive sampling)", float64(m.target))
+	gauge(&b, "dbg_simulations_progress", "Games of the run completed so far (including resumed games)", float64(progress))
+	gauge(&b, "dbg_simulations_per_second", "Average throughput of this process since the run started", float64(completed)/elapsed)
+	if m.mode == "tournament" {
+		gauge(&b, "dbg_tournament_matchups", "Matchups of the tournament", float64(m.totalMatchups))
+		gauge(&b, "dbg_tournament_matchups_finished", "Matchups of the tournament that are finished", float64(m.finishedMatchups))
+	}
+	gauge(&b, "dbg_heap_alloc_bytes", "Bytes of allocated heap objects", float64(mem.HeapAlloc))
+	gauge(&b, "dbg_heap_sys_bytes", "Bytes of heap memory obtained from the OS", float64(mem.HeapSys))
+	m.peakBytes = max(m.peakBytes, peakMB<<20, mem.HeapAlloc)
+	gauge(&b, "dbg_peak_memory_bytes", "Peak heap seen by the memory monitor or a scrape", float64(m.peakBytes))
+	metric(&b, "dbg_gc_runs_total", "counter", "Completed GC cycles")
+	fmt.Fprintf(&b, "dbg_gc_runs_total %d\n", mem.NumGC)
+	metric(&b, "dbg_forced_gc_runs_total", "counter", "GC runs forced by the memory monitor (-m)")
+	fmt.Fprintf(&b, "dbg_forced_gc_runs_total %d\n", forcedGC)
+	gauge(&b, "dbg_run_start_time_seconds", "Start of the run as Unix time", float64(m.start.Unix()))
+	metric(&b, "dbg_run_info", "gauge", "Mode of the run")
+	fmt.Fprintf(&b, "dbg_run_info{mode=%q} 1\n", m.mode)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	io.WriteString(w, b.String())
+}
+
+func (mm *matchupMetrics) labels(index int) string {
+	return fmt.Sprintf(`{matchup="%d",team1="%s",team2="%s"}`, index+1, escapeLabel(mm.team1), escapeLabel(mm.team2))
+}
+
+func metric(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func gauge(b *strings.Builder, name, help string, v float64) {
+	metric(b, name, "gauge", help)
+	fmt.Fprintf(b, "%s %s\n", name, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// escapeLabel escapes a label value (backslash, double quote and newline)
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
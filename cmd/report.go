@@ -0,0 +1,47 @@
+package main
+
+import (
+	"dbg_abm/internal/report"
+	"fmt"
+	"path/filepath"
+)
+
+// runReport renders the exports of a batch or tournament directory as a self-contained
+// HTML file: report <dir> [-o <file>]. Returns the process exit code.
+func runReport(args []string) int {
+	dir, output := "", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-o", "--output":
+			if i+1 < len(args) {
+				output = args[i+1]
+				i++
+			}
+		case "-h", "--help":
+			printReportUsage()
+			return 0
+		default:
+			dir = args[i]
+		}
+	}
+	if dir == "" {
+		printReportUsage()
+		return 2
+	}
+	if output == "" {
+		output = filepath.Join(dir, "report.html")
+	}
+
+	if err := report.WriteFile(dir, output); err != nil {
+		fmt.Fprintf(console, "Failed to create report: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(console, "Report written to: %s\n", output)
+	return 0
+}
+
+func printReportUsage() {
+	fmt.Fprintln(console, "Usage: report <dir> [-o <file>]")
+	fmt.Fprintln(console, "  <dir>                  Batch export directory (simulation_summary.json) or tournament export directory")
+	fmt.Fprintln(console, "  -o, --output <file>    HTML file to write (default: <dir>/report.html)")
+}
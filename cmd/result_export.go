@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"dbg_abm/internal/analysis"
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/store"
+	"dbg_abm/util"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// combinedExport streams every finished game of a run into the combined export files
+// (CSV modes 2/4, the JSONL export and the round aggregate table) and the results
+// database, so no game has to be kept until the end of the run.
+//
+// A run that appends to existing files (AppendCSV) first writes into a staging file next to
+// the target; only a complete run moves its rows into the target, an interrupted one keeps
+// them as *_partial. Without AppendCSV the target is written directly.
+type combinedExport struct {
+	sink    util.MultiSink
+	targets []exportTarget
+	failed  error // First write error; later games are not written
+
+	db       *store.GameSink // Results database (--db); committed only for a complete run
+	dbFailed error
+}
+
+type exportTarget struct {
+	label   string                      // e.g. "combined minimal CSV"
+	path    string                      // Final file
+	written string                      // File the sink writes into (path or its staging file)
+	merge   func(src, dst string) error // Appends a complete staging file to the target
+}
+
+// openCombinedExport opens the sinks selected by the configuration.
+// It returns nil if the run has no combined export.
+func openCombinedExport(config SimulationConfig) (*combinedExport, error) {
+	e := &combinedExport{}
+	var err error
+
+	switch {
+	case config.ExportFormat == "parquet" && (config.CSVExportMode == 2 || config.CSVExportMode == 4):
+		if config.CSVExportMode == 2 {
+			err = e.add(config, "combined full Parquet", "all_games_full.parquet", util.AppendParquet,
+				func(path string) (util.ResultSink, error) { return util.NewAllDataParquetSink(path) })
+		} else {
+			err = e.add(config, "combined minimal Parquet", "all_games_minimal.parquet", util.AppendParquet,
+				func(path string) (util.ResultSink, error) { return util.NewMinimalParquetSink(path) })
+		}
+		if err == nil {
+			err = e.add(config, "Parquet game table", "games.parquet", util.AppendParquet,
+				func(path string) (util.ResultSink, error) { return util.NewGamesParquetSink(path) })
+		}
+	case config.CSVExportMode == 2:
+		err = e.add(config, "combined full CSV", util.CompressedName("all_games_full.csv"), appendCSVFile,
+			func(path string) (util.ResultSink, error) { return util.NewAllDataCSVSink(path, false) })
+	case config.CSVExportMode == 4:
+		err = e.add(config, "combined minimal CSV", util.CompressedName("all_games_minimal.csv"), appendCSVFile,
+			func(path string) (util.ResultSink, error) { return util.NewMinimalCSVSink(path, false) })
+	}
+	if err == nil && config.JSONLExport {
+		err = e.add(config, "JSONL game records", util.CompressedName("all_games.jsonl"), appendJSONLFile,
+			func(path string) (util.ResultSink, error) { return util.NewJSONLSink(path, false) })
+	}
+	if err == nil && config.RoundTable {
+		err = e.add(config, "round aggregate table", util.CompressedName(analysis.RoundTableFile), analysis.MergeRoundTableFiles,
+			func(path string) (util.ResultSink, error) { return analysis.NewRoundTable(path), nil })
+	}
+	if err == nil && config.Store != nil {
+		err = e.openDB(config)
+	}
+	if err != nil {
+		e.sink.Close()
+		return nil, err
+	}
+
+	if len(e.targets) == 0 && e.db == nil {
+		return nil, nil
+	}
+	return e, nil
+}
+
+func (e *combinedExport) add(config SimulationConfig, label, name string, merge func(src, dst string) error, open func(path string) (util.ResultSink, error)) error {
+	t := exportTarget{label: label, path: filepath.Join(config.Exportpath, name), merge: merge}
+	t.written = t.path
+	if config.AppendCSV {
+		t.written = util.InsertSuffix(t.path, ".part")
+	}
+	sink, err := open(t.written)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", label, err)
+	}
+	e.sink = append(e.sink, sink)
+	e.targets = append(e.targets, t)
+	return nil
+}
+
+// openDB starts the transaction for the games of this run's matchup
+func (e *combinedExport) openDB(config SimulationConfig) error {
+	matchupID, err := config.Store.Matchup(config.RunID, config.MatchupIndex,
+		config.Team1Name, config.Team1Strategy, config.Team2Name, config.Team2Strategy)
+	if err != nil {
+		return err
+	}
+	e.db, err = config.Store.NewGameSink(matchupID)
+	if err != nil {
+		return fmt.Errorf("failed to open results database: %w", err)
+	}
+	return nil
+}
+
+// WriteGame writes a finished game to all export files. After the first error the
+// export is abandoned; the error is reported by finish.
+func (e *combinedExport) WriteGame(game *engine.Game) {
+	if e == nil || game == nil {
+		return
+	}
+	if e.failed == nil && len(e.sink) > 0 {
+		e.failed = e.sink.WriteGame(game)
+	}
+	if e.db != nil && e.dbFailed == nil {
+		e.dbFailed = e.db.WriteGame(game)
+	}
+}
+
+// finish closes the export files and moves them to their final place: appended to the
+// target for a complete AppendCSV run, renamed to *_partial for an interrupted one.
+// The games of an interrupted run are not committed to the results database, so a
+// resumed run does not store them twice.
+func (e *combinedExport) finish(partial bool, suppressOutput bool) {
+	if e == nil {
+		return
+	}
+	if e.db != nil {
+		err := e.dbFailed
+		if err != nil || partial {
+			e.db.Abort()
+		} else {
+			err = e.db.Close()
+		}
+		if err != nil && !suppressOutput {
+			fmt.Fprintf(console, "Warning: Error writing results database: %v\n", err)
+		}
+	}
+	closeErr := e.sink.Close()
+	if e.failed != nil {
+		closeErr = e.failed
+	}
+
+	for _, t := range e.targets {
+		path := partialPath(t.path, partial)
+		err := closeErr
+		if err == nil {
+			switch {
+			case partial:
+				err = os.Rename(t.written, path)
+			case t.written != t.path:
+				err = t.merge(t.written, t.path)
+			}
+		}
+		if suppressOutput {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(console, "Warning: Error exporting %s: %v\n", t.label, err)
+		} else {
+			fmt.Fprintf(console, "✅ %s exported: %s\n", strings.ToUpper(t.label[:1])+t.label[1:], path)
+		}
+	}
+}
+
+func appendCSVFile(src, dst string) error   { return appendExportFile(src, dst, true) }
+func appendJSONLFile(src, dst string) error { return appendExportFile(src, dst, false) }
+
+// appendExportFile appends the staging file src to dst and removes it. If dst already
+// has content, the header line of src is skipped. Compressed files are decompressed and
+// appended as a new gzip member; zstd targets are rewritten as a single frame because
+// Python's zstandard reader (used by pandas) stops after the first frame.
+func appendExportFile(src, dst string, header bool) error {
+	info, err := os.Stat(dst)
+	if os.IsNotExist(err) || (err == nil && info.Size() == 0) {
+		return os.Rename(src, dst)
+	}
+	if err != nil {
+		return err
+	}
+
+	in, err := util.OpenFile(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	target := dst
+	var prev io.ReadCloser
+	if util.CompressionExt(dst) == ".zst" {
+		target = util.InsertSuffix(dst, ".tmp")
+		if prev, err = util.OpenFile(dst); err != nil {
+			return err
+		}
+		defer prev.Close()
+	}
+	var out io.WriteCloser
+	if prev != nil {
+		out, err = util.CreateFile(target)
+	} else {
+		out, err = util.AppendFile(target)
+	}
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(in)
+	if header {
+		if _, err := r.ReadString('\n'); err != nil && err != io.EOF {
+			out.Close()
+			return err
+		}
+	}
+	if prev != nil {
+		if _, err := io.Copy(out, prev); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if prev != nil {
+		prev.Close()
+		if err := os.Rename(target, dst); err != nil {
+			return err
+		}
+	}
+	in.Close()
+	return os.Remove(src)
+}
+
+// openResultStore opens the results database of --db and records the run
+func openResultStore(config *SimulationConfig, kind string) error {
+	db, err := store.Open(config.DBPath)
+	if err != nil {
+		return err
+	}
+	runID, err := db.StartRun(store.RunInfo{
+		Kind:      kind,
+		ExportDir: config.Exportpath,
+		Seed:      config.BaseSeed,
+		Rules:     config.GameRules,
+		Config:    config,
+	})
+	if err != nil {
+		db.Close()
+		return err
+	}
+	config.Store = db
+	config.RunID = runID
+	return nil
+}
+
+// closeResultStore records the outcome of the run and closes the results database
+func closeResultStore(config *SimulationConfig, runErr error) {
+	if config.Store == nil {
+		return
+	}
+	status := store.StatusCompleted
+	switch {
+	case errors.Is(runErr, context.Canceled):
+		status = store.StatusInterrupted
+	case runErr != nil:
+		status = store.StatusFailed
+	}
+	if err := config.Store.FinishRun(config.RunID, status); err != nil {
+		fmt.Fprintf(console, "Warning: Failed to update results database: %v\n", err)
+	}
+	config.Store.Close()
+	config.Store = nil
+}
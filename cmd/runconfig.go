@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"dbg_abm/internal/configfile"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// RunConfig is a run configuration file (--config): everything a run is set up with on
+// the command line as one declarative JSON, YAML or TOML file. Only the options a user sets
+// are keys; runtime state of SimulationConfig (append mode, output suppression, open logs)
+// cannot be set from a file. Options given on the command line override the file.
+type RunConfig struct {
+	NumSimulations        int    `json:"num_simulations"`
+	MaxConcurrent         int    `json:"max_concurrent"`
+	MemoryLimit           int    `json:"memory_limit"`
+	Team1Name             string `json:"team1_name"`
+	Team1Strategy         string `json:"team1_strategy"`
+	Team2Name             string `json:"team2_name"`
+	Team2Strategy         string `json:"team2_strategy"`
+	Sequential            bool   `json:"sequential"`
+	ExportDetailedResults bool   `json:"export_detailed_results"`
+	ExportRounds          bool   `json:"export_rounds"`
+	AdvancedAnalysis      bool   `json:"advanced_analysis"`
+	CSVExportMode         int    `json:"csv_export_mode"`
+	ExportFormat          string `json:"export_format"` // csv or parquet
+	JSONLExport           bool   `json:"jsonl_export"`
+	RoundTable            bool   `json:"round_table"`
+	Compress              string `json:"compress"`
+	ExportPath            string `json:"export_path"` // Relative to the working directory
+	DBPath                string `json:"db_path"`
+	EventsPath            string `json:"events_path"`
+	BaseSeed              int64  `json:"base_seed"` // Game i is seeded with base_seed+i
+	CheckpointEvery       int    `json:"checkpoint_every"`
+
+	GameRules     string               `json:"game_rules"`    // -g: rules file or profile:<name>[+<file>]
+	Distributions string               `json:"distributions"` // -dist
+	TUI           bool                 `json:"tui"`
+	MetricsAddr   string               `json:"metrics_addr"`
+	Tournament    *RunTournamentConfig `json:"tournament"` // Runs a tournament instead of a single matchup
+}
+
+// newRunConfig returns a run configuration with the options of config, which keys the
+// file leaves out keep
+func newRunConfig(config SimulationConfig) RunConfig {
+	return RunConfig{
+		NumSimulations:        config.NumSimulations,
+		MaxConcurrent:         config.MaxConcurrent,
+		MemoryLimit:           config.MemoryLimit,
+		Team1Name:             config.Team1Name,
+		Team1Strategy:         config.Team1Strategy,
+		Team2Name:             config.Team2Name,
+		Team2Strategy:         config.Team2Strategy,
+		Sequential:            config.Sequential,
+		ExportDetailedResults: config.ExportDetailedResults,
+		ExportRounds:          config.ExportRounds,
+		AdvancedAnalysis:      config.AdvancedAnalysis,
+		CSVExportMode:         config.CSVExportMode,
+		ExportFormat:          config.ExportFormat,
+		JSONLExport:           config.JSONLExport,
+		RoundTable:            config.RoundTable,
+		Compress:              config.Compress,
+		ExportPath:            config.Exportpath,
+		DBPath:                config.DBPath,
+		EventsPath:            config.EventsPath,
+		BaseSeed:              config.BaseSeed,
+		CheckpointEvery:       config.CheckpointEvery,
+	}
+}
+
+// apply copies the simulation options of the run configuration to config. The export path,
+// rules, distributions and tournament options are read by the caller.
+func (rc RunConfig) apply(config *SimulationConfig) {
+	config.NumSimulations = rc.NumSimulations
+	config.MaxConcurrent = rc.MaxConcurrent
+	config.MemoryLimit = rc.MemoryLimit
+	config.Team1Name = rc.Team1Name
+	config.Team1Strategy = rc.Team1Strategy
+	config.Team2Name = rc.Team2Name
+	config.Team2Strategy = rc.Team2Strategy
+	config.Sequential = rc.Sequential
+	config.ExportDetailedResults = rc.ExportDetailedResults
+	config.ExportRounds = rc.ExportRounds
+	config.AdvancedAnalysis = rc.AdvancedAnalysis
+	config.CSVExportMode = rc.CSVExportMode
+	config.ExportFormat = rc.ExportFormat
+	config.JSONLExport = rc.JSONLExport
+	config.RoundTable = rc.RoundTable
+	config.Compress = rc.Compress
+	config.DBPath = rc.DBPath
+	config.EventsPath = rc.EventsPath
+	config.BaseSeed = rc.BaseSeed
+	config.CheckpointEvery = rc.CheckpointEvery
+	if t := rc.Tournament; t != nil && t.Seed != 0 {
+		config.BaseSeed = t.Seed
+	}
+}
+
+// RunTournamentConfig holds the tournament options of a run configuration file
+type RunTournamentConfig struct {
+	Strategies []string `json:"strategies"`
+	Format     string   `json:"format"`     // roundrobin or doubleroundrobin
+	Games      int      `json:"games"`      // Games per matchup (average budget with adaptive sampling)
+	Sampling   string   `json:"sampling"`   // fixed, wilson or sprt
+	TargetCI   float64  `json:"target_ci"`  // Half-width for wilson sampling
+	MinGames   int      `json:"min_games"`  // Adaptive sampling bounds
+	MaxGames   int      `json:"max_games"`  //
+	BatchSize  int      `json:"batch_size"` // Games per adaptive sampling batch
+	Seed       int64    `json:"seed"`       // Game i of a matchup is seeded with seed+i (overrides base_seed)
+}
+
+// findRunConfig returns the path given with --config ("" without)
+func findRunConfig(args []string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "--config" {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// loadRunConfig decodes the run configuration file at path over rc, so that keys the file
+// leaves out keep the values rc already holds. Unknown keys are errors. Relative rules and
+// distributions paths are resolved against the directory of the file.
+func loadRunConfig(path string, rc *RunConfig) error {
+	data, _, err := configfile.ReadJSON(path)
+	if err != nil {
+		return fmt.Errorf("cannot read run configuration: %w", err)
+	}
+	rules, dist := rc.GameRules, rc.Distributions
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(rc); err != nil {
+		return fmt.Errorf("invalid run configuration '%s': %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	if rc.GameRules != rules {
+		rc.GameRules = resolveRulesSpec(dir, rc.GameRules)
+	}
+	if rc.Distributions != dist && rc.Distributions != "" && !filepath.IsAbs(rc.Distributions) {
+		rc.Distributions = filepath.Join(dir, rc.Distributions)
+	}
+	if t := rc.Tournament; t != nil && len(t.Strategies) < 2 {
+		return fmt.Errorf("invalid run configuration '%s': tournament needs at least two strategies", path)
+	}
+	return nil
+}
+
+// resolveRulesSpec makes the file of a -g spec relative to dir (profiles stay as they are)
+func resolveRulesSpec(dir, spec string) string {
+	if spec == "" || spec == "default" {
+		return spec
+	}
+	prefix, file := "", spec
+	if name, ok := strings.CutPrefix(spec, "profile:"); ok {
+		profile, rest, layered := strings.Cut(name, "+")
+		if !layered {
+			return spec
+		}
+		prefix, file = "profile:"+profile+"+", rest
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	return prefix + file
+}
@@ -18,11 +18,13 @@ import (
 // SimulationResult holds the result of a single simulation
 type SimulationResult struct {
 	GameID         string
-	Team1Won       bool
+	Outcome        engine.Outcome
 	Team1Score     int
 	Team2Score     int
 	TotalRounds    int
 	WentToOvertime bool
+	OTCount        int
+	Seed           int64
 	Team1Economics TeamGameEconomics
 	Team2Economics TeamGameEconomics
 	GameData       *engine.Game // Optional: for advanced analysis
@@ -70,11 +72,15 @@ type WorkerPool struct {
 	ctx     context.Context
 	cancel  context.CancelFunc
 	stats   *analysis.SimulationStats
+
+	interrupt     chan struct{} // Closed by Interrupt: queued jobs are dropped
+	interruptOnce sync.Once
 }
 
 // SimulationJob represents a single simulation job
 type SimulationJob struct {
 	SimID  int
+	Seed   int64 // 0 = random seed
 	Config SimulationConfig
 }
 
@@ -88,6 +94,8 @@ func NewWorkerPool(workers int, stats *analysis.SimulationStats) *WorkerPool {
 		ctx:     ctx,
 		cancel:  cancel,
 		stats:   stats,
+
+		interrupt: make(chan struct{}),
 	}
 }
 
@@ -107,6 +115,22 @@ func (wp *WorkerPool) Stop() {
 	close(wp.results) // Close results channel after workers are done
 }
 
+// Interrupt stops the pool from starting new games. Games already running finish
+// normally and their results are still delivered; queued jobs are dropped.
+func (wp *WorkerPool) Interrupt() {
+	wp.interruptOnce.Do(func() { close(wp.interrupt) })
+}
+
+// interrupted reports whether Interrupt was called
+func (wp *WorkerPool) interrupted() bool {
+	select {
+	case <-wp.interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
 // AddJob adds a simulation job to the queue, returns false if pool is shutting down
 func (wp *WorkerPool) AddJob(job SimulationJob) bool {
 	select {
@@ -114,6 +138,8 @@ func (wp *WorkerPool) AddJob(job SimulationJob) bool {
 		return true
 	case <-wp.ctx.Done():
 		return false
+	case <-wp.interrupt:
+		return false
 	}
 }
 
@@ -127,6 +153,9 @@ func (wp *WorkerPool) worker(workerID int) {
 			if !ok {
 				return // Channel closed, exit worker
 			}
+			if wp.interrupted() {
+				continue // Drain queued jobs without running them
+			}
 			result := wp.processSingleSimulation(job)
 
 			select {
@@ -183,6 +212,8 @@ func (wp *WorkerPool) processSingleSimulation(job SimulationJob) SimulationResul
 			false,
 			job.Config.CSVExportMode,
 			job.Config.Exportpath,
+			job.Seed,
+			job.Config.EventLog,
 		)
 
 		var result SimulationResult
@@ -195,11 +226,13 @@ func (wp *WorkerPool) processSingleSimulation(job SimulationJob) SimulationResul
 		} else {
 			result = SimulationResult{
 				GameID:         gameResult.GameID,
-				Team1Won:       gameResult.Team1Won,
+				Outcome:        gameResult.Outcome,
 				Team1Score:     gameResult.Team1Score,
 				Team2Score:     gameResult.Team2Score,
 				TotalRounds:    gameResult.TotalRounds,
 				WentToOvertime: gameResult.WentToOvertime,
+				OTCount:        gameResult.OTCount,
+				Seed:           gameResult.Seed,
 				Team1Economics: gameResult.Team1Economics,
 				Team2Economics: gameResult.Team2Economics,
 				GameData:       gameResult.GameData, // Pass game data for advanced analysis
@@ -244,42 +277,61 @@ func RunParallelSimulations(config SimulationConfig) (*analysis.SimulationStats,
 		Exportpath:            config.Exportpath, // Use the export path from main config
 	})
 
-	// Storage for games if we need combined CSV export (modes 2 or 4)
-	var allGames []*engine.Game
-	var gamesMutex sync.Mutex
-	if config.CSVExportMode == 2 || config.CSVExportMode == 4 {
-		allGames = make([]*engine.Game, 0, config.NumSimulations)
+	// Combined exports (CSV modes 2/4, JSONL) are written game by game as results arrive
+	var export *combinedExport
+	if !config.StreamOnly {
+		var err error
+		if export, err = openCombinedExport(config); err != nil {
+			return nil, err
+		}
 	}
 
 	// Advanced analysis removed
 
 	if !config.SuppressOutput {
-		fmt.Printf("Starting %d simulations with %d concurrent workers...\n",
+		fmt.Fprintf(console, "Starting %d simulations with %d concurrent workers...\n",
 			config.NumSimulations, config.MaxConcurrent)
-		fmt.Printf("Memory limit: %d MB\n", config.MemoryLimit)
+		fmt.Fprintf(console, "Memory limit: %d MB\n", config.MemoryLimit)
 
 		if config.ExportDetailedResults {
-			fmt.Printf("Individual result export: ENABLED (results will be saved to %s/)\n", config.Exportpath)
+			fmt.Fprintf(console, "Individual result export: ENABLED (results will be saved to %s/)\n", config.Exportpath)
 			if config.NumSimulations > 10000 {
-				fmt.Printf("WARNING: Exporting %d individual results may create filesystem pressure\n", config.NumSimulations)
+				fmt.Fprintf(console, "WARNING: Exporting %d individual results may create filesystem pressure\n", config.NumSimulations)
 			}
 		} else {
-			fmt.Println("Individual result export: DISABLED (summary-only mode)")
+			fmt.Fprintln(console, "Individual result export: DISABLED (summary-only mode)")
 		}
 
 		if config.CSVExportMode > 0 {
-			fmt.Printf("CSV export mode: %d\n", config.CSVExportMode)
+			fmt.Fprintf(console, "CSV export mode: %d\n", config.CSVExportMode)
 		}
 	}
 
 	// Create worker pool
 	pool := NewWorkerPool(config.MaxConcurrent, stats)
 	pool.Start()
+	if config.Dashboard != nil {
+		config.Dashboard.Track(stats)
+	}
+	if config.Metrics != nil {
+		config.Metrics.Track(config.MatchupIndex, stats)
+	}
 
 	// Create shutdown context for monitoring goroutines
 	monitorCtx, monitorCancel := context.WithCancel(context.Background())
 	defer monitorCancel()
 
+	// Stop starting new games once the caller's context is cancelled (e.g. Ctrl-C)
+	if config.Context != nil {
+		go func() {
+			select {
+			case <-config.Context.Done():
+				pool.Interrupt()
+			case <-monitorCtx.Done():
+			}
+		}()
+	}
+
 	// Create error logger
 	errorLogger := NewErrorLogger(config.Exportpath)
 
@@ -287,11 +339,7 @@ func RunParallelSimulations(config SimulationConfig) (*analysis.SimulationStats,
 	resultsDone := make(chan bool)
 	go func() {
 		defer close(resultsDone)
-		if config.CSVExportMode == 2 || config.CSVExportMode == 4 {
-			collectResultsWithGames(pool.results, stats, config.NumSimulations, &allGames, &gamesMutex, errorLogger)
-		} else {
-			collectResults(pool.results, stats, config.NumSimulations, errorLogger)
-		}
+		collectResults(pool.results, stats, config.NumSimulations, export, errorLogger, config.ResultStream)
 	}()
 
 	// Track memory usage
@@ -317,21 +365,27 @@ func RunParallelSimulations(config SimulationConfig) (*analysis.SimulationStats,
 	jobsSubmitted := 0
 	for simID := 0; simID < config.NumSimulations; simID++ {
 		job := SimulationJob{
-			SimID:  simID + 1,
+			SimID:  config.SimOffset + simID + 1,
 			Config: config,
 		}
+		if config.BaseSeed != 0 {
+			job.Seed = config.BaseSeed + int64(config.SimOffset+simID)
+		}
 		if pool.AddJob(job) {
 			jobsSubmitted++
 		} else {
+			if pool.interrupted() {
+				break
+			}
 			if !config.SuppressOutput {
-				fmt.Printf("Warning: Failed to submit job %d, pool is shutting down\n", simID+1)
+				fmt.Fprintf(console, "Warning: Failed to submit job %d, pool is shutting down\n", simID+1)
 			}
 			break
 		}
 	}
 
 	if !config.SuppressOutput {
-		fmt.Printf("Successfully submitted %d/%d jobs\n", jobsSubmitted, config.NumSimulations)
+		fmt.Fprintf(console, "Successfully submitted %d/%d jobs\n", jobsSubmitted, config.NumSimulations)
 	}
 
 	// Stop the worker pool and wait for completion
@@ -340,37 +394,23 @@ func RunParallelSimulations(config SimulationConfig) (*analysis.SimulationStats,
 	// Signal monitoring goroutines to stop
 	monitorCancel()
 
-	// Wait for all results to be collected with timeout
-	timeout := time.After(30 * time.Second)
-	select {
-	case <-resultsDone:
-		// Results collected successfully
-	case <-timeout:
-		if !config.SuppressOutput {
-			fmt.Println("Warning: Timeout waiting for results collection")
-		}
-	}
+	// Wait for the collector before returning: callers finish the exports and close
+	// the result stream afterwards, both of which it may still be writing to. It ends
+	// once pool.Stop closed the results channel; the monitors end with monitorCancel.
+	<-resultsDone
+	<-memoryMonitorDone
+	<-progressDone
 
-	select {
-	case <-memoryMonitorDone:
-		// Memory monitor finished
-	case <-timeout:
-		if !config.SuppressOutput {
-			fmt.Println("Warning: Timeout waiting for memory monitor")
-		}
+	if config.Dashboard != nil {
+		config.Dashboard.Untrack()
 	}
-
-	select {
-	case <-progressDone:
-		// Progress reporter finished
-	case <-timeout:
-		if !config.SuppressOutput {
-			fmt.Println("Warning: Timeout waiting for progress reporter")
-		}
+	if config.Metrics != nil {
+		config.Metrics.Untrack(config.MatchupIndex)
 	}
 
 	// Calculate final statistics using unified analysis package
 	stats.ExecutionTime = time.Since(startTime)
+	stats.Partial = pool.interrupted() && stats.CompletedSims+stats.FailedSims < int64(config.NumSimulations)
 	stats.CalculateFinalStats()
 
 	// Final garbage collection and memory stats
@@ -391,63 +431,55 @@ func RunParallelSimulations(config SimulationConfig) (*analysis.SimulationStats,
 	}
 
 	// Export summary statistics
-	summaryPath := filepath.Join(config.Exportpath, "simulation_summary.json")
-	if err := exportSummary(stats, summaryPath); err != nil {
-		if !config.SuppressOutput {
-			fmt.Printf("Warning: Failed to export summary: %v\n", err)
-		}
-	}
-
-	// Export combined CSV if mode 2 or 4
-	if config.CSVExportMode == 2 && len(allGames) > 0 {
-		if !config.SuppressOutput {
-			fmt.Println("\nExporting combined full CSV...")
-		}
-		csvPath := filepath.Join(config.Exportpath, "all_games_full.csv")
-		err := util.ExportAllGamesAllDataCSV(allGames, csvPath)
-		if err != nil {
+	if !config.StreamOnly {
+		summaryPath := filepath.Join(config.Exportpath, "simulation_summary.json")
+		if err := exportSummary(stats, summaryPath); err != nil {
 			if !config.SuppressOutput {
-				fmt.Printf("Warning: Error exporting combined full CSV: %v\n", err)
-			}
-		} else {
-			if !config.SuppressOutput {
-				fmt.Printf("✅ Combined full CSV exported: %s\n", csvPath)
-			}
-		}
-	} else if config.CSVExportMode == 4 && len(allGames) > 0 {
-		if !config.SuppressOutput {
-			fmt.Println("\nExporting combined minimal CSV...")
-		}
-		csvPath := filepath.Join(config.Exportpath, "all_games_minimal.csv")
-		err := util.ExportAllGamesMinimalCSV(allGames, csvPath)
-		if err != nil {
-			if !config.SuppressOutput {
-				fmt.Printf("Warning: Error exporting combined minimal CSV: %v\n", err)
-			}
-		} else {
-			if !config.SuppressOutput {
-				fmt.Printf("✅ Combined minimal CSV exported: %s\n", csvPath)
+				fmt.Fprintf(console, "Warning: Failed to export summary: %v\n", err)
 			}
 		}
 	}
 
+	// Close the combined exports (mode 2 or 4, JSONL)
+	export.finish(stats.Partial, config.SuppressOutput)
+
 	// Advanced analysis removed
 
 	// Print final results
 	if !config.SuppressOutput {
-		analysis.PrintEnhancedStats(stats)
+		analysis.PrintEnhancedStats(console, stats)
 
 		// Print export information
-		fmt.Printf("\nResults exported to: %s/\n", config.Exportpath)
+		fmt.Fprintf(console, "\nResults exported to: %s/\n", config.Exportpath)
 		if config.ExportDetailedResults {
-			fmt.Printf("- Individual game results: %d JSON files\n", atomic.LoadInt64(&stats.CompletedSims))
+			fmt.Fprintf(console, "- Individual game results: %d JSON files\n", atomic.LoadInt64(&stats.CompletedSims))
+		}
+		fmt.Fprintln(console, "- Summary statistics: simulation_summary.json")
+		if stats.Partial {
+			fmt.Fprintf(console, "⚠️  Partial results: %d/%d simulations completed before the interrupt\n",
+				atomic.LoadInt64(&stats.CompletedSims), config.NumSimulations)
 		}
-		fmt.Println("- Summary statistics: simulation_summary.json")
 	}
 
+	if stats.Partial {
+		return stats, fmt.Errorf("stopped after %d/%d simulations: %w",
+			atomic.LoadInt64(&stats.CompletedSims), config.NumSimulations, config.Context.Err())
+	}
 	return stats, nil
-} // collectResults processes simulation results and updates statistics
-func collectResults(results <-chan SimulationResult, stats *analysis.SimulationStats, totalSims int, errorLogger *ErrorLogger) {
+}
+
+// partialPath marks the export path of an interrupted run (all_games_minimal.csv.gz ->
+// all_games_minimal_partial.csv.gz) so it never mixes with the files of complete runs
+func partialPath(path string, partial bool) string {
+	if !partial {
+		return path
+	}
+	return util.InsertSuffix(path, "_partial")
+}
+
+// collectResults processes simulation results, updates statistics and streams
+// finished games into the combined exports (if any)
+func collectResults(results <-chan SimulationResult, stats *analysis.SimulationStats, totalSims int, export *combinedExport, errorLogger *ErrorLogger, stream chan<- SimulationResult) {
 	processedCount := int64(0)
 
 	for result := range results {
@@ -467,52 +499,28 @@ func collectResults(results <-chan SimulationResult, stats *analysis.SimulationS
 
 		// Update statistics for successful simulations
 		stats.UpdateGameResult(
-			result.Team1Won,
+			result.Outcome,
 			result.Team1Score,
 			result.Team2Score,
 			result.TotalRounds,
 			result.WentToOvertime,
 			0, // responseTime - not tracked in current implementation
 		)
+		stats.UpdateAggregates(result.GameData)
 
+		export.WriteGame(result.GameData)
+		forwardResult(stream, result)
 	}
 }
 
-// collectResultsWithGames processes simulation results and stores games for CSV export
-func collectResultsWithGames(results <-chan SimulationResult, stats *analysis.SimulationStats, totalSims int, allGames *[]*engine.Game, gamesMutex *sync.Mutex, errorLogger *ErrorLogger) {
-	processedCount := int64(0)
-
-	for result := range results {
-		atomic.AddInt64(&processedCount, 1)
-
-		if result.Error != nil {
-			stats.UpdateFailedSimulation()
-
-			// Log the error to file
-			if err := errorLogger.LogError(result.GameID, result.Error); err != nil {
-				// If logging fails, we don't want to crash the simulation
-				fmt.Fprintf(os.Stderr, "Warning: Failed to log error for %s: %v\n", result.GameID, err)
-			}
-			continue
-		}
-
-		// Update statistics for successful simulations
-		stats.UpdateGameResult(
-			result.Team1Won,
-			result.Team1Score,
-			result.Team2Score,
-			result.TotalRounds,
-			result.WentToOvertime,
-			0,
-		)
-
-		// Store game data for combined CSV export
-		if result.GameData != nil {
-			gamesMutex.Lock()
-			*allGames = append(*allGames, result.GameData)
-			gamesMutex.Unlock()
-		}
+// forwardResult passes a successful result on to the caller's result stream (if any).
+// The full game is stripped so consumers of the stream don't keep it alive.
+func forwardResult(stream chan<- SimulationResult, result SimulationResult) {
+	if stream == nil {
+		return
 	}
+	result.GameData = nil
+	stream <- result
 }
 
 // monitorMemoryUsageWithContext monitors memory usage and forces GC when needed
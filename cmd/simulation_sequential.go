@@ -3,7 +3,6 @@ package main
 import (
 	"dbg_abm/internal/analysis"
 	"dbg_abm/internal/engine"
-	"dbg_abm/util"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -15,19 +14,20 @@ import (
 func updateglobalstats(stats *analysis.SimulationStats, result *GameResult) {
 	// Use the unified analysis package method
 	stats.UpdateGameResult(
-		result.Team1Won,
+		result.Outcome,
 		result.Team1Score,
 		result.Team2Score,
 		result.TotalRounds,
 		result.WentToOvertime,
 		0, // responseTime - not tracked in sequential mode
 	)
+	stats.UpdateAggregates(result.GameData)
 
 }
 
 func showstats(stats *analysis.SimulationStats) {
 	// Use the unified analysis package for enhanced reporting
-	analysis.PrintEnhancedStats(stats)
+	analysis.PrintEnhancedStats(console, stats)
 }
 
 func exportSummary_v2(stats *analysis.SimulationStats, pathOrID string) error {
@@ -55,51 +55,56 @@ func sequentialsimulation(config SimulationConfig, gameRules engine.GameRules) e
 
 	// Advanced analysis removed
 
-	// Storage for games if we need combined CSV export (modes 2 or 4)
-	var allGames []*engine.Game
-	if config.CSVExportMode == 2 || config.CSVExportMode == 4 {
-		allGames = make([]*engine.Game, 0, config.NumSimulations)
+	// Combined exports (CSV modes 2/4, JSONL) are written game by game
+	export, err := openCombinedExport(config)
+	if err != nil {
+		return err
 	}
 
 	// Display simulation mode and export information
 	if !config.SuppressOutput {
 		if config.ExportDetailedResults {
-			fmt.Printf("Sequential simulation with individual result export enabled\n")
-			fmt.Printf("Results will be saved to: %s/\n", config.Exportpath)
+			fmt.Fprintf(console, "Sequential simulation with individual result export enabled\n")
+			fmt.Fprintf(console, "Results will be saved to: %s/\n", config.Exportpath)
 			if config.NumSimulations > 10000 {
-				fmt.Printf("WARNING: Exporting %d individual results may create filesystem pressure\n", config.NumSimulations)
+				fmt.Fprintf(console, "WARNING: Exporting %d individual results may create filesystem pressure\n", config.NumSimulations)
 			}
 		} else {
-			fmt.Println("Sequential simulation with summary-only mode")
+			fmt.Fprintln(console, "Sequential simulation with summary-only mode")
 		}
 
 		if config.CSVExportMode > 0 {
-			fmt.Printf("CSV export mode: %d\n", config.CSVExportMode)
+			fmt.Fprintf(console, "CSV export mode: %d\n", config.CSVExportMode)
 		}
 
-		fmt.Printf("Starting %d sequential simulations...\n", config.NumSimulations)
+		fmt.Fprintf(console, "Starting %d sequential simulations...\n", config.NumSimulations)
 	}
 
 	for i := 0; i < config.NumSimulations; i++ {
+		if config.Context != nil && config.Context.Err() != nil {
+			stats.Partial = true
+			break
+		}
+
 		// Generate a simulation prefix for this run
 		simPrefix := fmt.Sprintf("seq_sim_%d_", i+1)
 
 		// Simulate a single game with pre-validated rules
+		var seed int64
+		if config.BaseSeed != 0 {
+			seed = config.BaseSeed + int64(i)
+		}
 		result, err := StartGame_default(config.Team1Name, config.Team1Strategy, config.Team2Name,
-			config.Team2Strategy, gameRules, simPrefix, config.ExportDetailedResults, false, config.CSVExportMode, config.Exportpath)
+			config.Team2Strategy, gameRules, simPrefix, config.ExportDetailedResults, false, config.CSVExportMode, config.Exportpath, seed, config.EventLog)
 		if err != nil {
 			if !config.SuppressOutput {
-				fmt.Printf("Simulation %d failed: %v\n", i+1, err)
+				fmt.Fprintf(console, "Simulation %d failed: %v\n", i+1, err)
 			}
 			continue
 		}
 
-		// Store game data for combined CSV export
-		if config.CSVExportMode == 2 || config.CSVExportMode == 4 {
-			if result.GameData != nil {
-				allGames = append(allGames, result.GameData)
-			}
-		}
+		// Stream game data into the combined exports
+		export.WriteGame(result.GameData)
 
 		// Update statistics with the result
 		updateglobalstats(stats, result)
@@ -126,7 +131,7 @@ func sequentialsimulation(config SimulationConfig, gameRules engine.GameRules) e
 		}
 		if config.NumSimulations > 100 && (i+1)%progressInterval == 0 {
 			if !config.SuppressOutput {
-				fmt.Printf("Progress: %d/%d simulations completed\n", i+1, config.NumSimulations)
+				fmt.Fprintf(console, "Progress: %d/%d simulations completed\n", i+1, config.NumSimulations)
 			}
 		}
 	}
@@ -134,43 +139,17 @@ func sequentialsimulation(config SimulationConfig, gameRules engine.GameRules) e
 	// Calculate final execution time
 	endtime := time.Now()
 	stats.ExecutionTime = endtime.Sub(starttime)
+	stats.CalculateFinalStats()
 
-	// Export combined CSV if mode 2 or 4
-	if config.CSVExportMode == 2 && len(allGames) > 0 {
-		if !config.SuppressOutput {
-			fmt.Println("\nExporting combined full CSV...")
-		}
-		csvPath := fmt.Sprintf("%s/all_games_full.csv", config.Exportpath)
-		err := util.ExportAllGamesAllDataCSV(allGames, csvPath)
-		if err != nil {
-			if !config.SuppressOutput {
-				fmt.Printf("Warning: Error exporting combined full CSV: %v\n", err)
-			}
-		} else {
-			if !config.SuppressOutput {
-				fmt.Printf("✅ Combined full CSV exported: %s\n", csvPath)
-			}
-		}
-	} else if config.CSVExportMode == 4 && len(allGames) > 0 {
-		if !config.SuppressOutput {
-			fmt.Println("\nExporting combined minimal CSV...")
-		}
-		csvPath := fmt.Sprintf("%s/all_games_minimal.csv", config.Exportpath)
-		err := util.ExportAllGamesMinimalCSV(allGames, csvPath)
-		if err != nil {
-			if !config.SuppressOutput {
-				fmt.Printf("Warning: Error exporting combined minimal CSV: %v\n", err)
-			}
-		} else {
-			if !config.SuppressOutput {
-				fmt.Printf("✅ Combined minimal CSV exported: %s\n", csvPath)
-			}
-		}
-	}
+	// Close the combined exports (mode 2 or 4, JSONL)
+	export.finish(stats.Partial, config.SuppressOutput)
 
 	// Generate comprehensive final summary
 	if !config.SuppressOutput {
 		showstats(stats)
+		if stats.Partial {
+			fmt.Fprintf(console, "⚠️  Partial results: %d/%d simulations completed before the interrupt\n", stats.CompletedSims, config.NumSimulations)
+		}
 	}
 
 	// Advanced analysis removed
@@ -179,17 +158,20 @@ func sequentialsimulation(config SimulationConfig, gameRules engine.GameRules) e
 	summaryPath := fmt.Sprintf("%s/simulation_summary.json", config.Exportpath)
 	if err := exportSummary_v2(stats, summaryPath); err != nil {
 		if !config.SuppressOutput {
-			fmt.Printf("Warning: Failed to export summary: %v\n", err)
+			fmt.Fprintf(console, "Warning: Failed to export summary: %v\n", err)
 		}
 	}
 
 	if !config.SuppressOutput {
-		fmt.Printf("\nResults exported to: %s/\n", config.Exportpath)
+		fmt.Fprintf(console, "\nResults exported to: %s/\n", config.Exportpath)
 		if config.ExportDetailedResults {
-			fmt.Printf("- Individual game results: %d JSON files\n", stats.CompletedSims)
+			fmt.Fprintf(console, "- Individual game results: %d JSON files\n", stats.CompletedSims)
 		}
-		fmt.Println("- Summary statistics: simulation_summary.json")
+		fmt.Fprintln(console, "- Summary statistics: simulation_summary.json")
 	}
 
+	if stats.Partial {
+		return fmt.Errorf("stopped after %d/%d simulations: %w", stats.CompletedSims, config.NumSimulations, config.Context.Err())
+	}
 	return nil
 }
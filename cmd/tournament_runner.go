@@ -1,233 +1,302 @@
 package main
 
 import (
+	"context"
 	"dbg_abm/internal/analysis"
-	"dbg_abm/internal/strategy"
+	"dbg_abm/internal/engine"
 	"dbg_abm/internal/tournament"
+	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
+	"strconv"
 )
 
-type MatchSpec struct {
-	Team1Strategy string
-	Team2Strategy string
-}
+func runTournament(cfg *SimulationConfig, custom *CustomConfig, strategiesCSV string, format string, games int, sampling tournament.SamplingSpec) error {
+	tcfg := tournament.TournamentConfig{
+		Sampling: sampling,
+		Format:   tournament.Format(format),
+		Series: tournament.SeriesSpec{
+			NumGames:      games,
+			Seed:          cfg.BaseSeed,
+			RandomSeeds:   cfg.BaseSeed == 0, // Like batch runs without --seed
+			MaxConcurrent: cfg.MaxConcurrent,
+		},
+		Participants: tournament.ParseParticipants(strategiesCSV),
+		Rules:        custom.GameRules,
+		Runner:       cliMatchupRunner(cfg),
+
+		CheckpointDir:   cfg.Exportpath,
+		Resume:          cfg.Resume,
+		CheckpointEvery: cfg.CheckpointEvery,
+		Options: map[string]string{
+			"csv_export_mode": strconv.Itoa(cfg.CSVExportMode),
+			"sequential":      strconv.FormatBool(cfg.Sequential),
+			"jsonl":           strconv.FormatBool(cfg.JSONLExport),
+			"round_table":     strconv.FormatBool(cfg.RoundTable),
+			"export_format":   cfg.ExportFormat,
+			"compress":        cfg.Compress,
+		},
+	}
 
-type MatchResult struct {
-	Team1Wins int
-	Team2Wins int
-}
+	// Validate all strategies upfront
+	fmt.Fprintln(console, "Validating strategies...")
+	if err := tcfg.Validate(); err != nil {
+		return fmt.Errorf("❌ %v", err)
+	}
+	for _, strat := range tcfg.Participants {
+		fmt.Fprintf(console, "  ✓ %s\n", strat)
+	}
 
-// RoundRobinSchedule creates all matchups for a round-robin tournament
-func RoundRobinSchedule(strategies []string) []MatchSpec {
-	var matches []MatchSpec
-	for i := 0; i < len(strategies); i++ {
-		for j := i + 1; j < len(strategies); j++ {
-			// A vs B
-			matches = append(matches, MatchSpec{
-				Team1Strategy: strategies[i],
-				Team2Strategy: strategies[j],
-			})
-			// B vs A (for balance, though starting side is randomized) -> not necessary, one is enough since sides are randomized in each game
-			// matches = append(matches, MatchSpec{
-			// 	Team1Strategy: strategies[j],
-			// 	Team2Strategy: strategies[i],
-			// })
+	// Distributed mode: the games run on worker processes, only the merged output is written here
+	if cfg.CoordinatorAddr != "" {
+		coord, err := NewCoordinator(cfg.CoordinatorAddr, cfg.DistBatch)
+		if err != nil {
+			return err
 		}
+		defer coord.Close()
+		tcfg.Runner = coord.Runner()
+		tcfg.Options["runner"] = "distributed"
+		if cfg.CheckpointEvery == 0 {
+			// Hand out a whole matchup at once so that all workers have batches to run
+			tcfg.CheckpointEvery = games
+		}
+		fmt.Fprintf(console, "Coordinator listening on %s (start workers with --worker <host:port>)\n", coord.Addr())
 	}
-	return matches
-}
 
-func runTournament(cfg *SimulationConfig, custom *CustomConfig, strategiesCSV string, format string, games int) error {
-	// Parse strategies list
-	list := []string{}
-	for _, s := range strings.Split(strategiesCSV, ",") {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			continue
+	// Print progress events while the tournament runs (or feed them to the dashboard)
+	// and pass them on to the metrics endpoint
+	events := make(chan tournament.Event, 16)
+	tcfg.Progress = events
+	printerDone := make(chan struct{})
+	if cfg.Dashboard != nil {
+		cfg.Dashboard.StartTournament(tcfg.Participants, games)
+	}
+	if cfg.Metrics != nil {
+		cfg.Metrics.SetGamesPerMatchup(games)
+	}
+	go func() {
+		defer close(printerDone)
+		for e := range events {
+			if cfg.Metrics != nil {
+				cfg.Metrics.Event(e)
+			}
+			if cfg.Dashboard != nil {
+				cfg.Dashboard.Event(e)
+			} else {
+				printTournamentEvent(e, len(tcfg.Participants), games)
+			}
 		}
-		list = append(list, s)
+	}()
+
+	ctx := cfg.Context
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	if len(list) < 2 {
-		return fmt.Errorf("need at least two strategies for a tournament")
+	results, runErr := tournament.Run(ctx, tcfg)
+	close(events)
+	<-printerDone
+	if cfg.Dashboard != nil {
+		cfg.Dashboard.Stop()
+	}
+	interrupted := errors.Is(runErr, context.Canceled) && results != nil
+	if runErr != nil && !interrupted {
+		return runErr
+	}
+	if interrupted {
+		fmt.Fprintf(console, "\n⚠️  Tournament interrupted after %d/%d matchups; exporting partial results (resume with --resume %s)\n",
+			len(results.Series), len(results.Matches), cfg.Exportpath)
 	}
 
-	// Validate all strategies upfront
-	fmt.Println("Validating strategies...")
-	for _, strat := range list {
-		if err := strategy.ValidateStrategy(strat); err != nil {
-			return fmt.Errorf("❌ %v", err)
-		}
-		fmt.Printf("  ✓ %s\n", strat)
+	// Build and print CLI matrix
+	printTournamentMatrix(results.Participants, results.Series)
+	if sampling.Adaptive() {
+		printTournamentPrecision(results.Series)
 	}
 
-	var matches []MatchSpec
-	switch format {
-	case "roundrobin":
-		matches = RoundRobinSchedule(list)
-	default:
-		return fmt.Errorf("unsupported tournament format: %s", format)
+	// Export results
+	resdir, err := analysis.CreateResultsDirectoryAt(cfg.Exportpath)
+	if err != nil {
+		return err
 	}
 
-	fmt.Printf("Running tournament with %d strategies, %d matchups, %d games each...\n", len(list), len(matches), games)
+	if err := analysis.ExportTournamentSummary(resdir, results.Matches, results.Series, results.Standings, tcfg.Rules, interrupted); err != nil {
+		return err
+	}
 
-	// Run all matchups and collect results
-	matchResults := make([]MatchResult, len(matches))
+	if interrupted {
+		return runErr
+	}
+	fmt.Fprintf(console, "\n✅ Tournament finished. Results exported to: %s\n", resdir)
+	return nil
+}
 
-	for i, m := range matches {
-		fmt.Printf("\nMatchup %d/%d: %s vs %s\n", i+1, len(matches), m.Team1Strategy, m.Team2Strategy)
+// printTournamentEvent prints a tournament progress event for the CLI
+func printTournamentEvent(e tournament.Event, participants int, games int) {
+	switch e.Type {
+	case tournament.EventTournamentStart:
+		fmt.Fprintf(console, "Running tournament with %d strategies, %d matchups, %d games each...\n", participants, e.TotalMatchup, games)
+	case tournament.EventMatchupStart:
+		fmt.Fprintf(console, "\nMatchup %d/%d: %s vs %s\n", e.Matchup+1, e.TotalMatchup, e.Match.Team1Strategy, e.Match.Team2Strategy)
+		if e.Resumed {
+			fmt.Fprintln(console, "  Resuming from checkpoint")
+		}
+	case tournament.EventMatchupDone:
+		fmt.Fprintf(console, "  Result: %s won %d, %s won %d (round diff %+d, OT rate %.1f%%)\n",
+			e.Match.Team1Strategy, e.Summary.Team1Wins,
+			e.Match.Team2Strategy, e.Summary.Team2Wins,
+			e.Summary.RoundDiff, e.Summary.OvertimeRate*100)
+		if e.Summary.Draws > 0 {
+			fmt.Fprintf(console, "  Draws: %d\n", e.Summary.Draws)
+		}
+	case tournament.EventMatchupTopUp:
+		fmt.Fprintf(console, "  Extra games for matchup %d (%s vs %s): now %d games, %s win rate %.2f%%\n",
+			e.Matchup+1, e.Match.Team1Strategy, e.Match.Team2Strategy,
+			e.Summary.Games, e.Match.Team1Strategy, e.Summary.Team1WinRate*100)
+	}
+}
 
-		// Create a unique folder for this matchup to avoid CSV file conflicts
-		matchupFolder := fmt.Sprintf("%s/matchup_%03d_%s_vs_%s", cfg.Exportpath, i+1, m.Team1Strategy, m.Team2Strategy)
-		if err := os.MkdirAll(matchupFolder, 0755); err != nil {
-			return fmt.Errorf("failed to create matchup folder: %v", err)
+// printTournamentPrecision prints the achieved precision per matchup (adaptive sampling)
+func printTournamentPrecision(series []tournament.SeriesResult) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "Achieved precision (Team1 win rate):")
+	for _, ser := range series {
+		p := ser.Precision
+		if p == nil {
+			continue
 		}
+		decision := ""
+		if p.Decision != "" {
+			decision = ", decision " + p.Decision
+		}
+		fmt.Fprintf(console, "  %s vs %s: %d games, %.2f%% [%.2f%%, %.2f%%] ±%.2f%% (stop: %s%s)\n",
+			ser.Match.Team1Strategy, ser.Match.Team2Strategy, p.Games,
+			p.WinRate*100, p.CILow*100, p.CIHigh*100, p.HalfWidth*100, p.StopReason, decision)
+	}
+}
 
-		// Create a simulation config for this matchup
-		matchConfig := SimulationConfig{
-			NumSimulations:        games,
-			MaxConcurrent:         cfg.MaxConcurrent,
-			MemoryLimit:           cfg.MemoryLimit,
-			Team1Name:             m.Team1Strategy,
-			Team1Strategy:         m.Team1Strategy,
-			Team2Name:             m.Team2Strategy,
-			Team2Strategy:         m.Team2Strategy,
-			GameRules:             custom.GameRules,
-			ExportDetailedResults: false,
-			ExportRounds:          false,
-			Sequential:            cfg.Sequential,
-			SuppressOutput:        true,              // Suppress output during tournament
-			CSVExportMode:         cfg.CSVExportMode, // Use the tournament's CSV export mode
-			Exportpath:            matchupFolder,     // Each matchup gets its own folder
+// cliMatchupRunner runs a matchup through the CLI's simulation runners so that every
+// matchup gets its own folder with summary and CSV exports. Later batches of the same
+// matchup (adaptive sampling, checkpoints) append to its CSVs and update its summary.
+func cliMatchupRunner(cfg *SimulationConfig) tournament.MatchupRunner {
+	merged := make(map[int]*analysis.SimulationStats)
+	return func(ctx context.Context, index int, m tournament.MatchSpec, rules engine.GameRules, spec tournament.SeriesSpec) (tournament.SeriesResult, error) {
+		series := tournament.SeriesResult{
+			Match:       m,
+			GameResults: make([]tournament.GameOutcome, 0, spec.NumGames),
+		}
+
+		// Create a unique folder for this matchup to avoid CSV file conflicts
+		matchupFolder := fmt.Sprintf("%s/matchup_%03d_%s_vs_%s", cfg.Exportpath, index+1, m.Team1Strategy, m.Team2Strategy)
+		if err := os.MkdirAll(matchupFolder, 0755); err != nil {
+			return series, fmt.Errorf("failed to create matchup folder: %v", err)
 		}
 
-		// Run the simulations for this matchup
-		var stats *analysis.SimulationStats
-		var err error
 		if cfg.Sequential {
-			// For sequential, we need to capture stats differently
-			tempStats := analysis.NewStats(games, "sequential")
-			for g := 0; g < games; g++ {
-				simPrefix := fmt.Sprintf("tournament_%s_vs_%s_game_%d_", m.Team1Strategy, m.Team2Strategy, g)
+			for g := 0; g < spec.NumGames; g++ {
+				if err := ctx.Err(); err != nil {
+					return series, err
+				}
+				var seed int64
+				if !spec.RandomSeeds {
+					seed = spec.Seed + int64(spec.Offset+g)
+				}
+				simPrefix := fmt.Sprintf("tournament_%s_vs_%s_game_%d_", m.Team1Strategy, m.Team2Strategy, spec.Offset+g)
 				result, gameErr := StartGame_default(
+					m.Team1Name,
 					m.Team1Strategy,
-					m.Team1Strategy,
-					m.Team2Strategy,
+					m.Team2Name,
 					m.Team2Strategy,
-					custom.GameRules,
+					rules,
 					simPrefix,
 					false,
 					false,
 					cfg.CSVExportMode, // Use the tournament's CSV export mode
 					matchupFolder,     // Use matchup-specific folder
+					seed,
+					cfg.EventLog,
 				)
 				if gameErr != nil {
 					continue
 				}
-				updateglobalstats(tempStats, result)
-			}
-			stats = tempStats
-		} else {
-			// Run concurrent simulations
-			stats, err = RunParallelSimulations(matchConfig)
-			if err != nil {
-				return fmt.Errorf("matchup %d/%d (%s vs %s) failed after running %d games: %w",
-					i+1, len(matches), m.Team1Strategy, m.Team2Strategy, games, err)
+				series.GameResults = append(series.GameResults, tournament.NewGameOutcome(result.Outcome, [2]int{result.Team1Score, result.Team2Score}, result.TotalRounds, result.OTCount, result.Seed))
 			}
+			return series, nil
 		}
 
-		matchResults[i] = MatchResult{
-			Team1Wins: int(stats.Team1Wins),
-			Team2Wins: int(stats.Team2Wins),
+		// RunParallelSimulations overwrites the summary; a resumed run continues from the
+		// summary the earlier batches left on disk
+		summaryPath := filepath.Join(matchupFolder, "simulation_summary.json")
+		if _, ok := merged[index]; !ok && spec.Offset > 0 {
+			if prev, err := analysis.LoadSummary(summaryPath); err == nil {
+				merged[index] = prev
+			}
 		}
 
-		fmt.Printf("  Result: %s won %d, %s won %d\n",
-			m.Team1Strategy, matchResults[i].Team1Wins,
-			m.Team2Strategy, matchResults[i].Team2Wins)
-	}
-
-	// Compute standings
-	standings := computeStandings(list, matches, matchResults)
-
-	// Build and print CLI matrix
-	printTournamentMatrix(list, matches, matchResults)
-
-	// Export results
-	resdir, err := analysis.CreateResultsDirectoryAt(cfg.Exportpath)
-	if err != nil {
-		return err
-	}
+		// Stream per-game outcomes out of the concurrent runner while it is running
+		stream := make(chan SimulationResult, cfg.MaxConcurrent*2)
+		collected := make(chan struct{})
+		go func() {
+			defer close(collected)
+			for r := range stream {
+				series.GameResults = append(series.GameResults, tournament.NewGameOutcome(r.Outcome, [2]int{r.Team1Score, r.Team2Score}, r.TotalRounds, r.OTCount, r.Seed))
+			}
+		}()
 
-	// Convert to tournament package types for export compatibility
-	tournamentMatches := make([]tournament.MatchSpec, len(matches))
-	for i, m := range matches {
-		tournamentMatches[i] = tournament.MatchSpec{
-			Team1Name:     m.Team1Strategy,
-			Team1Strategy: m.Team1Strategy,
-			Team2Name:     m.Team2Strategy,
-			Team2Strategy: m.Team2Strategy,
+		// Create a simulation config for this matchup
+		matchConfig := SimulationConfig{
+			NumSimulations:        spec.NumGames,
+			MaxConcurrent:         cfg.MaxConcurrent,
+			MemoryLimit:           cfg.MemoryLimit,
+			Team1Name:             m.Team1Name,
+			Team1Strategy:         m.Team1Strategy,
+			Team2Name:             m.Team2Name,
+			Team2Strategy:         m.Team2Strategy,
+			GameRules:             rules,
+			ExportDetailedResults: false,
+			ExportRounds:          false,
+			Sequential:            false,
+			SuppressOutput:        true,              // Suppress output during tournament
+			CSVExportMode:         cfg.CSVExportMode, // Use the tournament's CSV export mode
+			JSONLExport:           cfg.JSONLExport,
+			RoundTable:            cfg.RoundTable,
+			ExportFormat:          cfg.ExportFormat,
+			Store:                 cfg.Store,
+			RunID:                 cfg.RunID,
+			MatchupIndex:          index,
+			EventLog:              cfg.EventLog,
+			Dashboard:             cfg.Dashboard,
+			Metrics:               cfg.Metrics,
+			Exportpath:            matchupFolder, // Each matchup gets its own folder
+			BaseSeed:              spec.Seed,
+			SimOffset:             spec.Offset,
+			AppendCSV:             spec.Offset > 0,
+			ResultStream:          stream,
+			Context:               ctx,
 		}
-	}
 
-	seriesResults := make([]tournament.SeriesResult, len(matches))
-	for i := range matches {
-		seriesResults[i] = tournament.SeriesResult{
-			Match: tournamentMatches[i],
+		stats, err := RunParallelSimulations(matchConfig)
+		close(stream)
+		<-collected
+		if err != nil {
+			return series, fmt.Errorf("failed after running %d games: %w", spec.NumGames, err)
 		}
-		// Add game results
-		for g := 0; g < matchResults[i].Team1Wins; g++ {
-			seriesResults[i].GameResults = append(seriesResults[i].GameResults, tournament.GameOutcome{
-				T1Wins: true,
-				Score:  [2]int{16, 0}, // Placeholder scores
-			})
-		}
-		for g := 0; g < matchResults[i].Team2Wins; g++ {
-			seriesResults[i].GameResults = append(seriesResults[i].GameResults, tournament.GameOutcome{
-				T1Wins: false,
-				Score:  [2]int{0, 16}, // Placeholder scores
-			})
-		}
-	}
 
-	if err := analysis.ExportTournamentSummary(resdir, tournamentMatches, seriesResults, standings); err != nil {
-		return err
-	}
-
-	fmt.Printf("\n✅ Tournament finished. Results exported to: %s\n", resdir)
-	return nil
-}
-
-func computeStandings(strategies []string, matches []MatchSpec, results []MatchResult) tournament.Standings {
-	idx := make(map[string]int)
-	rows := make([]tournament.StandingsRow, len(strategies))
-	for i, s := range strategies {
-		idx[s] = i
-		rows[i] = tournament.StandingsRow{Strategy: s}
-	}
-
-	for i, m := range matches {
-		i1 := idx[m.Team1Strategy]
-		i2 := idx[m.Team2Strategy]
-
-		rows[i1].MapWins += results[i].Team1Wins
-		rows[i1].MapLoss += results[i].Team2Wins
-		rows[i2].MapWins += results[i].Team2Wins
-		rows[i2].MapLoss += results[i].Team1Wins
-
-		if results[i].Team1Wins > results[i].Team2Wins {
-			rows[i1].Wins++
-			rows[i2].Losses++
-		} else {
-			rows[i2].Wins++
-			rows[i1].Losses++
+		// Keep the matchup summary covering all of its batches
+		prev, ok := merged[index]
+		if !ok || spec.Offset == 0 {
+			merged[index] = stats
+			return series, nil
+		}
+		prev.Merge(stats)
+		if err := exportSummary(prev, summaryPath); err != nil {
+			fmt.Fprintf(console, "Warning: Failed to update summary for matchup %d: %v\n", index+1, err)
 		}
+		return series, nil
 	}
-
-	return tournament.Standings{Rows: rows}
 }
 
-func printTournamentMatrix(strategies []string, matches []MatchSpec, results []MatchResult) {
+func printTournamentMatrix(strategies []string, series []tournament.SeriesResult) {
 	n := len(strategies)
 	idx := make(map[string]int, n)
 	for i, name := range strategies {
@@ -243,15 +312,15 @@ func printTournamentMatrix(strategies []string, matches []MatchSpec, results []M
 		cells[i] = make([]string, n)
 	}
 
-	for i, m := range matches {
-		i1 := idx[m.Team1Strategy]
-		i2 := idx[m.Team2Strategy]
-		totalGames := results[i].Team1Wins + results[i].Team2Wins
+	for _, ser := range series {
+		i1 := idx[ser.Match.Team1Strategy]
+		i2 := idx[ser.Match.Team2Strategy]
+		sum := ser.Summarize()
 		// Populate both directions of the matchup
-		totals[i1][i2] += totalGames
-		totals[i2][i1] += totalGames
-		wins[i1][i2] += results[i].Team1Wins
-		wins[i2][i1] += results[i].Team2Wins
+		totals[i1][i2] += sum.Games
+		totals[i2][i1] += sum.Games
+		wins[i1][i2] += sum.Team1Wins
+		wins[i2][i1] += sum.Team2Wins
 	}
 
 	// Precompute cell strings and column widths
@@ -289,19 +358,19 @@ func printTournamentMatrix(strategies []string, matches []MatchSpec, results []M
 	}
 
 	// Print header
-	fmt.Println()
-	fmt.Println("Tournament win-rate matrix:")
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "Tournament win-rate matrix:")
 	for j := 0; j <= n; j++ {
-		fmt.Printf("%-*s ", colW[j], headers[j])
+		fmt.Fprintf(console, "%-*s ", colW[j], headers[j])
 	}
-	fmt.Println()
+	fmt.Fprintln(console)
 
 	// Print rows
 	for i := 0; i < n; i++ {
-		fmt.Printf("%-*s ", colW[0], strategies[i])
+		fmt.Fprintf(console, "%-*s ", colW[0], strategies[i])
 		for j := 0; j < n; j++ {
-			fmt.Printf("%-*s ", colW[j+1], cells[i][j])
+			fmt.Fprintf(console, "%-*s ", colW[j+1], cells[i][j])
 		}
-		fmt.Println()
+		fmt.Fprintln(console)
 	}
 }
@@ -0,0 +1,441 @@
+package analysis
+
+import (
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/strategy"
+	"dbg_abm/internal/tournament"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// aggregateConfidence is the confidence level of the win rate intervals
+const aggregateConfidence = 0.95
+
+// Aggregates are distributions of a run that are updated game by game, so no game has to
+// be kept until the end. They round-trip through JSON and merge across batches.
+type Aggregates struct {
+	Confidence      float64          `json:"confidence"`
+	ScoreLineCounts map[string]int64 `json:"score_line_counts"` // "16-10" (Team1-Team2) -> games
+	ScoreLines      []ScoreLine      `json:"score_lines"`       // Derived from ScoreLineCounts, most frequent first
+	RoundCounts     map[int]int64    `json:"round_counts"`      // Rounds played -> games
+	Team1           *TeamAggregates  `json:"team1"`
+	Team2           *TeamAggregates  `json:"team2"`
+
+	// Rounds and team 1 round wins per buy type pairing [team1 buy type][team2 buy type],
+	// rows and columns in the order of BuyTypes
+	BuyTypes         []string         `json:"buy_types"`
+	PairingRounds    [][]int64        `json:"buy_type_pairing_rounds"`
+	PairingTeam1Wins [][]int64        `json:"buy_type_pairing_team1_wins"`
+	Pairings         []BuyTypePairing `json:"buy_type_pairings"` // Derived, pairings that occurred
+}
+
+// BuyTypePairing is the round win rate of team 1 when both teams chose the given buy types
+type BuyTypePairing struct {
+	Team1BuyType string  `json:"team1_buy_type"`
+	Team2BuyType string  `json:"team2_buy_type"`
+	Rounds       int64   `json:"rounds"`
+	Team1Wins    int64   `json:"team1_wins"`
+	Team1WinRate float64 `json:"team1_win_rate"`
+}
+
+// TeamAggregates are the streaming statistics of one team
+type TeamAggregates struct {
+	// Game win rate intervals in percent, like Team1WinRate/Team2WinRate
+	WinRateWilson         Interval `json:"win_rate_ci_wilson"`
+	WinRateClopperPearson Interval `json:"win_rate_ci_clopper_pearson"`
+
+	// Rounds played and won per side
+	CTRounds       int64   `json:"ct_rounds"`
+	CTRoundWins    int64   `json:"ct_round_wins"`
+	TRounds        int64   `json:"t_rounds"`
+	TRoundWins     int64   `json:"t_round_wins"`
+	CTRoundWinRate float64 `json:"ct_round_win_rate"`
+	TRoundWinRate  float64 `json:"t_round_win_rate"`
+
+	// Per-round values: funds at round start, money spent, equipment at freeze time end
+	Funds     *TDigest `json:"funds_digest"`
+	Spent     *TDigest `json:"spent_digest"`
+	Equipment *TDigest `json:"equipment_digest"`
+
+	FundsQuantiles     Quantiles `json:"funds"`
+	SpentQuantiles     Quantiles `json:"spent"`
+	EquipmentQuantiles Quantiles `json:"equipment"`
+
+	// Buy types (order of Aggregates.BuyTypes): rounds per buy type and transitions from the
+	// buy type of round N [from] to round N+1 [to]. Transitions across halftime and overtime
+	// resets, where the funds do not carry over, are not counted.
+	BuyTypeCounts       []int64     `json:"buy_type_counts"`
+	BuyTypeTransitions  [][]int64   `json:"buy_type_transitions"`
+	BuyTypeTransitionPs [][]float64 `json:"buy_type_transition_probabilities"` // Derived, rows sum to 1
+}
+
+// Interval is a confidence interval
+type Interval struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Quantiles summarizes a digest
+type Quantiles struct {
+	Min  float64 `json:"min"`
+	P05  float64 `json:"p05"`
+	P25  float64 `json:"p25"`
+	P50  float64 `json:"p50"`
+	P75  float64 `json:"p75"`
+	P95  float64 `json:"p95"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+}
+
+// NewAggregates creates empty aggregates
+func NewAggregates() *Aggregates {
+	return &Aggregates{
+		Confidence:       aggregateConfidence,
+		ScoreLineCounts:  make(map[string]int64),
+		RoundCounts:      make(map[int]int64),
+		Team1:            newTeamAggregates(),
+		Team2:            newTeamAggregates(),
+		BuyTypes:         strategy.BuyTypes,
+		PairingRounds:    newMatrix(),
+		PairingTeam1Wins: newMatrix(),
+	}
+}
+
+// newMatrix returns a zero buy type x buy type matrix
+func newMatrix() [][]int64 {
+	m := make([][]int64, len(strategy.BuyTypes))
+	for i := range m {
+		m[i] = make([]int64, len(strategy.BuyTypes))
+	}
+	return m
+}
+
+// buyTypeIndex returns the position of a buy type in strategy.BuyTypes (-1 if unknown)
+func buyTypeIndex(buyType string) int {
+	for i, name := range strategy.BuyTypes {
+		if name == buyType {
+			return i
+		}
+	}
+	return -1
+}
+
+// addMatrix adds src to dst cell by cell
+func addMatrix(dst, src [][]int64) {
+	for i := range src {
+		for j := range src[i] {
+			if i < len(dst) && j < len(dst[i]) {
+				dst[i][j] += src[i][j]
+			}
+		}
+	}
+}
+
+func newTeamAggregates() *TeamAggregates {
+	return &TeamAggregates{
+		Funds:              NewTDigest(0),
+		Spent:              NewTDigest(0),
+		Equipment:          NewTDigest(0),
+		BuyTypeCounts:      make([]int64, len(strategy.BuyTypes)),
+		BuyTypeTransitions: newMatrix(),
+	}
+}
+
+// UpdateAggregates adds a finished game to the aggregates (thread-safe). Games without
+// data (e.g. run on a remote worker) only count towards the game counters.
+func (s *SimulationStats) UpdateAggregates(game *engine.Game) {
+	if game == nil || s.Aggregates == nil {
+		return
+	}
+	s.ScoreMutex.Lock()
+	defer s.ScoreMutex.Unlock()
+	s.Aggregates.addGame(game)
+}
+
+func (a *Aggregates) addGame(game *engine.Game) {
+	a.ScoreLineCounts[fmt.Sprintf("%d-%d", game.Score[0], game.Score[1])]++
+	a.RoundCounts[len(game.Rounds)]++
+
+	t1, t2 := game.Team1.RoundData, game.Team2.RoundData
+	for i := range game.Rounds {
+		if i >= len(t1) || i >= len(t2) {
+			break
+		}
+		round := &game.Rounds[i]
+		a.Team1.addRound(t1[i], round.IsT1CT, round.IsT1WinnerTeam)
+		a.Team2.addRound(t2[i], !round.IsT1CT, !round.IsT1WinnerTeam)
+		if i > 0 {
+			a.Team1.addTransition(t1[i-1], t1[i])
+			a.Team2.addTransition(t2[i-1], t2[i])
+		}
+
+		b1, b2 := buyTypeIndex(t1[i].BuyType), buyTypeIndex(t2[i].BuyType)
+		if b1 >= 0 && b2 >= 0 {
+			a.PairingRounds[b1][b2]++
+			if round.IsT1WinnerTeam {
+				a.PairingTeam1Wins[b1][b2]++
+			}
+		}
+	}
+}
+
+func (t *TeamAggregates) addRound(rd engine.Team_RoundData, ct, won bool) {
+	if ct {
+		t.CTRounds++
+		if won {
+			t.CTRoundWins++
+		}
+	} else {
+		t.TRounds++
+		if won {
+			t.TRoundWins++
+		}
+	}
+	t.Funds.Add(rd.Funds_start)
+	t.Spent.Add(rd.Spent)
+	t.Equipment.Add(rd.FTE_Eq_value)
+	if b := buyTypeIndex(rd.BuyType); b >= 0 {
+		t.BuyTypeCounts[b]++
+	}
+}
+
+func (t *TeamAggregates) addTransition(prev, next engine.Team_RoundData) {
+	if next.Funds_start != prev.Funds {
+		return // Funds were reset (halftime, overtime)
+	}
+	from, to := buyTypeIndex(prev.BuyType), buyTypeIndex(next.BuyType)
+	if from >= 0 && to >= 0 {
+		t.BuyTypeTransitions[from][to]++
+	}
+}
+
+// merge adds the aggregates of other (a later batch of the same matchup)
+func (a *Aggregates) merge(other *Aggregates) {
+	if a.ScoreLineCounts == nil {
+		a.ScoreLineCounts = make(map[string]int64)
+	}
+	if a.RoundCounts == nil {
+		a.RoundCounts = make(map[int]int64)
+	}
+	if a.PairingRounds == nil || a.PairingTeam1Wins == nil {
+		a.BuyTypes, a.PairingRounds, a.PairingTeam1Wins = strategy.BuyTypes, newMatrix(), newMatrix()
+	}
+	addMatrix(a.PairingRounds, other.PairingRounds)
+	addMatrix(a.PairingTeam1Wins, other.PairingTeam1Wins)
+	for line, n := range other.ScoreLineCounts {
+		a.ScoreLineCounts[line] += n
+	}
+	for rounds, n := range other.RoundCounts {
+		a.RoundCounts[rounds] += n
+	}
+	a.Team1.merge(other.Team1)
+	a.Team2.merge(other.Team2)
+}
+
+func (t *TeamAggregates) merge(other *TeamAggregates) {
+	if other == nil {
+		return
+	}
+	if t.Funds == nil || t.Spent == nil || t.Equipment == nil {
+		fresh := newTeamAggregates()
+		t.Funds, t.Spent, t.Equipment = fresh.Funds, fresh.Spent, fresh.Equipment
+	}
+	t.CTRounds += other.CTRounds
+	t.CTRoundWins += other.CTRoundWins
+	t.TRounds += other.TRounds
+	t.TRoundWins += other.TRoundWins
+	t.Funds.Merge(other.Funds)
+	t.Spent.Merge(other.Spent)
+	t.Equipment.Merge(other.Equipment)
+	if t.BuyTypeCounts == nil || t.BuyTypeTransitions == nil {
+		t.BuyTypeCounts, t.BuyTypeTransitions = make([]int64, len(strategy.BuyTypes)), newMatrix()
+	}
+	for i := range other.BuyTypeCounts {
+		if i < len(t.BuyTypeCounts) {
+			t.BuyTypeCounts[i] += other.BuyTypeCounts[i]
+		}
+	}
+	addMatrix(t.BuyTypeTransitions, other.BuyTypeTransitions)
+}
+
+// calculate computes the derived fields; wins and games are the team's game wins and the
+// completed games of the run
+func (a *Aggregates) calculate(team1Wins, team2Wins, games int64) {
+	a.Confidence = aggregateConfidence
+	a.Team1.calculate(team1Wins, games, a.Confidence)
+	a.Team2.calculate(team2Wins, games, a.Confidence)
+
+	a.ScoreLines = a.ScoreLines[:0]
+	for line, n := range a.ScoreLineCounts {
+		freq := 0.0
+		if games > 0 {
+			freq = float64(n) / float64(games) * 100
+		}
+		a.ScoreLines = append(a.ScoreLines, ScoreLine{Score: line, Count: n, Frequency: freq})
+	}
+	sort.Slice(a.ScoreLines, func(i, j int) bool {
+		if a.ScoreLines[i].Count != a.ScoreLines[j].Count {
+			return a.ScoreLines[i].Count > a.ScoreLines[j].Count
+		}
+		return a.ScoreLines[i].Score < a.ScoreLines[j].Score
+	})
+
+	a.Pairings = a.Pairings[:0]
+	for i, row := range a.PairingRounds {
+		for j, n := range row {
+			if n == 0 || i >= len(a.BuyTypes) || j >= len(a.BuyTypes) {
+				continue
+			}
+			wins := a.PairingTeam1Wins[i][j]
+			a.Pairings = append(a.Pairings, BuyTypePairing{
+				Team1BuyType: a.BuyTypes[i],
+				Team2BuyType: a.BuyTypes[j],
+				Rounds:       n,
+				Team1Wins:    wins,
+				Team1WinRate: float64(wins) / float64(n) * 100,
+			})
+		}
+	}
+}
+
+func (t *TeamAggregates) calculate(wins, games int64, confidence float64) {
+	low, high := tournament.WilsonInterval(int(wins), int(games), confidence)
+	t.WinRateWilson = Interval{Low: low * 100, High: high * 100}
+	low, high = ClopperPearsonInterval(int(wins), int(games), confidence)
+	t.WinRateClopperPearson = Interval{Low: low * 100, High: high * 100}
+
+	t.CTRoundWinRate, t.TRoundWinRate = 0, 0
+	if t.CTRounds > 0 {
+		t.CTRoundWinRate = float64(t.CTRoundWins) / float64(t.CTRounds) * 100
+	}
+	if t.TRounds > 0 {
+		t.TRoundWinRate = float64(t.TRoundWins) / float64(t.TRounds) * 100
+	}
+
+	t.FundsQuantiles = quantilesOf(t.Funds)
+	t.SpentQuantiles = quantilesOf(t.Spent)
+	t.EquipmentQuantiles = quantilesOf(t.Equipment)
+
+	t.BuyTypeTransitionPs = make([][]float64, len(t.BuyTypeTransitions))
+	for i, row := range t.BuyTypeTransitions {
+		var total int64
+		for _, n := range row {
+			total += n
+		}
+		t.BuyTypeTransitionPs[i] = make([]float64, len(row))
+		for j, n := range row {
+			if total > 0 {
+				t.BuyTypeTransitionPs[i][j] = float64(n) / float64(total)
+			}
+		}
+	}
+}
+
+func quantilesOf(d *TDigest) Quantiles {
+	if d == nil || d.Count == 0 {
+		return Quantiles{}
+	}
+	return Quantiles{
+		Min:  d.Min,
+		P05:  d.Quantile(0.05),
+		P25:  d.Quantile(0.25),
+		P50:  d.Quantile(0.5),
+		P75:  d.Quantile(0.75),
+		P95:  d.Quantile(0.95),
+		Max:  d.Max,
+		Mean: d.Mean(),
+	}
+}
+
+// ClopperPearsonInterval returns the exact (Clopper-Pearson) interval for wins successes
+// out of n trials. It is more conservative than the Wilson interval.
+func ClopperPearsonInterval(wins, n int, confidence float64) (float64, float64) {
+	if n <= 0 {
+		return 0, 1
+	}
+	alpha := 1 - confidence
+	low, high := 0.0, 1.0
+	if wins > 0 {
+		low = betaQuantile(alpha/2, float64(wins), float64(n-wins+1))
+	}
+	if wins < n {
+		high = betaQuantile(1-alpha/2, float64(wins+1), float64(n-wins))
+	}
+	return low, high
+}
+
+// betaQuantile inverts the regularized incomplete beta function by bisection
+func betaQuantile(p, a, b float64) float64 {
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 100 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if regularizedBeta(mid, a, b) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// regularizedBeta returns I_x(a, b), evaluated with the continued fraction of
+// Numerical Recipes (betacf) on the side where it converges quickly
+func regularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	qab, qap, qam := a+b, a+1, a-1
+	c, d := 1.0, 1-qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= 300; m++ {
+		mf := float64(m)
+		m2 := 2 * mf
+		aa := mf * (b - mf) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		aa = -(a + mf) * (qab + mf) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < 3e-14 {
+			break
+		}
+	}
+	return h
+}
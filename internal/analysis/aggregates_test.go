@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClopperPearsonInterval(t *testing.T) {
+	// Reference values from inverting the exact binomial CDF (as R's binom.test)
+	tests := []struct {
+		wins, n    int
+		confidence float64
+		low, high  float64
+	}{
+		{0, 10, 0.95, 0, 0.3084971078187607},
+		{10, 10, 0.95, 0.6915028921812392, 1},
+		{1, 10, 0.95, 0.0025285785444618125, 0.4450161170281953},
+		{5, 10, 0.95, 0.18708602844739858, 0.8129139715526015},
+		{3, 7, 0.95, 0.09898827844250785, 0.8159484323599169},
+		{50, 100, 0.95, 0.398321129503301, 0.6016788704966991},
+		{1, 1000, 0.95, 2.5317487491294255e-05, 0.0055589242798266825},
+		{0, 1, 0.95, 0, 0.975},
+		{5, 10, 0.99, 0.12831055393508278, 0.8716894460649167},
+		{0, 0, 0.95, 0, 1}, // No games: the whole range
+	}
+	for _, tt := range tests {
+		low, high := ClopperPearsonInterval(tt.wins, tt.n, tt.confidence)
+		if math.Abs(low-tt.low) > 1e-8 || math.Abs(high-tt.high) > 1e-8 {
+			t.Errorf("ClopperPearsonInterval(%d, %d, %g) = [%.10f, %.10f], want [%.10f, %.10f]",
+				tt.wins, tt.n, tt.confidence, low, high, tt.low, tt.high)
+		}
+	}
+}
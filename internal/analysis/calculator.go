@@ -1,16 +1,27 @@
 package analysis
 
 import (
+	"dbg_abm/internal/engine"
 	"sync/atomic"
 	"time"
 )
 
-// UpdateGameResult updates statistics with a single game result (thread-safe)
-func (s *SimulationStats) UpdateGameResult(team1Won bool, team1Score, team2Score, totalRounds int, wentToOvertime bool, responseTime time.Duration) {
+// UpdateGameResult updates statistics with a single game result (thread-safe).
+// A draw counts for neither team.
+func (s *SimulationStats) UpdateGameResult(outcome engine.Outcome, team1Score, team2Score, totalRounds int, wentToOvertime bool, responseTime time.Duration) {
 	// Update core stats atomically for thread safety
 	atomic.AddInt64(&s.CompletedSims, 1)
 	atomic.AddInt64(&s.TotalRounds, int64(totalRounds))
 
+	if wentToOvertime {
+		atomic.AddInt64(&s.OvertimeGames, 1)
+	}
+	if outcome.IsDraw() {
+		atomic.AddInt64(&s.Draws, 1)
+		return
+	}
+	team1Won := outcome.Team1Won()
+
 	if team1Won {
 		atomic.AddInt64(&s.Team1Wins, 1)
 	} else {
@@ -18,7 +29,6 @@ func (s *SimulationStats) UpdateGameResult(team1Won bool, team1Score, team2Score
 	}
 
 	if wentToOvertime {
-		atomic.AddInt64(&s.OvertimeGames, 1)
 		if team1Won {
 			atomic.AddInt64(&s.Team1OTWins, 1)
 		} else {
@@ -39,6 +49,37 @@ func (s *SimulationStats) UpdateFailedSimulation() {
 	s.FailedSims++
 }
 
+// Merge adds the counters of other (e.g. a later batch of the same matchup) to s
+// and recomputes the derived statistics
+func (s *SimulationStats) Merge(other *SimulationStats) {
+	s.TotalSimulations += other.TotalSimulations
+	s.CompletedSims += other.CompletedSims
+	s.FailedSims += other.FailedSims
+	s.Team1Wins += other.Team1Wins
+	s.Team2Wins += other.Team2Wins
+	s.Draws += other.Draws
+	s.TotalRounds += other.TotalRounds
+	s.OvertimeGames += other.OvertimeGames
+	s.Team1OTWins += other.Team1OTWins
+	s.Team2OTWins += other.Team2OTWins
+	s.Team1RTWins += other.Team1RTWins
+	s.Team2RTWins += other.Team2RTWins
+	s.ExecutionTime += other.ExecutionTime
+	s.TotalGCRuns += other.TotalGCRuns
+	if other.PeakMemoryUsage > s.PeakMemoryUsage {
+		s.PeakMemoryUsage = other.PeakMemoryUsage
+	}
+	if s.Aggregates != nil && other.Aggregates != nil {
+		s.Aggregates.merge(other.Aggregates)
+	} else {
+		s.Aggregates = nil // Would no longer cover all games (e.g. stats of an older manifest)
+	}
+	if s.Config != nil {
+		s.Config.NumSimulations = int(s.TotalSimulations)
+	}
+	s.CalculateFinalStats()
+}
+
 // TeamGameEconomics holds economic statistics for a single game
 type TeamGameEconomics struct {
 	TotalSpent       float64
@@ -58,6 +99,7 @@ func (s *SimulationStats) CalculateFinalStats() {
 	if s.CompletedSims > 0 {
 		s.Team1WinRate = float64(s.Team1Wins) / float64(s.CompletedSims) * 100
 		s.Team2WinRate = float64(s.Team2Wins) / float64(s.CompletedSims) * 100
+		s.DrawRate = float64(s.Draws) / float64(s.CompletedSims) * 100
 
 		if s.OvertimeGames > 0 {
 			s.Team1OTWinRate = float64(s.Team1OTWins) / float64(s.OvertimeGames) * 100
@@ -82,5 +124,11 @@ func (s *SimulationStats) CalculateFinalStats() {
 			s.ProcessingRate = float64(s.CompletedSims) / s.ExecutionTime.Seconds()
 		}
 
+		if s.Aggregates != nil {
+			s.ScoreMutex.Lock()
+			s.Aggregates.calculate(s.Team1Wins, s.Team2Wins, s.CompletedSims)
+			s.ScoreMutex.Unlock()
+		}
+
 	}
 }
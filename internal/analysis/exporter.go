@@ -96,3 +96,16 @@ func ExportAllFormats(stats *SimulationStats, baseDir string) error {
 
 	return nil
 }
+
+// LoadSummary reads a simulation_summary.json written by an earlier run
+func LoadSummary(filename string) (*SimulationStats, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	stats := &SimulationStats{}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to parse summary '%s': %w", filename, err)
+	}
+	return stats, nil
+}
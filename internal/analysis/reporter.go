@@ -2,59 +2,94 @@ package analysis
 
 import (
 	"fmt"
+	"io"
 	"strings"
 	"time"
 )
 
-// PrintEnhancedStats prints comprehensive statistics with enhanced analysis
-func PrintEnhancedStats(stats *SimulationStats) {
-	printBasicStats(stats)
-	printGameAnalysis(stats)
+// PrintEnhancedStats prints comprehensive statistics with enhanced analysis to w
+func PrintEnhancedStats(w io.Writer, stats *SimulationStats) {
+	printBasicStats(w, stats)
+	printGameAnalysis(w, stats)
 }
 
 // printBasicStats prints core simulation statistics
-func printBasicStats(stats *SimulationStats) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("🎮 SIMULATION RESULTS SUMMARY")
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Printf("Simulation Mode: %s\n", strings.Title(stats.SimulationMode))
-	fmt.Printf("Total Simulations: %d (Completed: %d, Failed: %d)\n",
+func printBasicStats(w io.Writer, stats *SimulationStats) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("=", 60))
+	fmt.Fprintln(w, "🎮 SIMULATION RESULTS SUMMARY")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	fmt.Fprintf(w, "Simulation Mode: %s\n", strings.Title(stats.SimulationMode))
+	fmt.Fprintf(w, "Total Simulations: %d (Completed: %d, Failed: %d)\n",
 		stats.TotalSimulations, stats.CompletedSims, stats.FailedSims)
-	fmt.Printf("Execution Time: %s\n", stats.ExecutionTime.Round(time.Second))
+	fmt.Fprintf(w, "Execution Time: %s\n", stats.ExecutionTime.Round(time.Second))
 	if stats.ProcessingRate > 0 {
-		fmt.Printf("Processing Rate: %.1f simulations/second\n", stats.ProcessingRate)
+		fmt.Fprintf(w, "Processing Rate: %.1f simulations/second\n", stats.ProcessingRate)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // printGameAnalysis prints game-specific analysis
-func printGameAnalysis(stats *SimulationStats) {
+func printGameAnalysis(w io.Writer, stats *SimulationStats) {
 	if stats.CompletedSims == 0 {
 		return
 	}
 
-	fmt.Println("📊 GAME ANALYSIS")
-	fmt.Println(strings.Repeat("-", 40))
+	fmt.Fprintln(w, "📊 GAME ANALYSIS")
+	fmt.Fprintln(w, strings.Repeat("-", 40))
 
 	// Team performance
-	fmt.Printf("Team 1 Wins: %d (%.1f%%) with strat (%s)\n", stats.Team1Wins, stats.Team1WinRate, stats.Config.Team1Strategy)
-	fmt.Printf("Team 2 Wins: %d (%.1f%%) with strat (%s)\n", stats.Team2Wins, stats.Team2WinRate, stats.Config.Team2Strategy)
-	fmt.Printf("Team 1 Regular Time Wins: %d (%.1f%%)\n", stats.Team1RTWins, stats.Team1RTWinRate)
-	fmt.Printf("Team 2 Regular Time Wins: %d (%.1f%%)\n", stats.Team2RTWins, stats.Team2RTWinRate)
-	fmt.Printf("Team 1 Overtime Wins: %d (%.1f%%)\n", stats.Team1OTWins, stats.Team1OTWinRate)
-	fmt.Printf("Team 2 Overtime Wins: %d (%.1f%%)\n", stats.Team2OTWins, stats.Team2OTWinRate)
+	fmt.Fprintf(w, "Team 1 Wins: %d (%.1f%%) with strat (%s)\n", stats.Team1Wins, stats.Team1WinRate, stats.Config.Team1Strategy)
+	fmt.Fprintf(w, "Team 2 Wins: %d (%.1f%%) with strat (%s)\n", stats.Team2Wins, stats.Team2WinRate, stats.Config.Team2Strategy)
+	if stats.Draws > 0 {
+		fmt.Fprintf(w, "Draws: %d (%.1f%%)\n", stats.Draws, stats.DrawRate)
+	}
+	fmt.Fprintf(w, "Team 1 Regular Time Wins: %d (%.1f%%)\n", stats.Team1RTWins, stats.Team1RTWinRate)
+	fmt.Fprintf(w, "Team 2 Regular Time Wins: %d (%.1f%%)\n", stats.Team2RTWins, stats.Team2RTWinRate)
+	fmt.Fprintf(w, "Team 1 Overtime Wins: %d (%.1f%%)\n", stats.Team1OTWins, stats.Team1OTWinRate)
+	fmt.Fprintf(w, "Team 2 Overtime Wins: %d (%.1f%%)\n", stats.Team2OTWins, stats.Team2OTWinRate)
 
 	// Game characteristics
-	fmt.Printf("Average Rounds per Game: %.1f\n", stats.AverageRounds)
-	fmt.Printf("Overtime Rate: %.1f%%\n", stats.OvertimeRate)
+	fmt.Fprintf(w, "Average Rounds per Game: %.1f\n", stats.AverageRounds)
+	fmt.Fprintf(w, "Overtime Rate: %.1f%%\n", stats.OvertimeRate)
+
+	fmt.Fprintln(w)
+	printAggregates(w, stats.Aggregates)
+}
+
+// printAggregates prints the win rate intervals, per-side round win rates and economy quantiles
+func printAggregates(w io.Writer, a *Aggregates) {
+	if a == nil || a.Team1 == nil || a.Team2 == nil {
+		return
+	}
 
-	fmt.Println()
+	fmt.Fprintln(w, "📈 DISTRIBUTIONS")
+	fmt.Fprintln(w, strings.Repeat("-", 40))
+	for i, t := range []*TeamAggregates{a.Team1, a.Team2} {
+		fmt.Fprintf(w, "Team %d Win Rate %.0f%% CI: Wilson [%.1f%%, %.1f%%], Clopper-Pearson [%.1f%%, %.1f%%]\n", i+1, a.Confidence*100,
+			t.WinRateWilson.Low, t.WinRateWilson.High, t.WinRateClopperPearson.Low, t.WinRateClopperPearson.High)
+		fmt.Fprintf(w, "Team %d Round Win Rate: CT %.1f%% (%d rounds), T %.1f%% (%d rounds)\n", i+1,
+			t.CTRoundWinRate, t.CTRounds, t.TRoundWinRate, t.TRounds)
+		fmt.Fprintf(w, "Team %d Funds p05/p50/p95: %.0f / %.0f / %.0f, Spent p50: %.0f, Equipment p50: %.0f\n", i+1,
+			t.FundsQuantiles.P05, t.FundsQuantiles.P50, t.FundsQuantiles.P95, t.SpentQuantiles.P50, t.EquipmentQuantiles.P50)
+	}
+	if n := len(a.ScoreLines); n > 0 {
+		top := a.ScoreLines
+		if n > 5 {
+			top = top[:5]
+		}
+		lines := make([]string, len(top))
+		for i, l := range top {
+			lines[i] = fmt.Sprintf("%s (%.1f%%)", l.Score, l.Frequency)
+		}
+		fmt.Fprintf(w, "Most Frequent Score Lines: %s\n", strings.Join(lines, ", "))
+	}
+	fmt.Fprintln(w)
 }
 
 // Advanced analysis removed
 
 // PrintProgressSummary prints a compact progress update (for live monitoring)
-func PrintProgressSummary(stats *SimulationStats) {
+func PrintProgressSummary(w io.Writer, stats *SimulationStats) {
 	if stats.CompletedSims == 0 {
 		return
 	}
@@ -62,12 +97,12 @@ func PrintProgressSummary(stats *SimulationStats) {
 	team1Rate := float64(stats.Team1Wins) / float64(stats.CompletedSims) * 100
 	progressPercent := float64(stats.CompletedSims) / float64(stats.TotalSimulations) * 100
 
-	fmt.Printf("\r🎮 Progress: %d/%d (%.1f%%) | Team1: %.1f%% | Team2: %.1f%% | Rate: %.0f/sec",
+	fmt.Fprintf(w, "\r🎮 Progress: %d/%d (%.1f%%) | Team1: %.1f%% | Team2: %.1f%% | Rate: %.0f/sec",
 		stats.CompletedSims, stats.TotalSimulations, progressPercent,
 		team1Rate, 100-team1Rate, stats.ProcessingRate)
 }
 
 // ClearProgressLine clears the progress line
-func ClearProgressLine() {
-	fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
+func ClearProgressLine(w io.Writer) {
+	fmt.Fprint(w, "\r"+strings.Repeat(" ", 80)+"\r")
 }
@@ -0,0 +1,307 @@
+package analysis
+
+import (
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/strategy"
+	"dbg_abm/util"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// RoundTableFile is the name of the per-round aggregate table in an export directory
+const RoundTableFile = "round_aggregates.csv"
+
+// RoundKey identifies a cell of the round table: a team in a round number, on a side,
+// at a score state (scores before the round)
+type RoundKey struct {
+	Team          int // 1 or 2
+	Strategy      string
+	RoundNumber   int
+	Side          string // CT or T
+	Score         int
+	OpponentScore int
+}
+
+// RoundCell aggregates the team-rounds of a key
+type RoundCell struct {
+	Rounds     int64
+	Wins       int64
+	Spent      float64 // Sums; the CSV holds the means
+	FTEEq      float64
+	FundsStart float64
+	BuyTypes   []int64 // Counts per strategy.BuyTypes
+}
+
+// RoundTable aggregates team-rounds by round number, side and score state as games finish,
+// so the economy of a run can be analysed without the full round export. It is a
+// util.ResultSink: Close writes it as CSV. Not safe for concurrent use.
+type RoundTable struct {
+	path  string
+	cells map[RoundKey]*RoundCell
+}
+
+// NewRoundTable creates an empty table that Close writes to path
+func NewRoundTable(path string) *RoundTable {
+	return &RoundTable{path: path, cells: make(map[RoundKey]*RoundCell)}
+}
+
+// WriteGame adds the rounds of both teams of a finished game
+func (t *RoundTable) WriteGame(game *engine.Game) error {
+	for i := range game.Rounds {
+		if i >= len(game.Team1.RoundData) || i >= len(game.Team2.RoundData) {
+			break
+		}
+		round := &game.Rounds[i]
+		t1, t2 := game.Team1.RoundData[i], game.Team2.RoundData[i]
+		t.addRound(1, game.Team1.Strategy, round.RoundNumber, round.IsT1CT, round.IsT1WinnerTeam, t1, t2.Score_Start)
+		t.addRound(2, game.Team2.Strategy, round.RoundNumber, !round.IsT1CT, !round.IsT1WinnerTeam, t2, t1.Score_Start)
+	}
+	return nil
+}
+
+func (t *RoundTable) addRound(team int, strat string, roundNumber int, ct, won bool, rd engine.Team_RoundData, opponentScore int) {
+	side := "T"
+	if ct {
+		side = "CT"
+	}
+	cell := t.cell(RoundKey{Team: team, Strategy: strat, RoundNumber: roundNumber, Side: side,
+		Score: rd.Score_Start, OpponentScore: opponentScore})
+	cell.Rounds++
+	if won {
+		cell.Wins++
+	}
+	cell.Spent += rd.Spent
+	cell.FTEEq += rd.FTE_Eq_value
+	cell.FundsStart += rd.Funds_start
+	for j, name := range strategy.BuyTypes {
+		if name == rd.BuyType {
+			cell.BuyTypes[j]++
+		}
+	}
+}
+
+func (t *RoundTable) cell(key RoundKey) *RoundCell {
+	cell, ok := t.cells[key]
+	if !ok {
+		cell = &RoundCell{BuyTypes: make([]int64, len(strategy.BuyTypes))}
+		t.cells[key] = cell
+	}
+	return cell
+}
+
+// Merge adds the cells of other to t
+func (t *RoundTable) Merge(other *RoundTable) {
+	for key, o := range other.cells {
+		cell := t.cell(key)
+		cell.Rounds += o.Rounds
+		cell.Wins += o.Wins
+		cell.Spent += o.Spent
+		cell.FTEEq += o.FTEEq
+		cell.FundsStart += o.FundsStart
+		for j := range cell.BuyTypes {
+			cell.BuyTypes[j] += o.BuyTypes[j]
+		}
+	}
+}
+
+// RoundMeans are the aggregates of one team in one round number over all sides and scores
+type RoundMeans struct {
+	RoundNumber int
+	Rounds      int64
+	WinRate     float64
+	Spent       float64
+	FTEEq       float64
+	FundsStart  float64
+}
+
+// MeansByRound returns the aggregates of team (1 or 2) per round number, in round order
+func (t *RoundTable) MeansByRound(team int) []RoundMeans {
+	byRound := make(map[int]*RoundCell)
+	for key, cell := range t.cells {
+		if key.Team != team {
+			continue
+		}
+		sum, ok := byRound[key.RoundNumber]
+		if !ok {
+			sum = &RoundCell{}
+			byRound[key.RoundNumber] = sum
+		}
+		sum.Rounds += cell.Rounds
+		sum.Wins += cell.Wins
+		sum.Spent += cell.Spent
+		sum.FTEEq += cell.FTEEq
+		sum.FundsStart += cell.FundsStart
+	}
+	means := make([]RoundMeans, 0, len(byRound))
+	for round, sum := range byRound {
+		n := float64(sum.Rounds)
+		means = append(means, RoundMeans{
+			RoundNumber: round,
+			Rounds:      sum.Rounds,
+			WinRate:     float64(sum.Wins) / n,
+			Spent:       sum.Spent / n,
+			FTEEq:       sum.FTEEq / n,
+			FundsStart:  sum.FundsStart / n,
+		})
+	}
+	sort.Slice(means, func(i, j int) bool { return means[i].RoundNumber < means[j].RoundNumber })
+	return means
+}
+
+// Close writes the table
+func (t *RoundTable) Close() error {
+	return t.writeCSV(t.path)
+}
+
+func roundTableHeader() []string {
+	header := []string{"team", "strategy", "round_number", "side", "team_score", "opponent_score",
+		"rounds", "wins", "win_rate", "mean_spent", "mean_fte_eq", "mean_funds_start"}
+	return append(header, strategy.BuyTypes...)
+}
+
+// writeCSV writes one row per cell with means and buy type counts. The means are exact
+// enough to merge tables by weighting them with the rounds column.
+func (t *RoundTable) writeCSV(path string) error {
+	keys := make([]RoundKey, 0, len(t.cells))
+	for key := range t.cells {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch {
+		case a.Team != b.Team:
+			return a.Team < b.Team
+		case a.Strategy != b.Strategy:
+			return a.Strategy < b.Strategy
+		case a.RoundNumber != b.RoundNumber:
+			return a.RoundNumber < b.RoundNumber
+		case a.Side != b.Side:
+			return a.Side < b.Side
+		case a.Score != b.Score:
+			return a.Score < b.Score
+		}
+		return a.OpponentScore < b.OpponentScore
+	})
+
+	f, err := util.CreateFile(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	w.Write(roundTableHeader())
+	for _, key := range keys {
+		cell := t.cells[key]
+		n := float64(cell.Rounds)
+		row := []string{
+			strconv.Itoa(key.Team),
+			key.Strategy,
+			strconv.Itoa(key.RoundNumber),
+			key.Side,
+			strconv.Itoa(key.Score),
+			strconv.Itoa(key.OpponentScore),
+			strconv.FormatInt(cell.Rounds, 10),
+			strconv.FormatInt(cell.Wins, 10),
+			strconv.FormatFloat(float64(cell.Wins)/n, 'f', 6, 64),
+			strconv.FormatFloat(cell.Spent/n, 'f', 4, 64),
+			strconv.FormatFloat(cell.FTEEq/n, 'f', 4, 64),
+			strconv.FormatFloat(cell.FundsStart/n, 'f', 4, 64),
+		}
+		for _, count := range cell.BuyTypes {
+			row = append(row, strconv.FormatInt(count, 10))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadRoundTable reads a table written by a RoundTable
+func ReadRoundTable(path string) (*RoundTable, error) {
+	f, err := util.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(header) != len(roundTableHeader()) {
+		return nil, fmt.Errorf("%s has %d columns, expected %d", path, len(header), len(roundTableHeader()))
+	}
+
+	t := NewRoundTable(path)
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		// Columns: team, strategy, round_number, side, team_score, opponent_score, rounds,
+		// wins, win_rate, three means, buy type counts
+		var ints [6]int64
+		for j, col := range []int{0, 2, 4, 5, 6, 7} {
+			if ints[j], err = strconv.ParseInt(row[col], 10, 64); err != nil {
+				return nil, fmt.Errorf("%s line %d: %s: %w", path, line, header[col], err)
+			}
+		}
+		var means [3]float64
+		for j := range means {
+			if means[j], err = strconv.ParseFloat(row[9+j], 64); err != nil {
+				return nil, fmt.Errorf("%s line %d: %s: %w", path, line, header[9+j], err)
+			}
+		}
+		key := RoundKey{Team: int(ints[0]), Strategy: row[1], RoundNumber: int(ints[1]), Side: row[3],
+			Score: int(ints[2]), OpponentScore: int(ints[3])}
+		cell := t.cell(key)
+		n := float64(ints[4])
+		cell.Rounds += ints[4]
+		cell.Wins += ints[5]
+		cell.Spent += means[0] * n
+		cell.FTEEq += means[1] * n
+		cell.FundsStart += means[2] * n
+		for j := range cell.BuyTypes {
+			count, err := strconv.ParseInt(row[12+j], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: %s: %w", path, line, header[12+j], err)
+			}
+			cell.BuyTypes[j] += count
+		}
+	}
+}
+
+// MergeRoundTableFiles adds the table in src to the one in dst and removes src. If dst
+// does not exist yet, src is renamed.
+func MergeRoundTableFiles(src, dst string) error {
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		return os.Rename(src, dst)
+	}
+	into, err := ReadRoundTable(dst)
+	if err != nil {
+		return err
+	}
+	from, err := ReadRoundTable(src)
+	if err != nil {
+		return err
+	}
+	into.Merge(from)
+	tmp := util.InsertSuffix(dst, ".tmp")
+	if err := into.writeCSV(tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -16,6 +16,7 @@ type SimulationStats struct {
 	// Game results
 	Team1Wins     int64 `json:"team1_wins"`
 	Team2Wins     int64 `json:"team2_wins"`
+	Draws         int64 `json:"draws,omitempty"` // Games that ended tied (GameRules.TieResolution "draw")
 	TotalRounds   int64 `json:"total_rounds"`
 	OvertimeGames int64 `json:"overtime_games"`
 	Team1OTWins   int64 `json:"team1_overtime_wins"`
@@ -26,6 +27,7 @@ type SimulationStats struct {
 	// Calculated metrics
 	Team1WinRate   float64 `json:"team1_win_rate"`
 	Team2WinRate   float64 `json:"team2_win_rate"`
+	DrawRate       float64 `json:"draw_rate,omitempty"`
 	AverageRounds  float64 `json:"average_rounds"`
 	OvertimeRate   float64 `json:"overtime_rate"`
 	Team1OTWinRate float64 `json:"team1_overtime_win_rate"`
@@ -33,6 +35,9 @@ type SimulationStats struct {
 	Team1RTWinRate float64 `json:"team1_regular_time_win_rate"`
 	Team2RTWinRate float64 `json:"team2_regular_time_win_rate"`
 
+	// Distributions (confidence intervals, score lines, quantiles), see UpdateAggregates
+	Aggregates *Aggregates `json:"aggregates,omitempty"`
+
 	// Performance metrics (optional for sequential)
 	ExecutionTime   time.Duration `json:"execution_time"`
 	ProcessingRate  float64       `json:"simulations_per_second,omitempty"`
@@ -40,7 +45,8 @@ type SimulationStats struct {
 	TotalGCRuns     uint32        `json:"total_gc_runs,omitempty"`
 
 	// Metadata
-	SimulationMode string            `json:"simulation_mode"` // "sequential" or "concurrent"
+	SimulationMode string            `json:"simulation_mode"`   // "sequential" or "concurrent"
+	Partial        bool              `json:"partial,omitempty"` // Interrupted run: only CompletedSims games are included
 	StartTime      time.Time         `json:"start_time"`
 	Config         *SimulationConfig `json:"simulation_config,omitempty"` // Configuration used for this simulation
 
@@ -98,6 +104,7 @@ func NewStats(NumSimulations int, mode string) *SimulationStats {
 		Team2OTWinRate: 0.0,
 		Team1RTWinRate: 0.0,
 		Team2RTWinRate: 0.0,
+		Aggregates:     NewAggregates(),
 
 		// Performance metrics
 		ProcessingRate:  0.0,
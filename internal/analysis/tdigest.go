@@ -0,0 +1,158 @@
+package analysis
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// defaultCompression bounds a digest to roughly 2x this many centroids
+const defaultCompression = 100
+
+// TDigest estimates quantiles of a stream of values in bounded memory (merging t-digest
+// with the k1 scale function). Values are buffered and merged into the centroids in
+// batches; digests of different batches of a run can be merged. Not safe for concurrent use.
+type TDigest struct {
+	Compression float64    `json:"compression"`
+	Centroids   []Centroid `json:"centroids"`
+	Count       float64    `json:"count"`
+	Sum         float64    `json:"sum"`
+	Min         float64    `json:"min"`
+	Max         float64    `json:"max"`
+
+	buffer []Centroid // Values not merged into Centroids yet
+}
+
+// Centroid is a cluster of values with their mean
+type Centroid struct {
+	Mean   float64 `json:"mean"`
+	Weight float64 `json:"weight"`
+}
+
+// NewTDigest creates an empty digest; compression <= 0 uses the default of 100
+func NewTDigest(compression float64) *TDigest {
+	if compression <= 0 {
+		compression = defaultCompression
+	}
+	return &TDigest{Compression: compression}
+}
+
+// Add adds a value
+func (t *TDigest) Add(x float64) {
+	t.add(Centroid{Mean: x, Weight: 1}, x, x)
+}
+
+// Merge adds all values of other to t
+func (t *TDigest) Merge(other *TDigest) {
+	if other == nil {
+		return
+	}
+	other.flush()
+	for _, c := range other.Centroids {
+		t.add(c, other.Min, other.Max)
+	}
+}
+
+func (t *TDigest) add(c Centroid, min, max float64) {
+	if t.Count == 0 || min < t.Min {
+		t.Min = min
+	}
+	if t.Count == 0 || max > t.Max {
+		t.Max = max
+	}
+	t.Count += c.Weight
+	t.Sum += c.Mean * c.Weight
+	t.buffer = append(t.buffer, c)
+	if len(t.buffer) >= int(5*t.Compression) {
+		t.flush()
+	}
+}
+
+// flush merges the buffered values into the centroids
+func (t *TDigest) flush() {
+	if len(t.buffer) == 0 {
+		return
+	}
+	all := append(t.Centroids, t.buffer...)
+	t.buffer = t.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].Mean < all[j].Mean })
+
+	// A centroid may grow while the quantile it ends at stays within one unit of k
+	merged := []Centroid{all[0]}
+	before := 0.0 // Weight of the centroids before the last merged one
+	limit := t.kInverse(t.k(0) + 1)
+	for _, c := range all[1:] {
+		last := &merged[len(merged)-1]
+		if (before+last.Weight+c.Weight)/t.Count <= limit {
+			last.Mean += (c.Mean - last.Mean) * c.Weight / (last.Weight + c.Weight)
+			last.Weight += c.Weight
+			continue
+		}
+		before += last.Weight
+		limit = t.kInverse(t.k(before/t.Count) + 1)
+		merged = append(merged, c)
+	}
+	t.Centroids = merged
+}
+
+// k1 scale function and its inverse
+func (t *TDigest) k(q float64) float64 {
+	return t.Compression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+func (t *TDigest) kInverse(k float64) float64 {
+	if k >= t.Compression/4 {
+		return 1
+	}
+	return (math.Sin(k*2*math.Pi/t.Compression) + 1) / 2
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1); it is 0 for an empty digest
+func (t *TDigest) Quantile(q float64) float64 {
+	t.flush()
+	if len(t.Centroids) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return t.Min
+	}
+	if q >= 1 {
+		return t.Max
+	}
+
+	// Interpolate between the centers of neighbouring centroids (and min/max at the ends)
+	target := q * t.Count
+	prevMean, prevPos := t.Min, 0.0
+	cum := 0.0
+	for _, c := range t.Centroids {
+		pos := cum + c.Weight/2
+		if target < pos {
+			if pos == prevPos {
+				return c.Mean
+			}
+			return prevMean + (target-prevPos)/(pos-prevPos)*(c.Mean-prevMean)
+		}
+		prevMean, prevPos = c.Mean, pos
+		cum += c.Weight
+	}
+	if t.Count == prevPos {
+		return t.Max
+	}
+	return prevMean + (target-prevPos)/(t.Count-prevPos)*(t.Max-prevMean)
+}
+
+// Mean returns the exact mean of the values
+func (t *TDigest) Mean() float64 {
+	if t.Count == 0 {
+		return 0
+	}
+	return t.Sum / t.Count
+}
+
+// MarshalJSON merges the buffered values first so that the digest round-trips
+// through checkpoint manifests
+func (t *TDigest) MarshalJSON() ([]byte, error) {
+	t.flush()
+	type digest TDigest
+	return json.Marshal((*digest)(t))
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// shuffled returns the values from..to-1 in a fixed random order
+func shuffled(from, to int, seed int64) []float64 {
+	values := make([]float64, 0, to-from)
+	for v := from; v < to; v++ {
+		values = append(values, float64(v))
+	}
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(values), func(i, j int) { values[i], values[j] = values[j], values[i] })
+	return values
+}
+
+// checkQuantiles compares the quantiles of a digest of the values 0..n-1 with the exact ones
+func checkQuantiles(t *testing.T, d *TDigest, n int) {
+	t.Helper()
+	tests := []struct {
+		q   float64
+		tol float64 // Allowed error in ranks, relative to n; the k1 scale is most precise in the tails
+	}{
+		{0.001, 0.001},
+		{0.01, 0.002},
+		{0.05, 0.005},
+		{0.25, 0.01},
+		{0.5, 0.01},
+		{0.75, 0.01},
+		{0.95, 0.005},
+		{0.99, 0.002},
+		{0.999, 0.001},
+	}
+	for _, tt := range tests {
+		want := tt.q * float64(n-1)
+		if got := d.Quantile(tt.q); math.Abs(got-want) > tt.tol*float64(n) {
+			t.Errorf("Quantile(%g) = %.1f, want %.1f ± %.1f", tt.q, got, want, tt.tol*float64(n))
+		}
+	}
+	if got := d.Quantile(0); got != 0 {
+		t.Errorf("Quantile(0) = %g, want the minimum 0", got)
+	}
+	if got := d.Quantile(1); got != float64(n-1) {
+		t.Errorf("Quantile(1) = %g, want the maximum %d", got, n-1)
+	}
+}
+
+func TestTDigestQuantiles(t *testing.T) {
+	const n = 100000
+	d := NewTDigest(0)
+	for _, v := range shuffled(0, n, 1) {
+		d.Add(v)
+	}
+	checkQuantiles(t, d, n)
+	if got, want := d.Mean(), float64(n-1)/2; got != want {
+		t.Errorf("Mean() = %g, want %g", got, want)
+	}
+	if len(d.Centroids) > 2*defaultCompression {
+		t.Errorf("digest has %d centroids, want at most %d", len(d.Centroids), 2*defaultCompression)
+	}
+}
+
+func TestTDigestSmall(t *testing.T) {
+	empty := NewTDigest(0)
+	if got := empty.Quantile(0.5); got != 0 {
+		t.Errorf("empty digest: Quantile(0.5) = %g, want 0", got)
+	}
+	if got := empty.Mean(); got != 0 {
+		t.Errorf("empty digest: Mean() = %g, want 0", got)
+	}
+
+	single := NewTDigest(0)
+	single.Add(42)
+	for _, q := range []float64{0, 0.25, 0.5, 1} {
+		if got := single.Quantile(q); got != 42 {
+			t.Errorf("single value: Quantile(%g) = %g, want 42", q, got)
+		}
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	const n = 100000
+	// Batches of a run each get their own digest; the merged one describes the whole run
+	merged := NewTDigest(0)
+	for b := 0; b < 4; b++ {
+		part := NewTDigest(0)
+		for _, v := range shuffled(b*n/4, (b+1)*n/4, int64(b)) {
+			part.Add(v)
+		}
+		merged.Merge(part)
+	}
+	merged.Merge(nil)
+
+	if merged.Count != n {
+		t.Errorf("Count = %g, want %d", merged.Count, n)
+	}
+	if merged.Min != 0 || merged.Max != n-1 {
+		t.Errorf("Min, Max = %g, %g, want 0, %d", merged.Min, merged.Max, n-1)
+	}
+	if got, want := merged.Mean(), float64(n-1)/2; got != want {
+		t.Errorf("Mean() = %g, want %g", got, want)
+	}
+	checkQuantiles(t, merged, n)
+}
+
+func TestTDigestJSONRoundTrip(t *testing.T) {
+	d := NewTDigest(50)
+	for _, v := range shuffled(0, 10000, 2) {
+		d.Add(v)
+	}
+	d.Add(10000) // Still buffered when the digest is marshaled
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var restored TDigest
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if restored.Compression != 50 || restored.Count != d.Count || restored.Sum != d.Sum || restored.Min != d.Min || restored.Max != d.Max {
+		t.Errorf("restored digest {compression %g, count %g, sum %g, min %g, max %g}, want {%g, %g, %g, %g, %g}",
+			restored.Compression, restored.Count, restored.Sum, restored.Min, restored.Max, d.Compression, d.Count, d.Sum, d.Min, d.Max)
+	}
+	for _, q := range []float64{0, 0.01, 0.25, 0.5, 0.75, 0.99, 1} {
+		if got, want := restored.Quantile(q), d.Quantile(q); got != want {
+			t.Errorf("restored Quantile(%g) = %g, want %g", q, got, want)
+		}
+	}
+
+	// A restored digest keeps accepting values, as after resuming from a checkpoint
+	restored.Add(20000)
+	if restored.Count != d.Count+1 || restored.Quantile(1) != 20000 {
+		t.Errorf("after Add: Count %g, Quantile(1) %g, want %g, 20000", restored.Count, restored.Quantile(1), d.Count+1)
+	}
+}
@@ -8,30 +8,67 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"dbg_abm/internal/engine"
 	"dbg_abm/internal/tournament"
+	"dbg_abm/util"
 )
 
-// ExportTournamentSummary writes tournament matches, series, and standings to JSON and standings CSV
-func ExportTournamentSummary(dir string, matches []tournament.MatchSpec, series []tournament.SeriesResult, standings tournament.Standings) error {
+// TournamentSeriesExport is a series with its aggregated outcomes as written to tournament_summary.json
+type TournamentSeriesExport struct {
+	tournament.SeriesResult
+	Summary tournament.SeriesSummary `json:"summary"`
+}
+
+// TournamentSummary is the content of tournament_summary.json
+type TournamentSummary struct {
+	Partial          bool                     `json:"partial,omitempty"`
+	CompletedMatchup int                      `json:"completed_matchups"`
+	Matches          []tournament.MatchSpec   `json:"matches"`
+	Series           []TournamentSeriesExport `json:"series"`
+	Standings        tournament.Standings     `json:"standings"`
+	Rules            *engine.GameRules        `json:"game_rules,omitempty"` // Resolved rules every game was played with
+}
+
+// LoadTournamentSummary loads a tournament_summary.json written by ExportTournamentSummary
+func LoadTournamentSummary(filename string) (*TournamentSummary, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	summary := &TournamentSummary{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		return nil, fmt.Errorf("failed to parse tournament summary '%s': %w", filename, err)
+	}
+	return summary, nil
+}
+
+// ExportTournamentSummary writes tournament matches, series, and standings to JSON and standings CSV.
+// partial marks a tournament that was interrupted before all matchups finished.
+func ExportTournamentSummary(dir string, matches []tournament.MatchSpec, series []tournament.SeriesResult, standings tournament.Standings, rules engine.GameRules, partial bool) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 	// CSV standings (best-effort if type provides GetRows)
-	f, err := os.Create(filepath.Join(dir, "tournament_standings.csv"))
+	f, err := util.CreateFile(filepath.Join(dir, util.CompressedName("tournament_standings.csv")))
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 	w := csv.NewWriter(f)
 	defer w.Flush()
-	w.Write([]string{"strategy", "wins", "losses", "map_wins", "map_losses"})
+	w.Write([]string{"strategy", "points", "wins", "losses", "draws", "map_wins", "map_losses", "rounds_won", "rounds_lost", "round_diff"})
 	for _, r := range standings.Rows {
 		w.Write([]string{
 			r.Strategy,
+			strconv.Itoa(r.Points),
 			strconv.Itoa(r.Wins),
 			strconv.Itoa(r.Losses),
+			strconv.Itoa(r.Draws),
 			strconv.Itoa(r.MapWins),
 			strconv.Itoa(r.MapLoss),
+			strconv.Itoa(r.RoundsWon),
+			strconv.Itoa(r.RoundsLost),
+			strconv.Itoa(r.RoundDiff),
 		})
 	}
 
@@ -58,13 +95,16 @@ func ExportTournamentSummary(dir string, matches []tournament.MatchSpec, series
 		team1Wins := 0
 		team2Wins := 0
 		for _, g := range ser.GameResults {
+			if g.Draw {
+				continue
+			}
 			if g.T1Wins {
 				team1Wins++
 			} else {
 				team2Wins++
 			}
 		}
-		totalGames := team1Wins + team2Wins
+		totalGames := len(ser.GameResults) // Draws count as games won by neither
 		// Populate both cells symmetrically
 		totals[i][j] = totalGames
 		totals[j][i] = totalGames
@@ -72,7 +112,7 @@ func ExportTournamentSummary(dir string, matches []tournament.MatchSpec, series
 		wins[j][i] = team2Wins
 	}
 
-	mf, err := os.Create(filepath.Join(dir, "tournament_matrix.csv"))
+	mf, err := util.CreateFile(filepath.Join(dir, util.CompressedName("tournament_matrix.csv")))
 	if err != nil {
 		return err
 	}
@@ -105,7 +145,90 @@ func ExportTournamentSummary(dir string, matches []tournament.MatchSpec, series
 		mw.Write(row)
 	}
 
-	return nil
+	// JSON summary with per-series score lines, round differentials and OT rates
+	seriesExport := make([]TournamentSeriesExport, len(series))
+	for i, ser := range series {
+		seriesExport[i] = TournamentSeriesExport{SeriesResult: ser, Summary: ser.Summarize()}
+	}
+	summary := TournamentSummary{
+		Partial:          partial,
+		CompletedMatchup: len(series),
+		Matches:          matches,
+		Series:           seriesExport,
+		Standings:        standings,
+		Rules:            &rules,
+	}
+	if err := writeJSON(filepath.Join(dir, "tournament_summary.json"), summary); err != nil {
+		return err
+	}
+
+	if err := exportTournamentPrecision(filepath.Join(dir, util.CompressedName("tournament_precision.csv")), series); err != nil {
+		return err
+	}
+
+	return exportTournamentGames(filepath.Join(dir, util.CompressedName("tournament_games.csv")), series)
+}
+
+// exportTournamentPrecision writes the achieved precision of every matchup's win rate
+func exportTournamentPrecision(path string, series []tournament.SeriesResult) error {
+	f, err := util.CreateFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	w.Write([]string{"team1_strategy", "team2_strategy", "games", "team1_win_rate", "ci_low", "ci_high", "ci_half_width", "confidence", "decision", "stop_reason"})
+	for _, ser := range series {
+		p := ser.Precision
+		if p == nil {
+			continue
+		}
+		w.Write([]string{
+			ser.Match.Team1Strategy,
+			ser.Match.Team2Strategy,
+			strconv.Itoa(p.Games),
+			strconv.FormatFloat(p.WinRate, 'f', 6, 64),
+			strconv.FormatFloat(p.CILow, 'f', 6, 64),
+			strconv.FormatFloat(p.CIHigh, 'f', 6, 64),
+			strconv.FormatFloat(p.HalfWidth, 'f', 6, 64),
+			strconv.FormatFloat(p.Confidence, 'f', 3, 64),
+			p.Decision,
+			p.StopReason,
+		})
+	}
+	return w.Error()
+}
+
+// exportTournamentGames writes one row per game with its real score line
+func exportTournamentGames(path string, series []tournament.SeriesResult) error {
+	f, err := util.CreateFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	w.Write([]string{"matchup", "team1_strategy", "team2_strategy", "team1_won", "draw", "team1_score", "team2_score", "rounds", "ot_count", "seed"})
+	for i, ser := range series {
+		for _, g := range ser.GameResults {
+			w.Write([]string{
+				strconv.Itoa(i + 1),
+				ser.Match.Team1Strategy,
+				ser.Match.Team2Strategy,
+				strconv.FormatBool(g.T1Wins),
+				strconv.FormatBool(g.Draw),
+				strconv.Itoa(g.Score[0]),
+				strconv.Itoa(g.Score[1]),
+				strconv.Itoa(g.Rounds),
+				strconv.Itoa(g.OTCount),
+				strconv.FormatInt(g.Seed, 10),
+			})
+		}
+	}
+	return w.Error()
 }
 
 func writeJSON(path string, v any) error {
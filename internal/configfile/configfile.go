@@ -0,0 +1,59 @@
+// Package configfile reads configuration files written as JSON, YAML or TOML. YAML and TOML
+// are converted to JSON so that every format goes through the same strict JSON decoding.
+package configfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format returns the format of a configuration file from its extension: "yaml" (.yaml,
+// .yml), "toml" (.toml) or "json" (everything else)
+func Format(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return "json"
+}
+
+// ReadJSON returns the content of the configuration file at path as JSON. converted
+// tells whether the file was YAML or TOML, so that offsets in decoding errors do not refer
+// to lines of the file.
+func ReadJSON(path string) (data []byte, converted bool, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	format := Format(path)
+	if format == "json" {
+		return data, false, nil
+	}
+
+	var doc map[string]any
+	switch format {
+	case "yaml":
+		err = yaml.Unmarshal(data, &doc)
+	case "toml":
+		err = toml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{} // Empty file
+	}
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return nil, true, fmt.Errorf("%s: cannot be represented as JSON: %w", path, err)
+	}
+	return data, true, nil
+}
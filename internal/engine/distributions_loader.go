@@ -1,6 +1,8 @@
 package engine
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -46,6 +48,9 @@ type ProcessedDistributions struct {
 
 	// EquipmentSaved: side -> reasonCode -> survivorCount -> sorted percentiles
 	EquipmentSaved map[string]map[string]map[string][]PercentileValue
+
+	// Hash: SHA-256 of the distributions file the data was loaded from
+	Hash string
 }
 
 // Raw JSON structures for unmarshaling
@@ -141,6 +146,9 @@ func loadAndProcessDistributions(filePath string) (*ProcessedDistributions, erro
 		EquipmentSaved: make(map[string]map[string]map[string][]PercentileValue),
 	}
 
+	sum := sha256.Sum256(data)
+	processed.Hash = hex.EncodeToString(sum[:])
+
 	// Copy metadata fields manually
 	processed.Metadata.CSFRValue = raw.Metadata.CSFRValue
 	processed.Metadata.CSFRanges.Min = raw.Metadata.CSFRanges.Min
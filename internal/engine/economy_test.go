@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func float(v float64) *float64 { return &v }
+
+func TestEconomySideRules(t *testing.T) {
+	rules := getDefaultRules()
+	rules.CT = &SideRules{LossBonus: []float64{1000, 1500, 2000}, MaxFunds: float(20000), DefaultEquipment: float(400)}
+	rules.T = &SideRules{StartingFunds: float(1000), EliminationReward: float(0)}
+
+	ct, tside := rules.Economy(true), rules.Economy(false)
+	want := map[string][2]any{ // field -> CT, T
+		"LossBonus":         {[]float64{1000, 1500, 2000}, rules.LossBonus},
+		"MaxFunds":          {20000.0, rules.MaxFunds},
+		"DefaultEquipment":  {400.0, rules.DefaultEquipment},
+		"StartingFunds":     {rules.StartingFunds, 1000.0},
+		"EliminationReward": {rules.EliminationReward, 0.0}, // An explicit zero replaces the shared value
+		"OTFunds":           {rules.OTFunds, rules.OTFunds},
+	}
+	for field, w := range want {
+		got := [2]any{reflect.ValueOf(ct).FieldByName(field).Interface(), reflect.ValueOf(tside).FieldByName(field).Interface()}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("%s: CT %v, T %v; want %v, %v", field, got[0], got[1], w[0], w[1])
+		}
+	}
+
+	if shared := getDefaultRules(); !reflect.DeepEqual(shared.Economy(true), shared.Economy(false)) {
+		t.Error("without side blocks both sides must play the shared economy")
+	}
+}
+
+func TestDetermineFundsEarnedPerSide(t *testing.T) {
+	// CT: own loss bonus ladder and funds cap; T: the shared ladder (1400 ... 3400) and cap
+	rules := getDefaultRules()
+	rules.CT = &SideRules{LossBonus: []float64{1000, 1500, 2000}, MaxFunds: float(20000)}
+	const start = 5 * 800.0
+	const winnerEarned = 3250*5 + 5*300.0 // Elimination win, all five opponents killed
+
+	tests := []struct {
+		name        string
+		t1CT        bool
+		ctWins      bool
+		loserLevel  int
+		loserEarned float64
+		loserLevel2 int        // Loss bonus level after the round
+		funds       [2]float64 // Team1, Team2 after the round
+	}{
+		{"T loses at level 0", true, true, 0, 1400 * 5, 1, [2]float64{20000, start + 1400*5}},
+		{"T loses at the top level", true, true, 4, 3400 * 5, 4, [2]float64{20000, start + 3400*5}},
+		{"CT loses at level 0", true, false, 0, 1000 * 5, 1, [2]float64{start + 1000*5, start + winnerEarned}},
+		{"CT loses at level 2", true, false, 2, 2000 * 5, 2, [2]float64{start + 2000*5, start + winnerEarned}},
+		{"CT loses beyond its ladder", false, false, 4, 2000 * 5, 2, [2]float64{start + winnerEarned, start + 2000*5}},
+		{"T loses as Team1", false, true, 1, 1900 * 5, 2, [2]float64{start + 1900*5, 20000}},
+	}
+	for _, tt := range tests {
+		t1 := NewTeam("A", 800, tt.t1CT, 200, "all_in")
+		t2 := NewTeam("B", 800, !tt.t1CT, 200, "all_in")
+		r := &Round{RoundNumber: 1, IsT1CT: tt.t1CT, gameRules: &rules}
+		if tt.ctWins {
+			r.Calc_Outcome = RoundOutcome{CTWins: true, ReasonCode: 4, CTSurvivors: 5}
+		} else {
+			r.Calc_Outcome = RoundOutcome{ReasonCode: 2, TSurvivors: 5}
+		}
+		r.IsT1WinnerTeam = tt.ctWins == tt.t1CT
+
+		loser, winner := t1, t2
+		if r.IsT1WinnerTeam {
+			loser, winner = t2, t1
+		}
+		loser.RoundData[0].LossBonusLevel = tt.loserLevel
+		r.determineFundsEarned(t1, t2)
+
+		if got := loser.RoundData[0].Earned; got != tt.loserEarned {
+			t.Errorf("%s: loser earned %g, want %g", tt.name, got, tt.loserEarned)
+		}
+		if got := winner.RoundData[0].Earned; got != winnerEarned {
+			t.Errorf("%s: winner earned %g, want %g", tt.name, got, winnerEarned)
+		}
+		if got := loser.GetCurrentLossBonusLevel(); got != tt.loserLevel2 {
+			t.Errorf("%s: loss bonus level %d after the round, want %d", tt.name, got, tt.loserLevel2)
+		}
+		if got := [2]float64{t1.GetCurrentFunds(), t2.GetCurrentFunds()}; got != tt.funds {
+			t.Errorf("%s: funds %v, want %v", tt.name, got, tt.funds)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package engine
+
+// EventHandler receives the events of a game while it is played. It is called on the
+// goroutine running the game, so a handler shared by concurrent games must be safe for
+// concurrent use.
+type EventHandler func(Event)
+
+// Event types
+const (
+	EventGameStart  = "game_start"  // GameStartData
+	EventSideSwitch = "side_switch" // SideSwitchData, halftime of regulation or overtime
+	EventOTStart    = "ot_start"    // OTStartData
+	EventTimeout    = "timeout"     // TimeoutData, one per team that calls a timeout
+	EventBuy        = "buy"         // BuyData, one per team and round
+	EventOutcome    = "outcome"     // OutcomeData
+	EventFunds      = "funds"       // FundsData, one per team and round
+	EventGameEnd    = "game_end"    // GameEndData
+)
+
+// Event is one step of a game. Data holds the type-specific payload.
+type Event struct {
+	Type   string      `json:"type"`
+	GameID string      `json:"game_id"`
+	Round  int         `json:"round"`
+	Data   interface{} `json:"data"`
+}
+
+type GameStartData struct {
+	Seed          int64  `json:"seed"`
+	Team1Name     string `json:"team1_name"`
+	Team1Strategy string `json:"team1_strategy"`
+	Team2Name     string `json:"team2_name"`
+	Team2Strategy string `json:"team2_strategy"`
+	T1StartsCT    bool   `json:"t1_starts_ct"`
+}
+
+type SideSwitchData struct {
+	OT     bool `json:"ot"`
+	T1IsCT bool `json:"t1_is_ct"` // Side of team 1 after the switch
+}
+
+type OTStartData struct {
+	OTNumber    int    `json:"ot_number"`
+	Score       [2]int `json:"score"`
+	SuddenDeath bool   `json:"sudden_death,omitempty"` // A single round decides the tied game
+}
+
+// TimeoutData is a timeout a team called before the buy phase of the round
+type TimeoutData struct {
+	Team         string `json:"team"`
+	Strategy     string `json:"strategy"`
+	Side         string `json:"side"` // CT or T
+	TimeoutsLeft int    `json:"timeouts_left"`
+	Effect       string `json:"effect"`
+}
+
+// BuyData is a team's buy decision together with the state the strategy decided on
+type BuyData struct {
+	Team              string  `json:"team"`
+	Strategy          string  `json:"strategy"`
+	Side              string  `json:"side"` // CT or T
+	OT                bool    `json:"ot"`
+	Score             int     `json:"score"`
+	OpponentScore     int     `json:"opponent_score"`
+	FundsStart        float64 `json:"funds_start"`
+	Spent             float64 `json:"spent"`
+	BuyType           string  `json:"buy_type"`
+	FundsAfterBuy     float64 `json:"funds_after_buy"`
+	RSEquipment       float64 `json:"rs_equipment"`
+	FTEEquipment      float64 `json:"fte_equipment"`
+	ConsecutiveLosses int     `json:"consecutive_losses"`
+	ConsecutiveWins   int     `json:"consecutive_wins"`
+	LossBonusLevel    int     `json:"loss_bonus_level"`
+	OpponentFunds     float64 `json:"opponent_funds_start"`
+}
+
+type OutcomeData struct {
+	T1Winner                  bool         `json:"t1_winner"`
+	CTWins                    bool         `json:"ct_wins"`
+	ReasonCode                int          `json:"reason_code"`
+	BombPlanted               bool         `json:"bomb_planted"`
+	CTSurvivors               int          `json:"ct_survivors"`
+	TSurvivors                int          `json:"t_survivors"`
+	CSF                       float64      `json:"csf"`
+	CSFKey                    string       `json:"csf_key"`
+	CTEquipmentSharePerPlayer []float64    `json:"ct_equipment_share_per_player"`
+	TEquipmentSharePerPlayer  []float64    `json:"t_equipment_share_per_player"`
+	CTEquipmentPerPlayer      []float64    `json:"ct_equipment_per_player"`
+	TEquipmentPerPlayer       []float64    `json:"t_equipment_per_player"`
+	RNG                       RNG_Outcomes `json:"rng"`
+	Score                     [2]int       `json:"score"` // Score after the round
+}
+
+// FundsData is a team's money change at the end of a round
+type FundsData struct {
+	Team           string  `json:"team"`
+	Earned         float64 `json:"earned"`
+	FundsEnd       float64 `json:"funds_end"`
+	REEquipment    float64 `json:"re_equipment"`
+	Survivors      int     `json:"survivors"`
+	LossBonusLevel int     `json:"loss_bonus_level"`
+}
+
+type GameEndData struct {
+	Score    [2]int  `json:"score"`
+	Outcome  Outcome `json:"outcome"`   // team1, team2 or draw
+	T1Winner bool    `json:"t1_winner"` // Same as outcome == team1, kept for existing consumers
+	Rounds   int     `json:"rounds"`
+	OTCount  int     `json:"ot_count"`
+}
+
+func (g *Game) emit(eventType string, data interface{}) {
+	g.Events(Event{Type: eventType, GameID: g.ID, Round: g.CurrentRound, Data: data})
+}
+
+func (g *Game) emitTimeout(round *Round, t *Team) {
+	rd := t.RoundData[len(t.RoundData)-1]
+	if !rd.Timeout {
+		return
+	}
+	side := "T"
+	if rd.is_Side_CT {
+		side = "CT"
+	}
+	g.emit(EventTimeout, TimeoutData{
+		Team:         t.Name,
+		Strategy:     t.Strategy,
+		Side:         side,
+		TimeoutsLeft: rd.TimeoutsLeft,
+		Effect:       round.gameRules.Timeouts.Effect,
+	})
+}
+
+func (g *Game) emitBuy(round *Round, t, opponent *Team, score, opponentScore int, ct bool) {
+	rd := t.RoundData[len(t.RoundData)-1]
+	side := "T"
+	if ct {
+		side = "CT"
+	}
+	g.emit(EventBuy, BuyData{
+		Team:              t.Name,
+		Strategy:          t.Strategy,
+		Side:              side,
+		OT:                round.OT,
+		Score:             score,
+		OpponentScore:     opponentScore,
+		FundsStart:        rd.Funds_start,
+		Spent:             rd.Spent,
+		BuyType:           rd.BuyType,
+		FundsAfterBuy:     rd.Funds,
+		RSEquipment:       rd.RS_Eq_value,
+		FTEEquipment:      rd.FTE_Eq_value,
+		ConsecutiveLosses: rd.Consecutiveloss_start,
+		ConsecutiveWins:   rd.Consecutivewins_start,
+		LossBonusLevel:    rd.LossBonusLevel,
+		OpponentFunds:     opponent.RoundData[len(opponent.RoundData)-1].Funds_start,
+	})
+}
+
+func (g *Game) emitOutcome(round *Round) {
+	o := round.Calc_Outcome
+	g.emit(EventOutcome, OutcomeData{
+		T1Winner:                  round.IsT1WinnerTeam,
+		CTWins:                    o.CTWins,
+		ReasonCode:                o.ReasonCode,
+		BombPlanted:               o.BombPlanted,
+		CTSurvivors:               o.CTSurvivors,
+		TSurvivors:                o.TSurvivors,
+		CSF:                       o.CSF,
+		CSFKey:                    o.CSFKey,
+		CTEquipmentSharePerPlayer: o.CTEquipmentSharePerPlayer,
+		TEquipmentSharePerPlayer:  o.TEquipmentSharePerPlayer,
+		CTEquipmentPerPlayer:      o.CTEquipmentPerPlayer,
+		TEquipmentPerPlayer:       o.TEquipmentPerPlayer,
+		RNG:                       o.StochasticValues,
+		Score:                     g.Score,
+	})
+}
+
+func (g *Game) emitFunds(t *Team) {
+	rd := t.RoundData[len(t.RoundData)-1]
+	g.emit(EventFunds, FundsData{
+		Team:           t.Name,
+		Earned:         rd.Earned,
+		FundsEnd:       rd.Funds,
+		REEquipment:    rd.RE_Eq_value,
+		Survivors:      rd.Survivors,
+		LossBonusLevel: rd.LossBonusLevel,
+	})
+}
@@ -6,8 +6,8 @@ import (
 )
 
 //!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
-//CAUTION There is currently a max number of overtime set to 50. Meaning an overtime can have max 15 * 2 + 50 * 6= 330 rounds.
-//This is a temporary solution to prevent infinite loops in the game simulation.
+//CAUTION The number of overtimes is capped by GameRules.MaxOvertimes (50 by default, max 15 * 2 + 50 * 6= 330 rounds).
+//A game still tied after the last overtime is decided by GameRules.TieResolution, which prevents infinite loops in the game simulation.
 
 type Game struct {
 	ID             string
@@ -21,22 +21,33 @@ type Game struct {
 	firsthalf      bool
 	sideswitch     bool
 	GameRules      GameRules
-	Is_T1_Winner   bool // true if T1 wins, false if T2 wins
+	Outcome        Outcome // Team1 win, Team2 win or draw once the game is finished
 	Team1          *Team
 	Team2          *Team
-	rng            *rand.Rand // Thread-safe RNG for this game instance
+	Seed           int64        // Seed of the game's RNG, allows replaying a game exactly
+	Events         EventHandler // Optional: receives buy, outcome, funds, side switch and OT events while the game runs
+	rng            *rand.Rand   // Thread-safe RNG for this game instance
+	suddenDeath    bool         // The next round decides the game (TieSuddenDeath)
 }
 
 // NewGame creates a new game with pre-validated GameRules object (optimized for batch simulations)
 func NewGame(id string, Team1Name string, Team1Strategy string, Team2Name string, Team2Strategy string, gameRules GameRules) *Game {
+	return NewSeededGame(id, Team1Name, Team1Strategy, Team2Name, Team2Strategy, gameRules, rand.Int63())
+}
+
+// NewSeededGame creates a new game whose RNG (including the starting side) is derived from seed
+func NewSeededGame(id string, Team1Name string, Team1Strategy string, Team2Name string, Team2Strategy string, gameRules GameRules, seed int64) *Game {
 
 	// Create a thread-safe RNG for this game instance
-	rng := rand.New(rand.NewSource(rand.Int63()))
+	rng := rand.New(rand.NewSource(seed))
 
 	currentCT := rng.Intn(2) == 0
 
-	Team_1 := NewTeam(Team1Name, gameRules.StartingFunds, currentCT, gameRules.DefaultEquipment, Team1Strategy)
-	Team_2 := NewTeam(Team2Name, gameRules.StartingFunds, !currentCT, gameRules.DefaultEquipment, Team2Strategy)
+	t1Economy, t2Economy := gameRules.Economy(currentCT), gameRules.Economy(!currentCT)
+	Team_1 := NewTeam(Team1Name, t1Economy.StartingFunds, currentCT, t1Economy.DefaultEquipment, Team1Strategy)
+	Team_2 := NewTeam(Team2Name, t2Economy.StartingFunds, !currentCT, t2Economy.DefaultEquipment, Team2Strategy)
+	Team_1.SetTimeoutsLeft(gameRules.TimeoutsPerHalf(false))
+	Team_2.SetTimeoutsLeft(gameRules.TimeoutsPerHalf(false))
 
 	return &Game{
 		ID:             id,
@@ -51,6 +62,7 @@ func NewGame(id string, Team1Name string, Team1Strategy string, Team2Name string
 		Score:          [2]int{0, 0},
 		GameRules:      gameRules,
 		GameinProgress: false,
+		Seed:           seed,
 		rng:            rng,
 	}
 
@@ -58,34 +70,71 @@ func NewGame(id string, Team1Name string, Team1Strategy string, Team2Name string
 
 // SetSeed sets the RNG seed for this game to ensure reproducible outcomes per game/series
 func (g *Game) SetSeed(seed int64) {
+	g.Seed = seed
 	g.rng = rand.New(rand.NewSource(seed))
 }
 
 func (g *Game) Start() {
 	g.GameinProgress = true
 
+	if g.Events != nil {
+		g.emit(EventGameStart, GameStartData{
+			Seed:          g.Seed,
+			Team1Name:     g.Team1.Name,
+			Team1Strategy: g.Team1.Strategy,
+			Team2Name:     g.Team2.Name,
+			Team2Strategy: g.Team2.Strategy,
+			T1StartsCT:    g.is_T1_CT,
+		})
+	}
+
 	for g.GameinProgress {
 
 		round := NewRound(g.Team1, g.Team2, g.CurrentRound, g.is_T1_CT, &g.GameRules, g.OT, g)
 
 		// Handle side switches and OT transitions
-		if g.CurrentRound == g.GameRules.HalfLength+1 {
+		if g.suddenDeath {
+			// Deciding round, played on the current sides with overtime money
+			round.HandleOTStart(g.Team1, g.Team2)
+			if g.Events != nil {
+				g.emit(EventOTStart, OTStartData{OTNumber: g.OTcounter, Score: g.Score, SuddenDeath: true})
+			}
+		} else if g.CurrentRound == g.GameRules.HalfLength+1 {
 			// Regular halftime side switch
 			round.HandleSideSwitch(g.Team1, g.Team2)
 			g.is_T1_CT = !g.is_T1_CT
 			g.firsthalf = !g.firsthalf
+			if g.Events != nil {
+				g.emit(EventSideSwitch, SideSwitchData{OT: false, T1IsCT: g.is_T1_CT})
+			}
 		} else if g.CurrentRound == (g.GameRules.HalfLength*2)+1 || (g.OT && g.CurrentRound == ((g.GameRules.HalfLength*2)+(g.OTcounter*g.GameRules.OTHalfLength*2)+1)) {
 			// Start of overtime
 			g.OT = true
 			g.OTcounter++
 			round.HandleOTStart(g.Team1, g.Team2)
+			if g.Events != nil {
+				g.emit(EventOTStart, OTStartData{OTNumber: g.OTcounter, Score: g.Score})
+			}
 		} else if g.OT && g.CurrentRound == (g.GameRules.HalfLength*2)+((g.OTcounter-1)*g.GameRules.OTHalfLength*2)+g.GameRules.OTHalfLength+1 {
 			// OT halftime side switch
 			round.HandleOTSideSwitch(g.Team1, g.Team2)
 			g.is_T1_CT = !g.is_T1_CT
+			if g.Events != nil {
+				g.emit(EventSideSwitch, SideSwitchData{OT: true, T1IsCT: g.is_T1_CT})
+			}
+		}
+
+		round.TimeoutPhase(g.Team1, g.Team2)
+		if g.Events != nil {
+			g.emitTimeout(round, g.Team1)
+			g.emitTimeout(round, g.Team2)
 		}
 
 		round.BuyPhase(g.Team1, g.Team2)
+		if g.Events != nil {
+			g.emitBuy(round, g.Team1, g.Team2, g.Score[0], g.Score[1], round.IsT1CT)
+			g.emitBuy(round, g.Team2, g.Team1, g.Score[1], g.Score[0], !round.IsT1CT)
+		}
 
 		round.RoundStart(g.Team1, g.Team2)
 
@@ -94,6 +143,11 @@ func (g *Game) Start() {
 		g.Rounds = append(g.Rounds, *round)
 
 		g.UpdateScore(round.IsT1WinnerTeam)
+		if g.Events != nil {
+			g.emitOutcome(round)
+			g.emitFunds(g.Team1)
+			g.emitFunds(g.Team2)
+		}
 
 		// Clear round pointer to help GC
 		round = nil
@@ -103,34 +157,94 @@ func (g *Game) Start() {
 
 	}
 
+	if g.Events != nil {
+		g.Events(Event{Type: EventGameEnd, GameID: g.ID, Round: len(g.Rounds), Data: GameEndData{
+			Score:    g.Score,
+			Outcome:  g.Outcome,
+			T1Winner: g.Outcome.Team1Won(),
+			Rounds:   len(g.Rounds),
+			OTCount:  g.OTcounter,
+		}})
+	}
+}
+
+// isMatchPoint reports whether a team with score wins the game by winning the next round
+// against a team with opponent
+func (g *Game) isMatchPoint(score, opponent int) bool {
+	rules := &g.GameRules
+	switch {
+	case g.suddenDeath:
+		return true
+	case !g.OT:
+		return score == rules.HalfLength && opponent < rules.HalfLength
+	case rules.OTWinCondition == OTWinLead2:
+		return score-opponent == 1
+	}
+	return score >= rules.HalfLength+g.OTcounter*rules.OTHalfLength && score > opponent
 }
 
 func (g *Game) GameFinished() {
+	rules := &g.GameRules
+	played := g.CurrentRound - 1
+	lead := g.Score[0] - g.Score[1]
+
+	if g.suddenDeath {
+		// The deciding round is over, its winner wins the game
+		g.finish(winnerOutcome(g.Rounds[len(g.Rounds)-1].IsT1WinnerTeam))
+		return
+	}
+
 	if !g.OT {
-		if g.Score[0] >= (g.GameRules.HalfLength+1) && g.Score[1] < (g.GameRules.HalfLength) {
-			g.GameinProgress = false
-			g.Is_T1_Winner = true // Team1 wins
-		} else if g.Score[1] >= (g.GameRules.HalfLength+1) && g.Score[0] < (g.GameRules.HalfLength) {
-			g.GameinProgress = false
-			g.Is_T1_Winner = false // Team2 wins
+		if g.Score[0] >= (rules.HalfLength+1) && g.Score[1] < (rules.HalfLength) {
+			g.finish(OutcomeTeam1Win)
+		} else if g.Score[1] >= (rules.HalfLength+1) && g.Score[0] < (rules.HalfLength) {
+			g.finish(OutcomeTeam2Win)
+		} else if played == rules.HalfLength*2 && rules.AllowDraw {
+			g.finish(OutcomeDraw) // Tied after regulation, e.g. 12-12 in CS2 competitive
+		} else if played == rules.HalfLength*2 && rules.MaxOvertimes == 0 {
+			g.resolveTie()
+		}
+		return
+	}
+
+	switch rules.OTWinCondition {
+	case OTWinLead2:
+		if lead >= 2 || lead <= -2 {
+			g.finish(winnerOutcome(lead > 0))
+		} else if played == rules.HalfLength*2+rules.MaxOvertimes*rules.OTHalfLength*2 {
+			g.resolveTie()
 		}
-	} else if ((g.Score[0]-g.GameRules.HalfLength-(g.OTcounter*g.GameRules.OTHalfLength)) >= 1 || (g.Score[1]-g.GameRules.HalfLength-(g.OTcounter*g.GameRules.OTHalfLength)) >= 1) && math.Abs(float64(g.Score[0]-g.Score[1])) >= 2 {
-		g.GameinProgress = false
-		if g.Score[0] > g.Score[1] {
-			g.Is_T1_Winner = true // Team1 wins
-		} else {
-			g.Is_T1_Winner = false // Team2 wins
+	default:
+		if ((g.Score[0]-rules.HalfLength-(g.OTcounter*rules.OTHalfLength)) >= 1 || (g.Score[1]-rules.HalfLength-(g.OTcounter*rules.OTHalfLength)) >= 1) && math.Abs(float64(lead)) >= 2 {
+			g.finish(winnerOutcome(lead > 0))
+			//CAUTION WITH THE NEXT PART, THIS DEFINES THE MAXIMUM NUMBER OF ROUNDS IN OVERTIME
+		} else if g.OTcounter >= rules.MaxOvertimes && played == rules.HalfLength*2+g.OTcounter*rules.OTHalfLength*2 {
+			g.resolveTie()
 		}
-		//CAUTION WITH THE NEXT PART, THIS DEFINES THE MAXIMUM NUMBER OF ROUNDS IN OVERTIME
-	} else if g.OTcounter > 50 {
-		// If the game has gone on for too long, end it
-		g.GameinProgress = false
-		g.Is_T1_Winner = g.rng.Intn(2) == 0 // Randomly decide a winner
 	}
 }
 
+// resolveTie decides a game that is tied after the last overtime (or regulation without
+// overtime) according to GameRules.TieResolution
+func (g *Game) resolveTie() {
+	switch g.GameRules.TieResolution {
+	case TieDraw:
+		g.finish(OutcomeDraw)
+	case TieSuddenDeath:
+		g.suddenDeath = true
+		g.OT = true
+	default:
+		g.finish(winnerOutcome(g.rng.Intn(2) == 0)) // Randomly decide a winner
+	}
+}
+
+// finish ends the game with outcome
+func (g *Game) finish(outcome Outcome) {
+	g.GameinProgress = false
+	g.Outcome = outcome
+}
+
 func (g *Game) UpdateScore(winner bool) {
-	g.Is_T1_Winner = winner
 	if winner {
 		g.Score[0]++
 	} else {
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGameFinishedOvertime(t *testing.T) {
+	majority := getDefaultRules() // MR15, MR3 overtimes won by majority
+	lead2 := getDefaultRules()
+	lead2.OTWinCondition = OTWinLead2
+	lead2.MaxOvertimes = 2
+	lead2.TieResolution = TieDraw
+
+	tests := []struct {
+		name  string
+		rules GameRules
+		score [2]int
+		ot    int     // Overtime being played, 0 in regulation
+		want  Outcome // OutcomeUndecided: the game goes on
+	}{
+		{"regulation win", majority, [2]int{16, 10}, 0, OutcomeTeam1Win},
+		{"regulation loss", majority, [2]int{3, 16}, 0, OutcomeTeam2Win},
+		{"regulation running", majority, [2]int{15, 14}, 0, OutcomeUndecided},
+		{"tied after regulation", majority, [2]int{15, 15}, 0, OutcomeUndecided},
+		{"overtime running", majority, [2]int{18, 17}, 1, OutcomeUndecided},
+		{"overtime won", majority, [2]int{19, 17}, 1, OutcomeTeam1Win},
+		{"overtime tied", majority, [2]int{18, 18}, 1, OutcomeUndecided},
+		{"second overtime lost", majority, [2]int{20, 22}, 2, OutcomeTeam2Win},
+		{"lead2 running", lead2, [2]int{16, 15}, 1, OutcomeUndecided},
+		{"lead2 won mid-period", lead2, [2]int{17, 15}, 1, OutcomeTeam1Win},
+		{"lead2 lost", lead2, [2]int{19, 21}, 2, OutcomeTeam2Win},
+		{"lead2 last overtime tied", lead2, [2]int{21, 21}, 2, OutcomeDraw},
+	}
+	for _, tt := range tests {
+		g := &Game{
+			GameRules:      tt.rules,
+			GameinProgress: true,
+			Score:          tt.score,
+			CurrentRound:   tt.score[0] + tt.score[1] + 1,
+			OT:             tt.ot > 0,
+			OTcounter:      tt.ot,
+			rng:            rand.New(rand.NewSource(1)),
+		}
+		g.GameFinished()
+		if g.Outcome != tt.want || g.GameinProgress != (tt.want == OutcomeUndecided) {
+			t.Errorf("%s (%d-%d): outcome %v, in progress %v; want %v", tt.name, tt.score[0], tt.score[1], g.Outcome, g.GameinProgress, tt.want)
+		}
+	}
+}
+
+func TestResolveTie(t *testing.T) {
+	// Without overtime, 15-15 after regulation goes to the tie resolution
+	tied := func(resolution string, seed int64) *Game {
+		rules := getDefaultRules()
+		rules.MaxOvertimes = 0
+		rules.TieResolution = resolution
+		g := &Game{GameRules: rules, GameinProgress: true, Score: [2]int{15, 15}, CurrentRound: 31, rng: rand.New(rand.NewSource(seed))}
+		g.GameFinished()
+		return g
+	}
+
+	if g := tied(TieDraw, 1); g.GameinProgress || g.Outcome != OutcomeDraw {
+		t.Errorf("%s: in progress %v, outcome %v; want a finished draw", TieDraw, g.GameinProgress, g.Outcome)
+	}
+
+	winners := map[Outcome]int{}
+	for seed := int64(0); seed < 40; seed++ {
+		g := tied(TieCoinFlip, seed)
+		if g.GameinProgress || g.Outcome == OutcomeDraw || g.Outcome == OutcomeUndecided {
+			t.Fatalf("%s: in progress %v, outcome %v; want a winner", TieCoinFlip, g.GameinProgress, g.Outcome)
+		}
+		winners[g.Outcome]++
+	}
+	if winners[OutcomeTeam1Win] == 0 || winners[OutcomeTeam2Win] == 0 {
+		t.Errorf("%s: winners over 40 seeds %v, want both teams", TieCoinFlip, winners)
+	}
+
+	// Sudden death plays one more round, whose winner wins the game
+	g := tied(TieSuddenDeath, 1)
+	if !g.GameinProgress || !g.suddenDeath || !g.OT {
+		t.Fatalf("%s: in progress %v, sudden death %v, OT %v; want a deciding round", TieSuddenDeath, g.GameinProgress, g.suddenDeath, g.OT)
+	}
+	g.Rounds = append(g.Rounds, Round{IsT1WinnerTeam: false})
+	g.UpdateScore(false)
+	g.CurrentRound++
+	g.GameFinished()
+	if g.GameinProgress || g.Outcome != OutcomeTeam2Win {
+		t.Errorf("%s: in progress %v, outcome %v at %v after the deciding round; want a team 2 win", TieSuddenDeath, g.GameinProgress, g.Outcome, g.Score)
+	}
+}
+
+func TestOvertimeCapLimitsRounds(t *testing.T) {
+	if err := LoadDistributions("../../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	rules := getDefaultRules()
+	rules.MaxOvertimes = 1
+	rules.TieResolution = TieDraw
+	maxRounds := rules.HalfLength*2 + rules.MaxOvertimes*rules.OTHalfLength*2
+
+	overtimes := 0
+	for seed := int64(1); seed <= 300; seed++ {
+		g := NewSeededGame("cap", "A", "all_in", "B", "all_in", rules, seed)
+		g.Start()
+		if g.GameinProgress || len(g.Rounds) > maxRounds || g.OTcounter > rules.MaxOvertimes {
+			t.Fatalf("seed %d: in progress %v after %d rounds and %d overtimes, cap is %d rounds", seed, g.GameinProgress, len(g.Rounds), g.OTcounter, maxRounds)
+		}
+		if g.Outcome == OutcomeDraw && (len(g.Rounds) != maxRounds || g.Score[0] != g.Score[1]) {
+			t.Errorf("seed %d: draw at %v after %d rounds", seed, g.Score, len(g.Rounds))
+		}
+		overtimes += g.OTcounter
+	}
+	if overtimes == 0 {
+		t.Error("no game of 300 went to overtime")
+	}
+}
+
+func TestGameFinishedAllowDraw(t *testing.T) {
+	competitive, _ := ProfileRules("cs2_mr12") // 12-12 is a draw
+	premier, _ := ProfileRules("cs2_premier")  // 12-12 goes to overtime
+
+	tests := []struct {
+		rules GameRules
+		score [2]int
+		want  Outcome
+	}{
+		{competitive, [2]int{13, 11}, OutcomeTeam1Win},
+		{competitive, [2]int{0, 13}, OutcomeTeam2Win},
+		{competitive, [2]int{12, 11}, OutcomeUndecided},
+		{competitive, [2]int{12, 12}, OutcomeDraw},
+		{premier, [2]int{12, 12}, OutcomeUndecided},
+		{premier, [2]int{13, 12}, OutcomeUndecided},
+	}
+	for _, tt := range tests {
+		g := &Game{GameRules: tt.rules, GameinProgress: true, Score: tt.score, CurrentRound: tt.score[0] + tt.score[1] + 1, rng: rand.New(rand.NewSource(1))}
+		g.GameFinished()
+		if g.Outcome != tt.want {
+			t.Errorf("%s %d-%d: outcome %v, want %v", tt.rules.Profile, tt.score[0], tt.score[1], g.Outcome, tt.want)
+		}
+	}
+}
+
+func TestOutcomeText(t *testing.T) {
+	for _, o := range []Outcome{OutcomeUndecided, OutcomeTeam1Win, OutcomeTeam2Win, OutcomeDraw} {
+		text, err := o.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d): %v", o, err)
+		}
+		var back Outcome
+		if err := back.UnmarshalText(text); err != nil || back != o {
+			t.Errorf("%s: round trip gave %v (%v)", text, back, err)
+		}
+	}
+	var o Outcome
+	if err := o.UnmarshalText([]byte("tie")); err == nil {
+		t.Error("UnmarshalText accepted 'tie'")
+	}
+	if !OutcomeDraw.IsDraw() || OutcomeDraw.Team1Won() || OutcomeDraw.Team2Won() {
+		t.Error("a draw is a win of a team")
+	}
+}
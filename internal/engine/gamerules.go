@@ -1,35 +1,207 @@
 package engine
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"dbg_abm/internal/configfile"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"sort"
+	"strings"
 )
 
 type GameRules struct {
-	DefaultEquipment                float64    `json:"defaultEquipment"`              // Default equipment
-	OTFunds                         float64    `json:"otFunds"`                       // Overtime funds
-	OTEquipment                     float64    `json:"otEquipment"`                   // Overtime equipment
-	StartingFunds                   float64    `json:"startingFunds"`                 // Starting funds for teams
-	HalfLength                      int        `json:"halfLength"`                    // Length of a half in rounds
-	OTHalfLength                    int        `json:"otHalfLength"`                  // Length of overtime half in rounds
-	MaxFunds                        float64    `json:"maxFunds"`                      // Maximum funds allowed for a team
-	LossBonusCalc                   bool       `json:"lossBonusCalc"`                 // true: loss bonus reduced 1 after each win, false: resets after each win
-	WithSaves                       bool       `json:"withSaves"`                     // true: teams can save weapons between rounds
-	LossBonus                       []float64  `json:"lossBonus"`                     // Custom loss bonus per round (if empty, use default logic)
-	RoundOutcomeReward              [4]float64 `json:"roundOutcomeReward"`            // Custom rewards for round outcomes
-	EliminationReward               float64    `json:"eliminationReward"`             // Reward for eliminating a opponent
-	BombplantRewardall              float64    `json:"bombplantRewardall"`            // Reward for planting the bomb for all players
-	BombplantReward                 float64    `json:"bombplantReward"`               // Reward for planting the bomb
-	BombdefuseReward                float64    `json:"bombdefuseReward"`              // Reward for defusing the bomb
-	AdditionalReward_CT_Elimination float64    `json:"additionalCTEliminationReward"` // Additional reward for CT team for eliminations
-	AdditionalReward_T_Elimination  float64    `json:"additionalTEliminationReward"`  // Additional reward for T team for eliminations
-	Custom_CSF_r_value              float64    `json:"customRValue"`                  // Custom r value the CSF default is to use from the probabilities.json
+	DefaultEquipment                float64        `json:"defaultEquipment"`              // Default equipment
+	OTFunds                         float64        `json:"otFunds"`                       // Overtime funds
+	OTEquipment                     float64        `json:"otEquipment"`                   // Overtime equipment
+	StartingFunds                   float64        `json:"startingFunds"`                 // Starting funds for teams
+	HalfLength                      int            `json:"halfLength"`                    // Length of a half in rounds
+	OTHalfLength                    int            `json:"otHalfLength"`                  // Length of overtime half in rounds
+	MaxFunds                        float64        `json:"maxFunds"`                      // Maximum funds allowed for a team
+	LossBonusCalc                   bool           `json:"lossBonusCalc"`                 // true: loss bonus reduced 1 after each win, false: resets after each win
+	WithSaves                       bool           `json:"withSaves"`                     // true: teams can save weapons between rounds
+	LossBonus                       []float64      `json:"lossBonus"`                     // Custom loss bonus per round (if empty, use default logic)
+	RoundOutcomeReward              [4]float64     `json:"roundOutcomeReward"`            // Custom rewards for round outcomes
+	EliminationReward               float64        `json:"eliminationReward"`             // Reward for eliminating a opponent
+	BombplantRewardall              float64        `json:"bombplantRewardall"`            // Reward for planting the bomb for all players
+	BombplantReward                 float64        `json:"bombplantReward"`               // Reward for planting the bomb
+	BombdefuseReward                float64        `json:"bombdefuseReward"`              // Reward for defusing the bomb
+	AdditionalReward_CT_Elimination float64        `json:"additionalCTEliminationReward"` // Additional reward for CT team for eliminations
+	AdditionalReward_T_Elimination  float64        `json:"additionalTEliminationReward"`  // Additional reward for T team for eliminations
+	Custom_CSF_r_value              float64        `json:"customRValue"`                  // Custom r value the CSF default is to use from the probabilities.json
+	AllowDraw                       bool           `json:"allowDraw"`                     // true: a game tied after regulation ends as a draw (no overtime)
+	MaxOvertimes                    int            `json:"maxOvertimes"`                  // Overtime periods before the tie resolution decides (0: no overtime)
+	OTWinCondition                  string         `json:"otWinCondition"`                // How an overtime is won (OTWinMajority or OTWinLead2)
+	TieResolution                   string         `json:"tieResolution"`                 // What decides a game still tied after the last overtime (TieCoinFlip, TieDraw or TieSuddenDeath)
+	CT                              *SideRules     `json:"ct,omitempty"`                  // Economy values of the CT side that replace the shared ones
+	T                               *SideRules     `json:"t,omitempty"`                   // Economy values of the T side that replace the shared ones
+	Timeouts                        *TimeoutRules  `json:"timeouts,omitempty"`            // Tactical timeouts (nil: no timeouts)
+	Momentum                        *MomentumRules `json:"momentum,omitempty"`            // Momentum modifiers of the CSF input (nil: equipment alone decides)
+	Profile                         string         `json:"profile,omitempty"`             // Named profile the rules are based on (see RuleProfiles)
 }
 
-// getDefaultRules returns the default game rules configuration
+// SideRules are the economy values one side can have apart from the other (e.g. a different
+// loss bonus ladder for the CT side). Fields left out use the shared value of GameRules.
+// Plant and defuse rewards need no side variant, they are T and CT rewards already.
+type SideRules struct {
+	StartingFunds     *float64  `json:"startingFunds,omitempty"`
+	MaxFunds          *float64  `json:"maxFunds,omitempty"`
+	DefaultEquipment  *float64  `json:"defaultEquipment,omitempty"`
+	OTFunds           *float64  `json:"otFunds,omitempty"`
+	OTEquipment       *float64  `json:"otEquipment,omitempty"`
+	LossBonus         []float64 `json:"lossBonus,omitempty"`
+	EliminationReward *float64  `json:"eliminationReward,omitempty"`
+}
+
+// SideEconomy are the economy values a side plays with, the shared values of GameRules
+// with the overrides of its SideRules applied
+type SideEconomy struct {
+	StartingFunds     float64
+	MaxFunds          float64
+	DefaultEquipment  float64
+	OTFunds           float64
+	OTEquipment       float64
+	LossBonus         []float64
+	EliminationReward float64
+}
+
+// Economy returns the economy values of the CT side (ct true) or the T side. Teams switch
+// sides at halftime, so with side-specific values a team's economy differs per half.
+func (r GameRules) Economy(ct bool) SideEconomy {
+	e := SideEconomy{
+		StartingFunds:     r.StartingFunds,
+		MaxFunds:          r.MaxFunds,
+		DefaultEquipment:  r.DefaultEquipment,
+		OTFunds:           r.OTFunds,
+		OTEquipment:       r.OTEquipment,
+		LossBonus:         r.LossBonus,
+		EliminationReward: r.EliminationReward,
+	}
+	side := r.T
+	if ct {
+		side = r.CT
+	}
+	if side == nil {
+		return e
+	}
+	override := func(value *float64, shared *float64) {
+		if value != nil {
+			*shared = *value
+		}
+	}
+	override(side.StartingFunds, &e.StartingFunds)
+	override(side.MaxFunds, &e.MaxFunds)
+	override(side.DefaultEquipment, &e.DefaultEquipment)
+	override(side.OTFunds, &e.OTFunds)
+	override(side.OTEquipment, &e.OTEquipment)
+	override(side.EliminationReward, &e.EliminationReward)
+	if len(side.LossBonus) > 0 {
+		e.LossBonus = side.LossBonus
+	}
+	return e
+}
+
+// TimeoutRules define the timeouts a team can call before the buy phase of a round and what
+// a timeout does to the round that follows it
+type TimeoutRules struct {
+	PerHalf         int     `json:"perHalf"`         // Timeouts of each team per regulation half
+	PerOTHalf       int     `json:"perOTHalf"`       // Timeouts of each team per overtime half
+	Effect          string  `json:"effect"`          // TimeoutCSFShift or TimeoutVariance
+	CSFShift        float64 `json:"csfShift"`        // csf_shift: added to the round win probability of the calling team
+	RFactor         float64 `json:"rFactor"`         // variance: factor on the CSF r value of the round, applied once per round (<1: more upsets)
+	CallAfterLosses int     `json:"callAfterLosses"` // Teams whose strategy has no timeout policy call one after this many lost rounds in a row (0: never)
+}
+
+// Timeout effects
+const (
+	TimeoutCSFShift = "csf_shift" // The timeout breaks the opponent's momentum: the calling team's win probability rises by csfShift
+	TimeoutVariance = "variance"  // The timeout changes how decisive equipment is: the CSF r value is multiplied by rFactor
+)
+
+// CSFRValue returns the CSF r value games under the rules are played with: the custom r
+// value of the rules, or the value of the loaded distributions if none is set (negative).
+// It is read per game, so games with different rules can run in one process.
+func (r GameRules) CSFRValue() float64 {
+	if r.Custom_CSF_r_value >= 0 {
+		return r.Custom_CSF_r_value
+	}
+	return GetCSFRValue()
+}
+
+// TimeoutsPerHalf returns the timeouts each team gets for a regulation or an overtime half
+func (r GameRules) TimeoutsPerHalf(ot bool) int {
+	switch {
+	case r.Timeouts == nil:
+		return 0
+	case ot:
+		return r.Timeouts.PerOTHalf
+	}
+	return r.Timeouts.PerHalf
+}
+
+// MomentumRules are modifiers of the CSF input beyond equipment: each value changes the
+// multiplier on a team's equipment (e.g. 0.02 raises it by 2%), so their effect can be
+// compared with the economy. The modifiers of a team add up; the multiplier is at least 0.
+// The values are configuration only, they have to be estimated from match data elsewhere.
+type MomentumRules struct {
+	WinStreak        float64 `json:"winStreak"`        // Per round the team has won in a row
+	LossStreak       float64 `json:"lossStreak"`       // Per round the team has lost in a row (negative: tilt)
+	MaxStreak        int     `json:"maxStreak"`        // Streak length after which the streak modifiers stop growing (0: no limit)
+	MatchPoint       float64 `json:"matchPoint"`       // For a team that wins the game by winning the round
+	FacingMatchPoint float64 `json:"facingMatchPoint"` // For a team that loses the game by losing the round
+	CTSide           float64 `json:"ctSide"`           // For the CT side (negative: T-sided)
+}
+
+// Overtime win conditions
+const (
+	OTWinMajority = "majority" // An overtime period is won by winning more than half of its rounds
+	OTWinLead2    = "lead2"    // Overtime continues until a team leads by two rounds
+)
+
+// Resolutions of a game that is still tied when no overtime is left
+const (
+	TieCoinFlip    = "coin_flip"    // A random winner
+	TieDraw        = "draw"         // The game ends as a draw
+	TieSuddenDeath = "sudden_death" // One more round decides
+)
+
+// DefaultRulesProfile is the profile used without -g
+const DefaultRulesProfile = "csgo_mr15"
+
+// ruleProfiles are the built-in named rule sets
+var ruleProfiles = map[string]func() GameRules{
+	"csgo_mr15":   getDefaultRules,
+	"csgo_mr12":   csgoMR12Rules,
+	"cs2_mr12":    cs2MR12Rules,
+	"cs2_premier": cs2PremierRules,
+}
+
+// RuleProfiles returns the names of the built-in rule profiles
+func RuleProfiles() []string {
+	names := make([]string, 0, len(ruleProfiles))
+	for name := range ruleProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileRules returns the rules of a built-in profile
+func ProfileRules(name string) (GameRules, error) {
+	profile, ok := ruleProfiles[name]
+	if !ok {
+		return GameRules{}, fmt.Errorf("unknown game rules profile '%s' (available: %s)", name, strings.Join(RuleProfiles(), ", "))
+	}
+	return profile(), nil
+}
+
+// getDefaultRules returns the default game rules configuration (CS:GO, MR15)
 func getDefaultRules() GameRules {
 	return GameRules{
+		Profile:                         "csgo_mr15",
 		DefaultEquipment:                200,
 		OTFunds:                         10000,
 		OTEquipment:                     200,
@@ -47,212 +219,279 @@ func getDefaultRules() GameRules {
 		BombdefuseReward:                300,
 		AdditionalReward_CT_Elimination: 0, //introduced in cs2, 50 per elimination for all CT players
 		AdditionalReward_T_Elimination:  0,
-		Custom_CSF_r_value:              -1, // Default value of 0 means "use default from probabilities.json"
+		Custom_CSF_r_value:              -1, // Negative: use the r value from probabilities.json
+		MaxOvertimes:                    50, // Prevents endless games, at most 15 * 2 + 50 * 6 = 330 rounds
+		OTWinCondition:                  OTWinMajority,
+		TieResolution:                   TieCoinFlip,
 	}
 }
 
-// validateGameRulesStrict performs strict validation - returns false if any value is invalid
-func validateGameRulesStrict(rules GameRules) bool {
-	// Validate economic values are positive
-	if rules.DefaultEquipment < 0 {
-		fmt.Println("Default equipment must be non-negative")
-		return false
-	}
-	if rules.StartingFunds < 0 {
-		fmt.Println("Starting funds must be non-negative")
-		return false
-	}
-	if rules.OTFunds < 0 {
-		fmt.Println("Overtime funds must be non-negative")
-		return false
-	}
+// csgoMR12Rules are the CS:GO economy played to 13 (MR12)
+func csgoMR12Rules() GameRules {
+	rules := getDefaultRules()
+	rules.Profile = "csgo_mr12"
+	rules.HalfLength = 12
+	return rules
+}
 
-	if rules.OTEquipment < 0 {
-		fmt.Println("Overtime equipment must be non-negative")
-		return false
-	}
+// cs2MR12Rules are the CS2 competitive rules: MR12 where 12-12 is a draw, $600 plant bonus
+// for the T team, $50 per elimination for every CT player and $12,500 at the start of an
+// overtime (if overtime is enabled with allowDraw false)
+func cs2MR12Rules() GameRules {
+	rules := getDefaultRules()
+	rules.Profile = "cs2_mr12"
+	rules.AllowDraw = true
+	rules.HalfLength = 12
+	rules.OTFunds = 12500
+	rules.BombplantRewardall = 600
+	rules.AdditionalReward_CT_Elimination = 50
+	return rules
+}
 
-	if rules.MaxFunds < 0 {
-		fmt.Println("Maximum funds must be non-negative")
-		return false
-	}
+// cs2PremierRules are the CS2 Premier rules, the competitive economy with MR3 overtimes
+// instead of draws
+func cs2PremierRules() GameRules {
+	rules := cs2MR12Rules()
+	rules.Profile = "cs2_premier"
+	rules.AllowDraw = false
+	return rules
+}
 
-	// Validate round counts are reasonable
-	if rules.HalfLength <= 0 {
-		fmt.Println("Half length must be positive")
-		return false
-	}
-	if rules.OTHalfLength <= 0 {
-		fmt.Println("Overtime half length must be positive")
-		return false
+// Validate checks every value of the rules and returns all problems, each prefixed with
+// the JSON path of its field (e.g. "lossBonus[2]: must be non-negative")
+func (r GameRules) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: "+format, append([]any{field}, args...)...))
+		}
 	}
 
-	if rules.LossBonusCalc != true && rules.LossBonusCalc != false {
-		fmt.Println("Loss bonus calculation flag must be true or false")
-		return false
-	}
+	// Economic values must be non-negative
+	check(r.DefaultEquipment >= 0, "defaultEquipment", "must be non-negative")
+	check(r.StartingFunds >= 0, "startingFunds", "must be non-negative")
+	check(r.OTFunds >= 0, "otFunds", "must be non-negative")
+	check(r.OTEquipment >= 0, "otEquipment", "must be non-negative")
+	check(r.MaxFunds >= 0, "maxFunds", "must be non-negative")
 
-	if rules.WithSaves != true && rules.WithSaves != false {
-		fmt.Println("With saves flag must be true or false")
-		return false
-	}
+	// Round counts
+	check(r.HalfLength > 0, "halfLength", "must be positive")
+	check(r.OTHalfLength > 0, "otHalfLength", "must be positive")
+	check(r.MaxOvertimes >= 0, "maxOvertimes", "must be non-negative")
+	check(r.OTWinCondition == OTWinMajority || r.OTWinCondition == OTWinLead2,
+		"otWinCondition", "must be '%s' or '%s', got '%s'", OTWinMajority, OTWinLead2, r.OTWinCondition)
+	check(r.TieResolution == TieCoinFlip || r.TieResolution == TieDraw || r.TieResolution == TieSuddenDeath,
+		"tieResolution", "must be '%s', '%s' or '%s', got '%s'", TieCoinFlip, TieDraw, TieSuddenDeath, r.TieResolution)
 
-	// Validate reward values are non-negative
-	if rules.EliminationReward < 0 {
-		fmt.Println("Elimination reward must be non-negative")
-		return false
-	}
-	if rules.BombplantRewardall < 0 {
-		fmt.Println("Bomb plant reward (all) must be non-negative")
-		return false
-	}
-	if rules.BombplantReward < 0 {
-		fmt.Println("Bomb plant reward must be non-negative")
-		return false
-	}
-	if rules.BombdefuseReward < 0 {
-		fmt.Println("Bomb defuse reward must be non-negative")
-		return false
+	// Rewards must be non-negative
+	check(r.EliminationReward >= 0, "eliminationReward", "must be non-negative")
+	check(r.BombplantRewardall >= 0, "bombplantRewardall", "must be non-negative")
+	check(r.BombplantReward >= 0, "bombplantReward", "must be non-negative")
+	check(r.BombdefuseReward >= 0, "bombdefuseReward", "must be non-negative")
+	check(r.AdditionalReward_CT_Elimination >= 0, "additionalCTEliminationReward", "must be non-negative")
+	check(r.AdditionalReward_T_Elimination >= 0, "additionalTEliminationReward", "must be non-negative")
+	for i, reward := range r.RoundOutcomeReward {
+		check(reward >= 0, fmt.Sprintf("roundOutcomeReward[%d]", i), "must be non-negative")
 	}
-	if rules.AdditionalReward_CT_Elimination < 0 {
-		fmt.Println("Additional CT elimination reward must be non-negative")
-		return false
-	}
-	if rules.AdditionalReward_T_Elimination < 0 {
-		fmt.Println("Additional T elimination reward must be non-negative")
-		return false
+	for i, bonus := range r.LossBonus {
+		check(bonus >= 0, fmt.Sprintf("lossBonus[%d]", i), "must be non-negative")
 	}
 
-	// Validate RoundOutcomeReward values
-	for i, reward := range rules.RoundOutcomeReward {
-		if reward < 0 {
-			fmt.Printf("Round outcome reward [%d] must be non-negative\n", i)
-			return false
+	// Side-specific values follow the rules of the shared ones
+	for _, side := range []struct {
+		name  string
+		rules *SideRules
+	}{{"ct", r.CT}, {"t", r.T}} {
+		s := side.rules
+		if s == nil {
+			continue
 		}
-	}
-
-	// Validate LossBonus values if provided
-	for i, bonus := range rules.LossBonus {
-		if bonus < 0 {
-			fmt.Printf("Loss bonus [%d] must be non-negative\n", i)
-			return false
+		for _, v := range []struct {
+			field string
+			value *float64
+		}{
+			{"startingFunds", s.StartingFunds},
+			{"maxFunds", s.MaxFunds},
+			{"defaultEquipment", s.DefaultEquipment},
+			{"otFunds", s.OTFunds},
+			{"otEquipment", s.OTEquipment},
+			{"eliminationReward", s.EliminationReward},
+		} {
+			check(v.value == nil || *v.value >= 0, side.name+"."+v.field, "must be non-negative")
+		}
+		for i, bonus := range s.LossBonus {
+			check(bonus >= 0, fmt.Sprintf("%s.lossBonus[%d]", side.name, i), "must be non-negative")
 		}
 	}
 
-	return true
-}
-
-func NewGameRules(pathtoFile string) (GameRules, bool) {
-	// Start with default rules
-	rules := getDefaultRules()
+	if t := r.Timeouts; t != nil {
+		check(t.PerHalf >= 0, "timeouts.perHalf", "must be non-negative")
+		check(t.PerOTHalf >= 0, "timeouts.perOTHalf", "must be non-negative")
+		check(t.CallAfterLosses >= 0, "timeouts.callAfterLosses", "must be non-negative")
+		check(t.Effect == TimeoutCSFShift || t.Effect == TimeoutVariance,
+			"timeouts.effect", "must be '%s' or '%s', got '%s'", TimeoutCSFShift, TimeoutVariance, t.Effect)
+		check(t.CSFShift >= 0 && t.CSFShift <= 1, "timeouts.csfShift", "must be between 0 and 1")
+		check(t.Effect != TimeoutVariance || t.RFactor > 0, "timeouts.rFactor", "must be positive for the '%s' effect", TimeoutVariance)
+	}
 
-	if pathtoFile != "" && pathtoFile != "default" {
-		// Attempt to read game rules from a JSON file
-		file, err := os.Open(pathtoFile)
-		if err != nil {
-			fmt.Printf("Warning: Could not open game rules file '%s': %v. Using defaults.\n", pathtoFile, err)
-			return rules, false
+	if m := r.Momentum; m != nil {
+		check(m.MaxStreak >= 0, "momentum.maxStreak", "must be non-negative")
+		for _, v := range []struct {
+			field string
+			value float64
+		}{
+			{"winStreak", m.WinStreak},
+			{"lossStreak", m.LossStreak},
+			{"matchPoint", m.MatchPoint},
+			{"facingMatchPoint", m.FacingMatchPoint},
+			{"ctSide", m.CTSide},
+		} {
+			check(v.value > -1, "momentum."+v.field, "must be greater than -1")
 		}
-		defer file.Close()
+	}
 
-		// Create a temporary rules struct for JSON parsing
-		var jsonRules GameRules
-		decoder := json.NewDecoder(file)
+	return errors.Join(errs...)
+}
 
-		if err := decoder.Decode(&jsonRules); err != nil {
-			fmt.Printf("Warning: Could not parse game rules file '%s': %v. Using defaults.\n", pathtoFile, err)
-			return rules, false
-		}
+// NewGameRules resolves the rules selected with -g: "" or "default" (the csgo_mr15
+// profile), "profile:<name>", a rules file (JSON, YAML or TOML, by extension), or
+// "profile:<name>+<file>" to layer a file on a profile. A file may name its base profile
+// itself with a "profile" key; fields present in the file replace the profile's values,
+// including explicit zeros and false, and fields the file leaves out keep them. Unknown
+// keys, null values and invalid values are errors. The returned warnings are for the user
+// (e.g. a file based on another profile than the one on the command line).
+func NewGameRules(spec string) (GameRules, []string, error) {
+	if spec == "" || spec == "default" {
+		return getDefaultRules(), nil, nil
+	}
 
-		// Merge JSON values with defaults (only overwrite non-zero values)
-		candidateRules := rules // Start with defaults
+	profile, pathtoFile := "", spec
+	if name, ok := strings.CutPrefix(spec, "profile:"); ok {
+		profile, pathtoFile, _ = strings.Cut(name, "+")
+	}
 
-		if jsonRules.DefaultEquipment > 0 {
-			candidateRules.DefaultEquipment = jsonRules.DefaultEquipment
-		}
-		if jsonRules.OTEquipment > 0 {
-			candidateRules.OTEquipment = jsonRules.OTEquipment
-		}
-		if jsonRules.OTFunds > 0 {
-			candidateRules.OTFunds = jsonRules.OTFunds
-		}
-		if jsonRules.StartingFunds > 0 {
-			candidateRules.StartingFunds = jsonRules.StartingFunds
-		}
-		if jsonRules.HalfLength > 0 {
-			candidateRules.HalfLength = jsonRules.HalfLength
+	var data, lines []byte
+	var warnings []string
+	if pathtoFile != "" {
+		var converted bool
+		var err error
+		if data, converted, err = configfile.ReadJSON(pathtoFile); err != nil {
+			if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+				return GameRules{}, nil, fmt.Errorf("could not open game rules file '%s': %w", pathtoFile, err)
+			}
+			return GameRules{}, nil, err
 		}
-		if jsonRules.OTHalfLength > 0 {
-			candidateRules.OTHalfLength = jsonRules.OTHalfLength
+		if !converted {
+			lines = data // Decoding error offsets are positions in the file
 		}
-		if jsonRules.MaxFunds > 0 {
-			candidateRules.MaxFunds = jsonRules.MaxFunds
+		// The file may name the profile it builds on
+		var base struct {
+			Profile string `json:"profile"`
 		}
-		if jsonRules.LossBonusCalc == true || jsonRules.LossBonusCalc == false {
-			candidateRules.LossBonusCalc = jsonRules.LossBonusCalc
+		if err := json.Unmarshal(data, &base); err != nil {
+			return GameRules{}, nil, rulesFileError(pathtoFile, lines, err)
 		}
-		if jsonRules.WithSaves == true || jsonRules.WithSaves == false {
-			candidateRules.WithSaves = jsonRules.WithSaves
+		switch {
+		case profile == "":
+			profile = base.Profile
+		case base.Profile != "" && base.Profile != profile:
+			warnings = append(warnings, fmt.Sprintf("game rules file '%s' is based on profile '%s', using '%s' from the command line", pathtoFile, base.Profile, profile))
 		}
+	}
+	if profile == "" {
+		profile = DefaultRulesProfile
+	}
 
-		// Load reward values (can be 0, so check if they were explicitly set)
-		// For rewards, we allow 0 values, so we only skip if not present in JSON
-		if jsonRules.EliminationReward >= 0 {
-			candidateRules.EliminationReward = jsonRules.EliminationReward
-		}
-		if jsonRules.BombplantRewardall >= 0 {
-			candidateRules.BombplantRewardall = jsonRules.BombplantRewardall
-		}
-		if jsonRules.BombplantReward >= 0 {
-			candidateRules.BombplantReward = jsonRules.BombplantReward
-		}
-		if jsonRules.BombdefuseReward >= 0 {
-			candidateRules.BombdefuseReward = jsonRules.BombdefuseReward
-		}
-		if jsonRules.AdditionalReward_CT_Elimination >= 0 {
-			candidateRules.AdditionalReward_CT_Elimination = jsonRules.AdditionalReward_CT_Elimination
-		}
-		if jsonRules.AdditionalReward_T_Elimination >= 0 {
-			candidateRules.AdditionalReward_T_Elimination = jsonRules.AdditionalReward_T_Elimination
-		}
+	rules, err := ProfileRules(profile)
+	if err != nil {
+		return GameRules{}, nil, err
+	}
 
-		if jsonRules.Custom_CSF_r_value >= 0 {
-			candidateRules.Custom_CSF_r_value = jsonRules.Custom_CSF_r_value
+	// Values present in the file replace the profile's; LossBonus is replaced as a whole
+	if data != nil {
+		if err := decodeRules(data, &rules); err != nil {
+			return GameRules{}, nil, rulesFileError(pathtoFile, lines, err)
 		}
+		rules.Profile = profile
+	}
 
-		// Load RoundOutcomeReward array (check if any values are set)
-		hasRoundRewards := false
-		for _, val := range jsonRules.RoundOutcomeReward {
-			if val != 0 {
-				hasRoundRewards = true
-				break
-			}
+	if err := rules.Validate(); err != nil {
+		return GameRules{}, nil, fmt.Errorf("invalid game rules '%s':\n%w", spec, err)
+	}
+	return rules, warnings, nil
+}
+
+// decodeRules decodes a rules file over rules. Only keys present in the file are set;
+// unknown keys, null values and trailing data are rejected.
+func decodeRules(data []byte, rules *GameRules) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, value := range fields {
+		if string(value) == "null" {
+			return fmt.Errorf("%s: null is not a valid value, leave the key out to keep the profile's value", name)
 		}
-		if hasRoundRewards {
-			candidateRules.RoundOutcomeReward = jsonRules.RoundOutcomeReward
+		if name != "ct" && name != "t" && name != "timeouts" && name != "momentum" {
+			continue
 		}
-
-		// Load LossBonus array (only if not empty)
-		if len(jsonRules.LossBonus) > 0 {
-			candidateRules.LossBonus = jsonRules.LossBonus
+		// Nested blocks are checked by the decoder below if they are no object
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(value, &nested) != nil {
+			continue
 		}
-
-		// Load Custom_CSF_r_value (can be 0 to use default from probabilities.json)
-		// Negative values are invalid, but 0 means "use default"
-		if jsonRules.Custom_CSF_r_value >= 0 {
-			candidateRules.Custom_CSF_r_value = jsonRules.Custom_CSF_r_value
+		for field, v := range nested {
+			if string(v) == "null" {
+				return fmt.Errorf("%s.%s: null is not a valid value, leave the key out instead", name, field)
+			}
 		}
+	}
 
-		// Strict validation - if ANY value fails, use all defaults
-		if !validateGameRulesStrict(candidateRules) {
-			fmt.Printf("Warning: Game rules in '%s' failed validation. Using all default values.\n", pathtoFile)
-			return rules, false // Return original defaults
-		}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(rules); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after the rules object")
+	}
+	return nil
+}
+
+// rulesFileError names the file, the line (if data holds the file as JSON) and the field
+// of a decoding error
+func rulesFileError(path string, data []byte, err error) error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%s: %s: expected %s, got %s", position(path, data, typeErr.Offset), typeErr.Field, typeErr.Type, typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%s: %v", position(path, data, syntaxErr.Offset), err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return fmt.Errorf("%s: unknown field %s (misspelled? see game_rules.json of a run for all fields)", path, field)
+	}
+	return fmt.Errorf("%s: %w", path, err)
+}
 
-		// All validations passed, use the candidate rules
-		rules = candidateRules
-		return rules, true
+// position returns path:line for a byte offset in data, or just path without data
+func position(path string, data []byte, offset int64) string {
+	if data == nil {
+		return path
 	}
+	line := bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+	return fmt.Sprintf("%s:%d", path, line)
+}
 
-	return rules, false
+// Hash returns a SHA-256 fingerprint of the rules, used to check that a resumed
+// run still plays under the same rules
+func (r GameRules) Hash() string {
+	r.Profile = "" // Same values, same rules
+	data, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewGameRulesStrictDecoding(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		errs    []string // Substrings of the error, none: the file loads
+	}{
+		{"unknown field", `{"halfLenght": 12}`, []string{"rules.json", "unknown field \"halfLenght\""}},
+		{"type error", "{\n  \"halfLength\": 12,\n  \"startingFunds\": \"800\"\n}", []string{"rules.json:3", "startingFunds", "expected float64, got string"}},
+		{"type error in a side block", `{"ct": {"startingFunds": true}}`, []string{"rules.json:1", "ct.startingFunds", "expected float64"}},
+		{"null value", `{"maxFunds": null}`, []string{"maxFunds: null is not a valid value"}},
+		{"null in the momentum block", `{"momentum": {"winStreak": null}}`, []string{"momentum.winStreak: null is not a valid value"}},
+		{"trailing data", `{"halfLength": 12} {"halfLength": 8}`, []string{"rules.json:1", "after top-level value"}},
+		{"not an object", `[1, 2]`, []string{"rules.json"}},
+		{"invalid values", `{"halfLength": 0, "lossBonus": [1400, -1], "tieResolution": "penalties"}`,
+			[]string{"halfLength: must be positive", "lossBonus[1]: must be non-negative", "tieResolution: must be"}},
+		{"explicit zero and false", `{"startingFunds": 0, "withSaves": false, "lossBonusCalc": false}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "rules.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			rules, _, err := NewGameRules(path)
+			if len(tt.errs) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if rules.StartingFunds != 0 || rules.WithSaves || rules.LossBonusCalc {
+					t.Errorf("explicit values not applied: startingFunds %g, withSaves %v, lossBonusCalc %v", rules.StartingFunds, rules.WithSaves, rules.LossBonusCalc)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no error")
+			}
+			for _, want := range tt.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+
+	if _, _, err := NewGameRules(filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "could not open") {
+		t.Errorf("missing file: %v", err)
+	}
+}
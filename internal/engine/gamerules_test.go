@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRuleProfiles(t *testing.T) {
+	want := []string{"cs2_mr12", "cs2_premier", "csgo_mr12", "csgo_mr15"}
+	if got := RuleProfiles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("RuleProfiles() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name         string
+		halfLength   int
+		otFunds      float64
+		plantBonus   float64 // BombplantRewardall
+		ctEliminator float64 // AdditionalReward_CT_Elimination
+	}{
+		{"csgo_mr15", 15, 10000, 800, 0},
+		{"csgo_mr12", 12, 10000, 800, 0},
+		{"cs2_mr12", 12, 12500, 600, 50},
+		{"cs2_premier", 12, 12500, 600, 50},
+	}
+	for _, tt := range tests {
+		rules, err := ProfileRules(tt.name)
+		if err != nil {
+			t.Fatalf("ProfileRules(%s): %v", tt.name, err)
+		}
+		if rules.Profile != tt.name {
+			t.Errorf("%s: profile field %q", tt.name, rules.Profile)
+		}
+		if rules.HalfLength != tt.halfLength || rules.OTFunds != tt.otFunds || rules.BombplantRewardall != tt.plantBonus || rules.AdditionalReward_CT_Elimination != tt.ctEliminator {
+			t.Errorf("%s: halfLength %d, otFunds %g, bombplantRewardall %g, additionalCTEliminationReward %g",
+				tt.name, rules.HalfLength, rules.OTFunds, rules.BombplantRewardall, rules.AdditionalReward_CT_Elimination)
+		}
+		if err := rules.Validate(); err != nil {
+			t.Errorf("%s: profile fails validation: %v", tt.name, err)
+		}
+	}
+
+	if _, err := ProfileRules("cs3"); err == nil {
+		t.Error("ProfileRules(cs3) returned no error")
+	}
+}
+
+func TestNewGameRulesProfileSpecs(t *testing.T) {
+	dir := t.TempDir()
+	override := filepath.Join(dir, "override.json")
+	if err := os.WriteFile(override, []byte(`{"halfLength": 8, "lossBonus": [1000, 2000]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	named := filepath.Join(dir, "named.json")
+	if err := os.WriteFile(named, []byte(`{"profile": "cs2_mr12", "startingFunds": 1000}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec       string
+		profile    string
+		halfLength int
+		otFunds    float64
+		lossBonus  int // Length of the loss bonus ladder
+	}{
+		{"", "csgo_mr15", 15, 10000, 5},
+		{"default", "csgo_mr15", 15, 10000, 5},
+		{"profile:cs2_mr12", "cs2_mr12", 12, 12500, 5},
+		{override, "csgo_mr15", 8, 10000, 2},                            // A file without profile builds on the default
+		{"profile:cs2_premier+" + override, "cs2_premier", 8, 12500, 2}, // Keys left out keep the profile's values
+		{named, "cs2_mr12", 12, 12500, 5},                               // The file names its own profile
+		{"profile:csgo_mr12+" + named, "csgo_mr12", 12, 10000, 5},       // The command line wins over the file
+	}
+	for _, tt := range tests {
+		rules, warnings, err := NewGameRules(tt.spec)
+		if err != nil {
+			t.Errorf("NewGameRules(%q): %v", tt.spec, err)
+			continue
+		}
+		if want := strings.HasPrefix(tt.spec, "profile:csgo_mr12+"); (len(warnings) > 0) != want {
+			t.Errorf("NewGameRules(%q) warnings %q, want a profile warning: %v", tt.spec, warnings, want)
+		}
+		if rules.Profile != tt.profile || rules.HalfLength != tt.halfLength || rules.OTFunds != tt.otFunds || len(rules.LossBonus) != tt.lossBonus {
+			t.Errorf("NewGameRules(%q) = profile %s, halfLength %d, otFunds %g, %d loss bonus levels; want %s, %d, %g, %d",
+				tt.spec, rules.Profile, rules.HalfLength, rules.OTFunds, len(rules.LossBonus), tt.profile, tt.halfLength, tt.otFunds, tt.lossBonus)
+		}
+	}
+
+	if _, _, err := NewGameRules("profile:cs3"); err == nil {
+		t.Error("NewGameRules(profile:cs3) returned no error")
+	}
+}
+
+func TestValidateOvertimeRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*GameRules)
+		valid  bool
+	}{
+		{"defaults", func(r *GameRules) {}, true},
+		{"no overtime", func(r *GameRules) { r.MaxOvertimes = 0 }, true},
+		{"negative cap", func(r *GameRules) { r.MaxOvertimes = -1 }, false},
+		{"lead2", func(r *GameRules) { r.OTWinCondition = OTWinLead2 }, true},
+		{"unknown win condition", func(r *GameRules) { r.OTWinCondition = "golden_round" }, false},
+		{"sudden death", func(r *GameRules) { r.TieResolution = TieSuddenDeath }, true},
+		{"unknown tie resolution", func(r *GameRules) { r.TieResolution = "penalties" }, false},
+	}
+	for _, tt := range tests {
+		rules := getDefaultRules()
+		tt.modify(&rules)
+		if got := rules.Validate() == nil; got != tt.valid {
+			t.Errorf("%s: valid %v, want %v", tt.name, got, tt.valid)
+		}
+	}
+}
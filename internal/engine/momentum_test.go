@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// momentumRound returns round 1 of a game under rules with momentum, Team1 on the CT side
+func momentumRound(m MomentumRules) (*Round, *Team, *Team) {
+	rules := getDefaultRules()
+	rules.Momentum = &m
+	g := &Game{GameRules: rules, GameinProgress: true, CurrentRound: 1, is_T1_CT: true, rng: rand.New(rand.NewSource(1))}
+	g.Team1 = NewTeam("A", rules.StartingFunds, true, rules.DefaultEquipment, "all_in")
+	g.Team2 = NewTeam("B", rules.StartingFunds, false, rules.DefaultEquipment, "all_in")
+	return NewRound(g.Team1, g.Team2, 1, true, &g.GameRules, false, g), g.Team1, g.Team2
+}
+
+func TestMomentumMultiplier(t *testing.T) {
+	rules := MomentumRules{WinStreak: 0.02, LossStreak: -0.01, MaxStreak: 3, MatchPoint: 0.05, FacingMatchPoint: -0.03, CTSide: 0.01}
+
+	tests := []struct {
+		name            string
+		wins, losses    int
+		score, oppScore int
+		opponentTimeout bool
+		ct              bool
+		want            float64
+	}{
+		{"neutral T side", 0, 0, 0, 0, false, false, 1},
+		{"CT side", 0, 0, 0, 0, false, true, 1.01},
+		{"win streak", 2, 0, 2, 0, false, false, 1.04},
+		{"win streak capped", 7, 0, 7, 0, false, false, 1.06},
+		{"loss streak capped", 0, 5, 0, 5, false, false, 0.97},
+		{"opponent timeout breaks the streak", 3, 0, 3, 0, true, false, 1},
+		{"match point", 1, 0, 15, 10, false, false, 1.07},
+		{"facing match point", 0, 1, 10, 15, false, true, 0.97},
+		{"both on match point", 0, 0, 15, 15, false, false, 1}, // 15-15 is not a match point in MR15
+	}
+	for _, tt := range tests {
+		r, team, opponent := momentumRound(rules)
+		rd := &team.RoundData[0]
+		rd.Consecutivewins, rd.Consecutiveloss, rd.Score_End = tt.wins, tt.losses, tt.score
+		opponent.RoundData[0].Score_End = tt.oppScore
+		opponent.RoundData[0].Timeout = tt.opponentTimeout
+
+		if got := r.momentumMultiplier(team, opponent, tt.ct); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: multiplier %g, want %g", tt.name, got, tt.want)
+		}
+	}
+
+	// The multiplier never turns negative
+	r, team, opponent := momentumRound(MomentumRules{LossStreak: -0.5})
+	team.RoundData[0].Consecutiveloss = 4
+	if got := r.momentumMultiplier(team, opponent, false); got != 0 {
+		t.Errorf("tilted multiplier %g, want 0", got)
+	}
+}
+
+func TestMomentumScalesCSFInput(t *testing.T) {
+	if err := LoadDistributions("../../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	r, ct, tside := momentumRound(MomentumRules{WinStreak: 0.1, CTSide: -0.05})
+	ct.RoundData[0].FTE_Eq_value = 20000
+	ct.RoundData[0].Consecutivewins = 3 // 1 + 3*0.1 - 0.05
+	tside.RoundData[0].FTE_Eq_value = 20000
+
+	r.CalculateRoundOutcome(ct, tside)
+	want := csfWithR(20001*1.25, 20001, r.gameRules.CSFRValue())
+	if math.Abs(r.Calc_Outcome.CSF-want) > 1e-12 {
+		t.Errorf("CSF %g, want %g from the scaled CT equipment", r.Calc_Outcome.CSF, want)
+	}
+	if even := csfWithR(20001, 20001, r.gameRules.CSFRValue()); r.Calc_Outcome.CSF <= even {
+		t.Errorf("CSF %g with a CT win streak, %g without", r.Calc_Outcome.CSF, even)
+	}
+}
+
+func TestMomentumStreakResets(t *testing.T) {
+	rules := getDefaultRules()
+	rules.Momentum = &MomentumRules{WinStreak: 0.03}
+
+	// The streak of a team does not carry over a side switch or the start of an overtime
+	for _, tt := range []struct {
+		name    string
+		switch_ func(*Team)
+	}{
+		{"halftime", func(team *Team) { team.Sideswitch(false, &rules) }},
+		{"overtime side switch", func(team *Team) { team.Sideswitch(true, &rules) }},
+		{"overtime start", func(team *Team) { team.NewOT(rules.OTFunds, rules.OTEquipment) }},
+	} {
+		r, team, opponent := momentumRound(*rules.Momentum)
+		team.RoundData[0].Consecutivewins = 6
+		tt.switch_(team)
+		if got := r.momentumMultiplier(team, opponent, false); got != 1 {
+			t.Errorf("%s: multiplier %g after the reset, want 1", tt.name, got)
+		}
+	}
+
+	if err := LoadDistributions("../../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	// In played games every half starts without a streak
+	rules.MaxOvertimes = 2
+	for seed := int64(1); seed <= 50; seed++ {
+		g := NewSeededGame("momentum", "A", "all_in", "B", "all_in", rules, seed)
+		g.Start()
+		for _, round := range g.Rounds {
+			otStart := round.OT && (round.RoundNumber-rules.HalfLength*2-1)%(rules.OTHalfLength*2) == 0
+			if !round.Sideswitch && !otStart {
+				continue
+			}
+			for _, team := range []*Team{g.Team1, g.Team2} {
+				rd := team.RoundData[round.RoundNumber-1]
+				if rd.Consecutivewins_start != 0 || rd.Consecutiveloss_start != 0 {
+					t.Fatalf("seed %d, round %d: %s starts with a streak of %d wins, %d losses", seed, round.RoundNumber, team.Name, rd.Consecutivewins_start, rd.Consecutiveloss_start)
+				}
+			}
+		}
+	}
+}
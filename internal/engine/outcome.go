@@ -0,0 +1,56 @@
+package engine
+
+import "fmt"
+
+// Outcome is the result of a game from Team1's point of view: a win of either team or,
+// if the rules allow it, a draw
+type Outcome int8
+
+const (
+	OutcomeUndecided Outcome = iota // The game is still running
+	OutcomeTeam1Win
+	OutcomeTeam2Win
+	OutcomeDraw
+)
+
+var outcomeNames = [...]string{"undecided", "team1", "team2", "draw"}
+
+// winnerOutcome returns the outcome of a game won by Team1 (t1Won) or Team2
+func winnerOutcome(t1Won bool) Outcome {
+	if t1Won {
+		return OutcomeTeam1Win
+	}
+	return OutcomeTeam2Win
+}
+
+// Team1Won reports whether Team1 won the game
+func (o Outcome) Team1Won() bool { return o == OutcomeTeam1Win }
+
+// Team2Won reports whether Team2 won the game
+func (o Outcome) Team2Won() bool { return o == OutcomeTeam2Win }
+
+// IsDraw reports whether the game ended tied
+func (o Outcome) IsDraw() bool { return o == OutcomeDraw }
+
+func (o Outcome) String() string {
+	if o < 0 || int(o) >= len(outcomeNames) {
+		return fmt.Sprintf("Outcome(%d)", int8(o))
+	}
+	return outcomeNames[o]
+}
+
+// MarshalText writes the outcome by name ("team1", "team2", "draw") in JSON exports and events
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText parses an outcome written by MarshalText
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for i, name := range outcomeNames {
+		if string(text) == name {
+			*o = Outcome(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown game outcome '%s'", text)
+}
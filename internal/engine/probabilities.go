@@ -61,6 +61,14 @@ func IsProbabilitiesLoaded() bool {
 	return distributionsLoaded
 }
 
+// DistributionsHash returns the SHA-256 of the loaded distributions file ("" if none is loaded)
+func DistributionsHash() string {
+	if !distributionsLoaded {
+		return ""
+	}
+	return distributions.Hash
+}
+
 // GetProbabilities returns the loaded distributions (for testing/debugging)
 func GetProbabilities() *ProcessedDistributions {
 	return distributions
@@ -70,8 +78,11 @@ func GetProbabilities() *ProcessedDistributions {
 // Returns the probability that side with expenditure x wins against side with expenditure y.
 // When r > 99, treats it as an all-pay auction where higher expenditure wins with probability 1.0 or 0.0.
 func CSF(x float64, y float64) float64 {
-	r := GetCSFRValue()
+	return csfWithR(x, y, GetCSFRValue())
+}
 
+// csfWithR is the Tullock CSF with an explicit r value
+func csfWithR(x float64, y float64, r float64) float64 {
 	// All-pay auction: r > 99 means winner-takes-all based on expenditure
 	if r > 99 {
 		if x > y {
@@ -95,28 +106,25 @@ func GetCSFRValue() float64 {
 	return distributions.Metadata.CSFRValue
 }
 
-// SetCustomCSFRValue overrides the CSF r value from distributions with a custom value.
-// If customRValue is negative, the original value from distributions is kept.
-// Returns true if a custom value was set, false otherwise.
-func SetCustomCSFRValue(customRValue float64) bool {
-	assertLoaded("SetCustomCSFRValue")
-	if customRValue >= 0 {
-		originalRValue := distributions.Metadata.CSFRValue
-		distributions.Metadata.CSFRValue = customRValue
-		fmt.Printf("Using custom CSF r value: %.2f (overriding probabilities.json value: %.2f)\n", customRValue, originalRValue)
-		return true
-	}
-	return false
+// CSFModifier changes the CT win probability of a single round, e.g. after a timeout.
+// The zero value leaves the CSF unchanged.
+type CSFModifier struct {
+	Shift   float64 // Added to the CT win probability (the result is kept in [0,1])
+	RFactor float64 // Factor on the CSF r value, 0 leaves it unchanged
 }
 
 // DetermineRoundOutcome determines all aspects of a round outcome based on CSF probability.
 // csfProb should be in [0,1], representing the CT win probability.
-func DetermineRoundOutcome(ct_eq_val float64, t_eq_val float64, rng *rand.Rand, gameR GameRules) RoundOutcome {
+func DetermineRoundOutcome(ct_eq_val float64, t_eq_val float64, rng *rand.Rand, gameR GameRules, mod CSFModifier) RoundOutcome {
 	assertLoaded("DetermineRoundOutcome")
 
 	outcome := RoundOutcome{}
 
-	outcome.CSF = CSF(ct_eq_val, t_eq_val)
+	r := gameR.CSFRValue()
+	if mod.RFactor > 0 {
+		r *= mod.RFactor
+	}
+	outcome.CSF = min(1, max(0, csfWithR(ct_eq_val, t_eq_val, r)+mod.Shift))
 
 	// 1. Determine winner
 	outcome.StochasticValues.RNG_CSF = rng.Float64()
@@ -15,12 +15,14 @@ type Round struct {
 
 func NewRound(T1 *Team, T2 *Team, roundNumber int, ctteam bool, gamerules *GameRules, ot bool, g *Game) *Round {
 
+	// Lost equipment is replaced with the default equipment of the team's side
+	t1Economy, t2Economy := gamerules.Economy(T1.GetSide()), gamerules.Economy(T2.GetSide())
 	if roundNumber != 1 { //avoid calling NewRound on first round twice
-		T1.NewRound(gamerules.DefaultEquipment)
-		T2.NewRound(gamerules.DefaultEquipment)
+		T1.NewRound(t1Economy.DefaultEquipment)
+		T2.NewRound(t2Economy.DefaultEquipment)
 	} else if ot {
-		T1.NewRound(gamerules.OTEquipment)
-		T2.NewRound(gamerules.OTEquipment)
+		T1.NewRound(t1Economy.OTEquipment)
+		T2.NewRound(t2Economy.OTEquipment)
 	}
 
 	return &Round{
@@ -37,23 +39,42 @@ func NewRound(T1 *Team, T2 *Team, roundNumber int, ctteam bool, gamerules *GameR
 func (r *Round) HandleSideSwitch(Team1 *Team, Team2 *Team) {
 	r.Sideswitch = true
 	r.IsT1CT = !r.IsT1CT
-	Team1.Sideswitch(r.OT, r.gameRules.StartingFunds, r.gameRules.DefaultEquipment, r.gameRules.OTFunds, r.gameRules.OTEquipment)
-	Team2.Sideswitch(r.OT, r.gameRules.StartingFunds, r.gameRules.DefaultEquipment, r.gameRules.OTFunds, r.gameRules.OTEquipment)
+	Team1.Sideswitch(r.OT, r.gameRules)
+	Team2.Sideswitch(r.OT, r.gameRules)
 }
 
 // HandleOTStart manages the start of overtime
 func (r *Round) HandleOTStart(Team1 *Team, Team2 *Team) {
 	r.OT = true
-	Team1.NewOT(r.gameRules.OTFunds, r.gameRules.OTEquipment)
-	Team2.NewOT(r.gameRules.OTFunds, r.gameRules.OTEquipment)
+	t1Economy, t2Economy := r.gameRules.Economy(Team1.GetSide()), r.gameRules.Economy(Team2.GetSide())
+	Team1.NewOT(t1Economy.OTFunds, t1Economy.OTEquipment)
+	Team2.NewOT(t2Economy.OTFunds, t2Economy.OTEquipment)
+	Team1.SetTimeoutsLeft(r.gameRules.TimeoutsPerHalf(true))
+	Team2.SetTimeoutsLeft(r.gameRules.TimeoutsPerHalf(true))
 }
 
 // HandleOTSideSwitch manages side switching during overtime
 func (r *Round) HandleOTSideSwitch(Team1 *Team, Team2 *Team) {
 	r.Sideswitch = true
 	r.IsT1CT = !r.IsT1CT
-	Team1.Sideswitch(true, r.gameRules.StartingFunds, r.gameRules.DefaultEquipment, r.gameRules.OTFunds, r.gameRules.OTEquipment)
-	Team2.Sideswitch(true, r.gameRules.StartingFunds, r.gameRules.DefaultEquipment, r.gameRules.OTFunds, r.gameRules.OTEquipment)
+	Team1.Sideswitch(true, r.gameRules)
+	Team2.Sideswitch(true, r.gameRules)
+}
+
+// TimeoutPhase lets both teams call a timeout before the buy phase. Both decide on the
+// state before either timeout is taken.
+func (r *Round) TimeoutPhase(Team1 *Team, Team2 *Team) {
+	if r.gameRules.Timeouts == nil {
+		return
+	}
+	t1Timeout := Team1.GetTimeoutsLeft() > 0 && CallTimeout(Team1, Team2, r.RoundNumber, r.OT, *r.gameRules, r.game)
+	t2Timeout := Team2.GetTimeoutsLeft() > 0 && CallTimeout(Team2, Team1, r.RoundNumber, r.OT, *r.gameRules, r.game)
+	if t1Timeout {
+		Team1.CallTimeout()
+	}
+	if t2Timeout {
+		Team2.CallTimeout()
+	}
 }
 
 // solution for what information gets passed to the teams, could be a json file in the gamerules which specifies
@@ -125,41 +146,45 @@ func (r *Round) determineFundsEarned(Team1 *Team, Team2 *Team) {
 		Team2.SetSurvivors(r.Calc_Outcome.CTSurvivors)
 	}
 
+	// Side-specific economy values (loss bonus ladder, kill reward and funds cap)
+	ctEconomy, tEconomy := r.gameRules.Economy(true), r.gameRules.Economy(false)
+
 	var lossbonus int
 	//Loser bonus evaluation
 	if r.Calc_Outcome.CTWins {
-		lossbonus = r.LossBonusCalculation(tteam)
+		lossbonus = r.LossBonusCalculation(tteam, tEconomy.LossBonus)
 	} else {
-		lossbonus = r.LossBonusCalculation(ctteam)
+		lossbonus = r.LossBonusCalculation(ctteam, ctEconomy.LossBonus)
 	}
 
-	maxLossLevel := len(r.gameRules.LossBonus) - 1
+	ctMaxLossLevel := len(ctEconomy.LossBonus) - 1
+	tMaxLossLevel := len(tEconomy.LossBonus) - 1
 	// Update loss bonus levels
 
 	if r.gameRules.LossBonusCalc {
 
 		if r.Calc_Outcome.CTWins {
-			tteam.SetCurrentLossBonusLevel(tteam.GetCurrentLossBonusLevel()+1, maxLossLevel)
-			ctteam.SetCurrentLossBonusLevel(ctteam.GetCurrentLossBonusLevel()-1, maxLossLevel)
+			tteam.SetCurrentLossBonusLevel(tteam.GetCurrentLossBonusLevel()+1, tMaxLossLevel)
+			ctteam.SetCurrentLossBonusLevel(ctteam.GetCurrentLossBonusLevel()-1, ctMaxLossLevel)
 		} else {
-			ctteam.SetCurrentLossBonusLevel(ctteam.GetCurrentLossBonusLevel()+1, maxLossLevel)
-			tteam.SetCurrentLossBonusLevel(tteam.GetCurrentLossBonusLevel()-1, maxLossLevel)
+			ctteam.SetCurrentLossBonusLevel(ctteam.GetCurrentLossBonusLevel()+1, ctMaxLossLevel)
+			tteam.SetCurrentLossBonusLevel(tteam.GetCurrentLossBonusLevel()-1, tMaxLossLevel)
 		}
 	} else {
 		if r.Calc_Outcome.CTWins {
-			tteam.SetCurrentLossBonusLevel(tteam.GetCurrentLossBonusLevel()+1, maxLossLevel)
-			ctteam.SetCurrentLossBonusLevel(0, maxLossLevel)
+			tteam.SetCurrentLossBonusLevel(tteam.GetCurrentLossBonusLevel()+1, tMaxLossLevel)
+			ctteam.SetCurrentLossBonusLevel(0, ctMaxLossLevel)
 		} else {
-			ctteam.SetCurrentLossBonusLevel(ctteam.GetCurrentLossBonusLevel()+1, maxLossLevel)
-			tteam.SetCurrentLossBonusLevel(0, maxLossLevel)
+			ctteam.SetCurrentLossBonusLevel(ctteam.GetCurrentLossBonusLevel()+1, ctMaxLossLevel)
+			tteam.SetCurrentLossBonusLevel(0, tMaxLossLevel)
 		}
 	}
 
 	// Kills and loss bonus
 
 	if r.Calc_Outcome.CTWins {
-		loserFunds += float64((5 - (r.Calc_Outcome.CTSurvivors))) * (r.gameRules.EliminationReward + (r.gameRules.AdditionalReward_T_Elimination * 5))
-		winnerFunds += float64((5 - (r.Calc_Outcome.TSurvivors))) * (r.gameRules.EliminationReward + (r.gameRules.AdditionalReward_CT_Elimination * 5))
+		loserFunds += float64((5 - (r.Calc_Outcome.CTSurvivors))) * (tEconomy.EliminationReward + (r.gameRules.AdditionalReward_T_Elimination * 5))
+		winnerFunds += float64((5 - (r.Calc_Outcome.TSurvivors))) * (ctEconomy.EliminationReward + (r.gameRules.AdditionalReward_CT_Elimination * 5))
 
 		// Add loss bonus to losing team
 		// Reduction for surviving T players if round end reason is 4
@@ -169,8 +194,8 @@ func (r *Round) determineFundsEarned(Team1 *Team, Team2 *Team) {
 		}
 		loserFunds += float64(lossbonus) * float64(5-reduction)
 	} else {
-		loserFunds += float64((5 - (r.Calc_Outcome.TSurvivors))) * (r.gameRules.EliminationReward + (r.gameRules.AdditionalReward_CT_Elimination * 5))
-		winnerFunds += float64((5 - (r.Calc_Outcome.CTSurvivors))) * (r.gameRules.EliminationReward + (r.gameRules.AdditionalReward_T_Elimination * 5))
+		loserFunds += float64((5 - (r.Calc_Outcome.TSurvivors))) * (ctEconomy.EliminationReward + (r.gameRules.AdditionalReward_CT_Elimination * 5))
+		winnerFunds += float64((5 - (r.Calc_Outcome.CTSurvivors))) * (tEconomy.EliminationReward + (r.gameRules.AdditionalReward_T_Elimination * 5))
 
 		// Add loss bonus to losing team
 		loserFunds += float64(lossbonus) * 5
@@ -188,22 +213,28 @@ func (r *Round) determineFundsEarned(Team1 *Team, Team2 *Team) {
 	}
 	// Ensure funds do not exceed maximum allowed
 
+	t1MaxFunds, t2MaxFunds := tEconomy.MaxFunds, ctEconomy.MaxFunds
+	if r.IsT1CT {
+		t1MaxFunds, t2MaxFunds = ctEconomy.MaxFunds, tEconomy.MaxFunds
+	}
+
 	Team1.SetEarned(FundsearnedT1)
-	Team1.SetFunds(math.Min(Team1.GetCurrentFunds(), r.gameRules.MaxFunds))
+	Team1.SetFunds(math.Min(Team1.GetCurrentFunds(), t1MaxFunds))
 
 	Team2.SetEarned(FundsearnedT2)
-	Team2.SetFunds(math.Min(Team2.GetCurrentFunds(), r.gameRules.MaxFunds))
+	Team2.SetFunds(math.Min(Team2.GetCurrentFunds(), t2MaxFunds))
 }
 
-func (r *Round) LossBonusCalculation(loserteam *Team) int {
+// LossBonusCalculation returns the loss bonus of loserteam on the loss bonus ladder of its side
+func (r *Round) LossBonusCalculation(loserteam *Team, ladder []float64) int {
 	// Calculate loss bonus based on consecutive losses
 
 	lossBonus := 0
 	lossbonuslevel := loserteam.GetCurrentLossBonusLevel()
-	if lossbonuslevel < len(r.gameRules.LossBonus) {
-		lossBonus = int(r.gameRules.LossBonus[lossbonuslevel])
+	if lossbonuslevel < len(ladder) {
+		lossBonus = int(ladder[lossbonuslevel])
 	} else {
-		lossBonus = int(r.gameRules.LossBonus[len(r.gameRules.LossBonus)-1])
+		lossBonus = int(ladder[len(ladder)-1])
 	}
 	return lossBonus
 }
@@ -225,8 +256,18 @@ func (r *Round) CalculateRoundOutcome(Team1 *Team, Team2 *Team) {
 		tequipment += Team1.RoundData[r.RoundNumber-1].FTE_Eq_value
 	}
 
+	// Momentum modifies the equipment the CSF compares
+	if r.gameRules.Momentum != nil {
+		ctteam, tteam := Team1, Team2
+		if !r.IsT1CT {
+			ctteam, tteam = Team2, Team1
+		}
+		ctequipment *= r.momentumMultiplier(ctteam, tteam, true)
+		tequipment *= r.momentumMultiplier(tteam, ctteam, false)
+	}
+
 	// Get comprehensive round outcome from ABM distributions (uses CT win probability)
-	r.Calc_Outcome = DetermineRoundOutcome(ctequipment, tequipment, r.game.rng, *r.gameRules)
+	r.Calc_Outcome = DetermineRoundOutcome(ctequipment, tequipment, r.game.rng, *r.gameRules, r.timeoutModifier(Team1, Team2))
 
 	// Determine which team won
 	r.IsT1WinnerTeam = !r.Calc_Outcome.CTWins
@@ -236,3 +277,57 @@ func (r *Round) CalculateRoundOutcome(Team1 *Team, Team2 *Team) {
 	}
 
 }
+
+// timeoutModifier returns the effect of the timeouts called before the round on its CSF
+func (r *Round) timeoutModifier(Team1 *Team, Team2 *Team) CSFModifier {
+	mod := CSFModifier{}
+	timeouts := r.gameRules.Timeouts
+	if timeouts == nil {
+		return mod
+	}
+	for _, team := range []*Team{Team1, Team2} {
+		if !team.RoundData[r.RoundNumber-1].Timeout {
+			continue
+		}
+		switch timeouts.Effect {
+		case TimeoutCSFShift:
+			// The shift favours the calling team, the CSF is the CT win probability
+			if team.GetSide() {
+				mod.Shift += timeouts.CSFShift
+			} else {
+				mod.Shift -= timeouts.CSFShift
+			}
+		case TimeoutVariance:
+			// Applied once per round, a second timeout in the same round adds nothing
+			mod.RFactor = timeouts.RFactor
+		}
+	}
+	return mod
+}
+
+// momentumMultiplier returns the factor on the team's equipment in the CSF from its streak,
+// the score pressure and its side. A timeout the opponent called before the round resets
+// the team's streak modifier.
+func (r *Round) momentumMultiplier(team *Team, opponent *Team, ct bool) float64 {
+	m := r.gameRules.Momentum
+	rd := team.RoundData[r.RoundNumber-1]
+	multiplier := 1.0
+
+	if !opponent.RoundData[r.RoundNumber-1].Timeout {
+		wins, losses := rd.Consecutivewins, rd.Consecutiveloss
+		if m.MaxStreak > 0 {
+			wins, losses = min(wins, m.MaxStreak), min(losses, m.MaxStreak)
+		}
+		multiplier += float64(wins)*m.WinStreak + float64(losses)*m.LossStreak
+	}
+	if r.game.isMatchPoint(team.GetScore(), opponent.GetScore()) {
+		multiplier += m.MatchPoint
+	}
+	if r.game.isMatchPoint(opponent.GetScore(), team.GetScore()) {
+		multiplier += m.FacingMatchPoint
+	}
+	if ct {
+		multiplier += m.CTSide
+	}
+	return max(0, multiplier)
+}
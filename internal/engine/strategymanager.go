@@ -11,8 +11,39 @@ type StrategyManager struct {
 }
 
 func CallStrategy(team *Team, opponent *Team, curround int, isOvertime bool, gameR GameRules, g *Game) float64 {
-	// Build context once
-	ctx := strategy.StrategyContext_simple{
+	ctx := strategyContext(team, opponent, curround, isOvertime, gameR, g)
+
+	// Get strategy function from registry
+	strategyFunc, err := strategy.GetStrategy(team.Strategy)
+	if err != nil {
+		// This should never happen if validation is done upfront
+		// But provide a safe fallback just in case
+		panic(fmt.Sprintf("FATAL: Invalid strategy '%s' for team - this should have been caught during validation!", team.Strategy))
+	}
+
+	invest := strategyFunc(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("FATAL: Error executing strategy '%s': %v", team.Strategy, err))
+	}
+	return invest
+}
+
+// CallTimeout decides whether the team calls a timeout before the buy phase: the team
+// calls one when it has just lost Timeouts.CallAfterLosses rounds in a row, unless its
+// strategy registered its own policy in strategy.TimeoutRegistry.
+func CallTimeout(team *Team, opponent *Team, curround int, isOvertime bool, gameR GameRules, g *Game) bool {
+	if policy, ok := strategy.GetTimeoutPolicy(team.Strategy); ok {
+		return policy(strategyContext(team, opponent, curround, isOvertime, gameR, g))
+	}
+	n := gameR.Timeouts.CallAfterLosses
+	return n > 0 && team.GetConsecutiveloss() == n
+}
+
+// strategyContext builds what a strategy decides on; the economy values are those of the
+// team's side
+func strategyContext(team *Team, opponent *Team, curround int, isOvertime bool, gameR GameRules, g *Game) strategy.StrategyContext_simple {
+	economy := gameR.Economy(team.GetSide())
+	return strategy.StrategyContext_simple{
 		Funds:                              team.GetCurrentFunds(),
 		CurrentRound:                       curround,
 		OpponentScore:                      opponent.GetScore(),
@@ -33,21 +64,23 @@ func CallStrategy(team *Team, opponent *Team, curround int, isOvertime bool, gam
 		RoundEndReason:                     g.GetPreviousRoundEndReason(),
 		Is_BombPlanted:                     g.GetPreviousBombPlant(),
 		RNG:                                g.rng,
+		TimeoutsLeft:                       team.GetTimeoutsLeft(),
+		TimeoutsLeft_opponent:              opponent.GetTimeoutsLeft(),
 		Funds_opponent_forbidden:           opponent.GetCurrentFunds(),
 		Start_Equipment_opponent_forbidden: opponent.GetRSEquipment(),
 		GameRules_strategy: strategy.GameRules_strategymanager{
-			DefaultEquipment:                gameR.DefaultEquipment,
-			OTFunds:                         gameR.OTFunds,
-			OTEquipment:                     gameR.OTEquipment,
-			StartingFunds:                   gameR.StartingFunds,
+			DefaultEquipment:                economy.DefaultEquipment,
+			OTFunds:                         economy.OTFunds,
+			OTEquipment:                     economy.OTEquipment,
+			StartingFunds:                   economy.StartingFunds,
 			HalfLength:                      gameR.HalfLength,
 			OTHalfLength:                    gameR.OTHalfLength,
-			MaxFunds:                        gameR.MaxFunds,
+			MaxFunds:                        economy.MaxFunds,
 			LossBonusCalc:                   gameR.LossBonusCalc,
 			WithSaves:                       gameR.WithSaves,
-			LossBonus:                       gameR.LossBonus,
+			LossBonus:                       economy.LossBonus,
 			RoundOutcomeReward:              gameR.RoundOutcomeReward,
-			EliminationReward:               gameR.EliminationReward,
+			EliminationReward:               economy.EliminationReward,
 			BombplantRewardall:              gameR.BombplantRewardall,
 			BombplantReward:                 gameR.BombplantReward,
 			BombdefuseReward:                gameR.BombdefuseReward,
@@ -55,20 +88,6 @@ func CallStrategy(team *Team, opponent *Team, curround int, isOvertime bool, gam
 			AdditionalReward_T_Elimination:  gameR.AdditionalReward_T_Elimination,
 		},
 	}
-
-	// Get strategy function from registry
-	strategyFunc, err := strategy.GetStrategy(team.Strategy)
-	if err != nil {
-		// This should never happen if validation is done upfront
-		// But provide a safe fallback just in case
-		panic(fmt.Sprintf("FATAL: Invalid strategy '%s' for team - this should have been caught during validation!", team.Strategy))
-	}
-
-	invest := strategyFunc(ctx)
-	if err != nil {
-		panic(fmt.Sprintf("FATAL: Error executing strategy '%s': %v", team.Strategy, err))
-	}
-	return invest
 }
 
 //most of the following functions are used to enhance the context for decision making
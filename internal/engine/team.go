@@ -1,5 +1,7 @@
 package engine
 
+import "dbg_abm/internal/strategy"
+
 // Team represents a team in the simulation with its properties and methods.
 type Team struct {
 	Name      string
@@ -24,6 +26,9 @@ type Team_RoundData struct {
 	Consecutiveloss_start int     // Consecutive losses at the start of the round
 	LossBonusLevel        int     // Level of loss bonus calculated at the end of the round
 	Spent                 float64 // Total funds spent by the team during buy time
+	BuyType               string  // Buy type of the round (strategy.BuyTypes), classified after the buy phase
+	TimeoutsLeft          int     // Timeouts left in the current half, after a timeout of this round
+	Timeout               bool    // True if the team called a timeout before the buy phase of the round
 }
 
 func NewTeam(name string, startingfunds float64, side bool, defaultequipment float64, strategy string) *Team {
@@ -74,6 +79,7 @@ func (t *Team) NewRound(defaultequipment float64) {
 		Consecutiveloss_start: previousRound.Consecutiveloss,
 		LossBonusLevel:        previousRound.LossBonusLevel,
 		Spent:                 0,
+		TimeoutsLeft:          previousRound.TimeoutsLeft,
 	}
 	t.RoundData = append(t.RoundData, newRoundData)
 }
@@ -104,14 +110,18 @@ func (t *Team) NewOT(OTfunds float64, OTEquipment float64) {
 
 }
 
-func (t *Team) Sideswitch(OT bool, startingfunds float64, defaultEquipment float64, OTfunds float64, OTEquipment float64) {
+// Sideswitch switches the side of the team and resets its economy to the starting values
+// of the new side
+func (t *Team) Sideswitch(OT bool, rules *GameRules) {
 	t.RoundData[len(t.RoundData)-1].is_Side_CT = !t.RoundData[len(t.RoundData)-1].is_Side_CT // Switch side if needed
+	economy := rules.Economy(t.GetSide())
+	t.SetTimeoutsLeft(rules.TimeoutsPerHalf(OT))
 	if OT {
-		t.NewOT(OTfunds, OTEquipment)
+		t.NewOT(economy.OTFunds, economy.OTEquipment)
 	} else {
-		t.RoundData[len(t.RoundData)-1].Funds = 5 * startingfunds // Reset funds for new half
-		t.RoundData[len(t.RoundData)-1].Funds_start = 5 * startingfunds
-		t.RoundData[len(t.RoundData)-1].RS_Eq_value = 5 * defaultEquipment // Reset equipment for new half
+		t.RoundData[len(t.RoundData)-1].Funds = 5 * economy.StartingFunds // Reset funds for new half
+		t.RoundData[len(t.RoundData)-1].Funds_start = 5 * economy.StartingFunds
+		t.RoundData[len(t.RoundData)-1].RS_Eq_value = 5 * economy.DefaultEquipment // Reset equipment for new half
 
 		t.RoundData[len(t.RoundData)-1].Consecutiveloss = 0
 		t.RoundData[len(t.RoundData)-1].Consecutivewins = 0
@@ -129,6 +139,9 @@ func (t *Team) BuyPhase(Round int, ot bool, t2 *Team, gameR GameRules, g *Game)
 
 	t.SpendFunds(investment) // Spend investment amount
 
+	RD := &t.RoundData[len(t.RoundData)-1]
+	RD.BuyType = strategy.ClassifyBuyType(RD.RS_Eq_value, RD.Spent)
+
 }
 
 func (t *Team) SpendFunds(amount float64) {
@@ -202,6 +215,24 @@ func (t *Team) SetREEqValue(value float64) {
 	RD.RE_Eq_value = value
 }
 
+func (t *Team) GetTimeoutsLeft() int {
+	RD := &t.RoundData[len(t.RoundData)-1]
+	return RD.TimeoutsLeft
+}
+
+// SetTimeoutsLeft gives the team its timeouts for a new half
+func (t *Team) SetTimeoutsLeft(timeouts int) {
+	RD := &t.RoundData[len(t.RoundData)-1]
+	RD.TimeoutsLeft = timeouts
+}
+
+// CallTimeout uses one of the team's timeouts in the current round
+func (t *Team) CallTimeout() {
+	RD := &t.RoundData[len(t.RoundData)-1]
+	RD.TimeoutsLeft--
+	RD.Timeout = true
+}
+
 func (t *Team) GetSide() bool {
 	RD := &t.RoundData[len(t.RoundData)-1]
 	return RD.is_Side_CT
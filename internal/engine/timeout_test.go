@@ -0,0 +1,121 @@
+package engine
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// halfOf returns the index of the half a round is played in: 0 and 1 in regulation, then
+// two per overtime
+func halfOf(rules GameRules, round int) int {
+	if round <= rules.HalfLength*2 {
+		return (round - 1) / rules.HalfLength
+	}
+	return 2 + (round-rules.HalfLength*2-1)/rules.OTHalfLength
+}
+
+func TestTimeoutsPerHalf(t *testing.T) {
+	if err := LoadDistributions("../../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	rules := getDefaultRules()
+	rules.MaxOvertimes = 2
+	rules.TieResolution = TieDraw
+	rules.Timeouts = &TimeoutRules{PerHalf: 2, PerOTHalf: 1, Effect: TimeoutCSFShift, CSFShift: 0.05, CallAfterLosses: 1}
+
+	full := map[bool]int{} // Halves in which a team used all its timeouts, by overtime
+	for seed := int64(1); seed <= 200; seed++ {
+		g := NewSeededGame("timeouts", "A", "all_in", "B", "all_in", rules, seed)
+		g.Start()
+
+		for _, team := range []*Team{g.Team1, g.Team2} {
+			used := map[int]int{}
+			for i, rd := range team.RoundData[:len(g.Rounds)] {
+				round := i + 1
+				half := halfOf(rules, round)
+				allowed := rules.TimeoutsPerHalf(round > rules.HalfLength*2)
+				if rd.Timeout {
+					used[half]++
+				}
+				if used[half] > allowed {
+					t.Fatalf("seed %d: %s called %d timeouts in half %d, %d allowed", seed, team.Name, used[half], half, allowed)
+				}
+				if rd.TimeoutsLeft != allowed-used[half] {
+					t.Fatalf("seed %d, round %d: %s has %d timeouts left after using %d of %d", seed, round, team.Name, rd.TimeoutsLeft, used[half], allowed)
+				}
+				// callAfterLosses 1: a timeout exactly after every lost round while timeouts are left
+				lostLast := i > 0 && team.RoundData[i-1].Consecutiveloss == 1 && halfOf(rules, round-1) == half
+				if lostLast && rd.TimeoutsLeft > 0 && !rd.Timeout {
+					t.Fatalf("seed %d, round %d: %s lost the round before and kept its timeout", seed, round, team.Name)
+				}
+			}
+			for half, n := range used {
+				if n == rules.TimeoutsPerHalf(half >= 2) {
+					full[half >= 2]++
+				}
+			}
+		}
+	}
+	if full[false] == 0 || full[true] == 0 {
+		t.Errorf("halves with all timeouts used: %d in regulation, %d in overtime; the allowances never ran out", full[false], full[true])
+	}
+}
+
+func TestCallTimeoutAfterLosses(t *testing.T) {
+	rules := getDefaultRules()
+	rules.Timeouts = &TimeoutRules{PerHalf: 4, CallAfterLosses: 2}
+	g := &Game{GameRules: rules}
+
+	for losses, want := range map[int]bool{0: false, 1: false, 2: true, 3: false} {
+		team := NewTeam("A", 800, true, 200, "min_max_v4")
+		team.RoundData[0].Consecutiveloss = losses
+		opponent := NewTeam("B", 800, false, 200, "all_in")
+		if got := CallTimeout(team, opponent, 5, false, rules, g); got != want {
+			t.Errorf("%d losses in a row: timeout %v, want %v", losses, got, want)
+		}
+	}
+}
+
+func TestTimeoutEffectOnCSF(t *testing.T) {
+	if err := LoadDistributions("../../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	const eq = 15000.0
+	r0 := getDefaultRules().CSFRValue()
+	even := csfWithR(eq+1, eq+1, r0)
+
+	tests := []struct {
+		name        string
+		timeouts    TimeoutRules
+		ctEq        float64
+		ct, t       bool // Who called a timeout
+		wantCSF     float64
+		wantRFactor float64 // 0: r unchanged
+	}{
+		{"csf_shift for the CT side", TimeoutRules{Effect: TimeoutCSFShift, CSFShift: 0.04}, eq, true, false, even + 0.04, 0},
+		{"csf_shift for the T side", TimeoutRules{Effect: TimeoutCSFShift, CSFShift: 0.04}, eq, false, true, even - 0.04, 0},
+		{"csf_shift by both cancels out", TimeoutRules{Effect: TimeoutCSFShift, CSFShift: 0.04}, eq, true, true, even, 0},
+		{"variance", TimeoutRules{Effect: TimeoutVariance, RFactor: 0.5}, 2 * eq, true, false, csfWithR(2*eq+1, eq+1, r0*0.5), 0.5},
+		{"variance once for two timeouts", TimeoutRules{Effect: TimeoutVariance, RFactor: 0.5}, 2 * eq, true, true, csfWithR(2*eq+1, eq+1, r0*0.5), 0.5},
+	}
+	for _, tt := range tests {
+		rules := getDefaultRules()
+		rules.Timeouts = &tt.timeouts
+		g := &Game{GameRules: rules, CurrentRound: 1, rng: rand.New(rand.NewSource(3))}
+		ctTeam := NewTeam("CT", 800, true, 200, "all_in")
+		tTeam := NewTeam("T", 800, false, 200, "all_in")
+		ctTeam.RoundData[0].FTE_Eq_value, ctTeam.RoundData[0].Timeout = tt.ctEq, tt.ct
+		tTeam.RoundData[0].FTE_Eq_value, tTeam.RoundData[0].Timeout = eq, tt.t
+		round := NewRound(ctTeam, tTeam, 1, true, &g.GameRules, false, g)
+
+		// Variance is applied once per round: two timeouts give rFactor, not rFactor squared
+		if mod := round.timeoutModifier(ctTeam, tTeam); mod.RFactor != tt.wantRFactor {
+			t.Errorf("%s: r factor %g, want %g", tt.name, mod.RFactor, tt.wantRFactor)
+		}
+		round.CalculateRoundOutcome(ctTeam, tTeam)
+		if math.Abs(round.Calc_Outcome.CSF-tt.wantCSF) > 1e-12 {
+			t.Errorf("%s: CSF %.6f, want %.6f", tt.name, round.Calc_Outcome.CSF, tt.wantCSF)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Package report renders simulation and tournament exports as a single self-contained HTML
+// file with inline SVG charts.
+package report
+
+import (
+	"dbg_abm/internal/analysis"
+	"dbg_abm/internal/tournament"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+// ciConfidence is used for intervals that are not stored in the exports
+const ciConfidence = 0.95
+
+// Report is the content of a report: a tournament overview and/or one section per matchup
+type Report struct {
+	Source     string
+	Generated  string
+	Tournament *TournamentSection
+	Matchups   []*MatchupSection
+}
+
+// TournamentSection summarizes a tournament_summary.json
+type TournamentSection struct {
+	Partial   bool
+	Standings []tournament.StandingsRow
+	Heatmap   template.HTML
+	WinRates  template.HTML
+}
+
+// MatchupSection summarizes the simulation_summary.json (and round_aggregates.csv) of a matchup
+type MatchupSection struct {
+	Title       string
+	Dir         string
+	Partial     bool
+	Games       int64
+	AvgRounds   float64
+	OTRate      float64
+	Rows        []StatRow
+	WinRates    template.HTML
+	Funds       template.HTML
+	Equipment   template.HTML
+	ScoreLines  template.HTML
+	RoundCounts template.HTML
+}
+
+// StatRow is a line of the matchup statistics table
+type StatRow struct {
+	Label string
+	Team1 string
+	Team2 string
+}
+
+// Build reads the exports in dir: a batch export directory (simulation_summary.json) or a
+// tournament export directory (matchup folders plus results_*/tournament_summary.json)
+func Build(dir string) (*Report, error) {
+	r := &Report{Source: dir, Generated: time.Now().Format("2006-01-02 15:04:05")}
+
+	if path := findTournamentSummary(dir); path != "" {
+		summary, err := analysis.LoadTournamentSummary(path)
+		if err != nil {
+			return nil, err
+		}
+		r.Tournament = tournamentSection(summary)
+
+		// Matchup folders are next to the results directory (or in dir itself)
+		roots := []string{dir}
+		if filepath.Dir(path) == filepath.Clean(dir) {
+			roots = append(roots, filepath.Dir(filepath.Clean(dir)))
+		}
+		for _, root := range roots {
+			folders, _ := filepath.Glob(filepath.Join(root, "matchup_*"))
+			sort.Strings(folders)
+			for _, folder := range folders {
+				if section, err := matchupSection(folder); err == nil {
+					r.Matchups = append(r.Matchups, section)
+				}
+			}
+			if len(r.Matchups) > 0 {
+				break
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "simulation_summary.json")); err == nil {
+		section, err := matchupSection(dir)
+		if err != nil {
+			return nil, err
+		}
+		r.Matchups = append(r.Matchups, section)
+	}
+
+	if r.Tournament == nil && len(r.Matchups) == 0 {
+		return nil, fmt.Errorf("no simulation_summary.json or tournament_summary.json found in %s", dir)
+	}
+	return r, nil
+}
+
+// Render writes the report as HTML
+func Render(w io.Writer, r *Report) error {
+	return reportTemplate.Execute(w, r)
+}
+
+// WriteFile builds the report of dir and writes it to path
+func WriteFile(dir, path string) error {
+	r, err := Build(dir)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Render(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// findTournamentSummary returns dir/tournament_summary.json or the one of the newest
+// results_* directory in dir ("" if there is none)
+func findTournamentSummary(dir string) string {
+	path := filepath.Join(dir, "tournament_summary.json")
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "results_*", "tournament_summary.json"))
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Strings(matches) // Timestamped names sort chronologically
+	return matches[len(matches)-1]
+}
+
+func tournamentSection(summary *analysis.TournamentSummary) *TournamentSection {
+	t := &TournamentSection{Partial: summary.Partial, Standings: summary.Standings.Rows}
+
+	names := make([]string, len(summary.Standings.Rows))
+	index := make(map[string]int, len(names))
+	for i, row := range summary.Standings.Rows {
+		names[i] = row.Strategy
+		index[row.Strategy] = i
+	}
+	wins := make([][]float64, len(names))
+	games := make([][]float64, len(names))
+	for i := range names {
+		wins[i] = make([]float64, len(names))
+		games[i] = make([]float64, len(names))
+	}
+
+	var items []CIItem
+	for _, ser := range summary.Series {
+		sum := ser.Summary
+		i, ok1 := index[ser.Match.Team1Strategy]
+		j, ok2 := index[ser.Match.Team2Strategy]
+		if ok1 && ok2 {
+			wins[i][j] += float64(sum.Team1Wins)
+			games[i][j] += float64(sum.Games)
+			wins[j][i] += float64(sum.Team2Wins)
+			games[j][i] += float64(sum.Games)
+		}
+
+		low, high := tournament.WilsonInterval(sum.Team1Wins, sum.Games, ciConfidence)
+		if p := ser.Precision; p != nil {
+			low, high = p.CILow, p.CIHigh
+		}
+		items = append(items, CIItem{
+			Label: ser.Match.Team1Strategy + " vs " + ser.Match.Team2Strategy,
+			Value: sum.Team1WinRate * 100,
+			Low:   low * 100,
+			High:  high * 100,
+		})
+	}
+
+	rates := make([][]float64, len(names))
+	for i := range names {
+		rates[i] = make([]float64, len(names))
+		for j := range names {
+			rates[i][j] = math.NaN()
+			if i != j && games[i][j] > 0 {
+				rates[i][j] = wins[i][j] / games[i][j] * 100
+			}
+		}
+	}
+	t.Heatmap = Heatmap("Win rate of row vs column", names, rates)
+	t.WinRates = CIChart("Team 1 win rate per matchup", items)
+	return t
+}
+
+func matchupSection(dir string) (*MatchupSection, error) {
+	stats, err := analysis.LoadSummary(filepath.Join(dir, "simulation_summary.json"))
+	if err != nil {
+		return nil, err
+	}
+	stats.CalculateFinalStats()
+
+	team1, team2 := "Team 1", "Team 2"
+	if stats.Config != nil {
+		team1, team2 = stats.Config.Team1Strategy, stats.Config.Team2Strategy
+	}
+	m := &MatchupSection{
+		Title:     team1 + " vs " + team2,
+		Dir:       dir,
+		Partial:   stats.Partial,
+		Games:     stats.CompletedSims,
+		AvgRounds: stats.AverageRounds,
+		OTRate:    stats.OvertimeRate,
+	}
+	pct := func(v float64) string { return fmt.Sprintf("%.1f%%", v) }
+	interval := func(i analysis.Interval) string { return fmt.Sprintf("[%.1f%%, %.1f%%]", i.Low, i.High) }
+
+	m.Rows = []StatRow{
+		{"Strategy", team1, team2},
+		{"Wins", strconv.FormatInt(stats.Team1Wins, 10), strconv.FormatInt(stats.Team2Wins, 10)},
+		{"Win rate", pct(stats.Team1WinRate), pct(stats.Team2WinRate)},
+		{"Regular time win rate", pct(stats.Team1RTWinRate), pct(stats.Team2RTWinRate)},
+		{"Overtime win rate", pct(stats.Team1OTWinRate), pct(stats.Team2OTWinRate)},
+	}
+	if stats.Draws > 0 {
+		draws := fmt.Sprintf("%d (%.1f%%)", stats.Draws, stats.DrawRate)
+		m.Rows = append(m.Rows, StatRow{"Draws", draws, draws})
+	}
+
+	low1, high1 := tournament.WilsonInterval(int(stats.Team1Wins), int(stats.CompletedSims), ciConfidence)
+	low2, high2 := tournament.WilsonInterval(int(stats.Team2Wins), int(stats.CompletedSims), ciConfidence)
+	items := []CIItem{
+		{Label: team1, Value: stats.Team1WinRate, Low: low1 * 100, High: high1 * 100},
+		{Label: team2, Value: stats.Team2WinRate, Low: low2 * 100, High: high2 * 100},
+	}
+
+	if a := stats.Aggregates; a != nil && a.Team1 != nil && a.Team2 != nil {
+		t1, t2 := a.Team1, a.Team2
+		items[0].Low, items[0].High = t1.WinRateWilson.Low, t1.WinRateWilson.High
+		items[1].Low, items[1].High = t2.WinRateWilson.Low, t2.WinRateWilson.High
+		m.Rows = append(m.Rows,
+			StatRow{"Win rate CI (Wilson)", interval(t1.WinRateWilson), interval(t2.WinRateWilson)},
+			StatRow{"Win rate CI (Clopper-Pearson)", interval(t1.WinRateClopperPearson), interval(t2.WinRateClopperPearson)},
+			StatRow{"CT round win rate", pct(t1.CTRoundWinRate), pct(t2.CTRoundWinRate)},
+			StatRow{"T round win rate", pct(t1.TRoundWinRate), pct(t2.TRoundWinRate)},
+			StatRow{"Funds at round start (p50 / p95)", quantilePair(t1.FundsQuantiles), quantilePair(t2.FundsQuantiles)},
+			StatRow{"Spent per round (p50 / p95)", quantilePair(t1.SpentQuantiles), quantilePair(t2.SpentQuantiles)},
+			StatRow{"Equipment after buy (p50 / p95)", quantilePair(t1.EquipmentQuantiles), quantilePair(t2.EquipmentQuantiles)},
+		)
+
+		var labels []string
+		var values []float64
+		for i, line := range a.ScoreLines {
+			if i == 20 {
+				break
+			}
+			labels = append(labels, line.Score)
+			values = append(values, line.Frequency)
+		}
+		m.ScoreLines = BarChart("Most frequent score lines", "% of games", labels, values)
+
+		rounds := make([]int, 0, len(a.RoundCounts))
+		for r := range a.RoundCounts {
+			rounds = append(rounds, r)
+		}
+		sort.Ints(rounds)
+		labels, values = labels[:0], values[:0]
+		for _, r := range rounds {
+			labels = append(labels, strconv.Itoa(r))
+			values = append(values, float64(a.RoundCounts[r]))
+		}
+		m.RoundCounts = BarChart("Rounds per game", "games", labels, values)
+	}
+	m.WinRates = CIChart("Win rate with 95% confidence interval", items)
+
+	if table := loadRoundTable(dir); table != nil {
+		var funds, equipment []LineSeries
+		for team, name := range []string{team1, team2} {
+			means := table.MeansByRound(team + 1)
+			f := LineSeries{Name: name}
+			e := LineSeries{Name: name}
+			for _, rm := range means {
+				f.X, f.Y = append(f.X, float64(rm.RoundNumber)), append(f.Y, rm.FundsStart)
+				e.X, e.Y = append(e.X, float64(rm.RoundNumber)), append(e.Y, rm.FTEEq)
+			}
+			funds = append(funds, f)
+			equipment = append(equipment, e)
+		}
+		m.Funds = LineChart("Mean funds at round start", "round", "funds", funds)
+		m.Equipment = LineChart("Mean equipment after the buy", "round", "equipment", equipment)
+	}
+	return m, nil
+}
+
+// loadRoundTable reads the (possibly compressed) round aggregate table of dir, or returns nil
+func loadRoundTable(dir string) *analysis.RoundTable {
+	for _, ext := range []string{"", ".gz", ".zst"} {
+		path := filepath.Join(dir, analysis.RoundTableFile+ext)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if table, err := analysis.ReadRoundTable(path); err == nil {
+			return table
+		}
+	}
+	return nil
+}
+
+func quantilePair(q analysis.Quantiles) string {
+	return fmt.Sprintf("%.0f / %.0f", q.P50, q.P95)
+}
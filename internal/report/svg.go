@@ -0,0 +1,270 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Charts are rendered as inline SVG strings so the report needs no scripts or external files.
+// All text is escaped before it is written into the markup.
+
+const (
+	chartWidth  = 760
+	chartHeight = 320
+	marginLeft  = 64
+	marginRight = 16
+	marginTop   = 16
+	marginBot   = 48
+)
+
+var palette = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b"}
+
+// LineSeries is one line of a line chart
+type LineSeries struct {
+	Name string
+	X    []float64
+	Y    []float64
+}
+
+// CIItem is a bar with a confidence interval (values in percent)
+type CIItem struct {
+	Label string
+	Value float64
+	Low   float64
+	High  float64
+}
+
+// scale maps a data range onto a pixel range
+type scale struct {
+	d0, d1, p0, p1 float64
+}
+
+func (s scale) at(v float64) float64 {
+	if s.d1 == s.d0 {
+		return (s.p0 + s.p1) / 2
+	}
+	return s.p0 + (v-s.d0)/(s.d1-s.d0)*(s.p1-s.p0)
+}
+
+// niceTicks returns about n evenly spaced round values covering [lo, hi]
+func niceTicks(lo, hi float64, n int) []float64 {
+	if hi <= lo {
+		hi = lo + 1
+	}
+	raw := (hi - lo) / float64(n)
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := mag
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if m*mag >= raw {
+			step = m * mag
+			break
+		}
+	}
+	var ticks []float64
+	for v := math.Floor(lo/step) * step; v <= hi+step/2; v += step {
+		ticks = append(ticks, v)
+	}
+	return ticks
+}
+
+func formatTick(v float64) string {
+	switch {
+	case math.Abs(v) >= 10000:
+		return fmt.Sprintf("%.0fk", v/1000)
+	case v == math.Trunc(v):
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+type svgBuilder struct {
+	strings.Builder
+}
+
+func newSVG(width, height int, title string) *svgBuilder {
+	b := &svgBuilder{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" role="img" aria-label="%s">`,
+		width, height, width, height, esc(title))
+	return b
+}
+
+func (b *svgBuilder) html() template.HTML {
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// yAxis draws horizontal grid lines with labels and returns the ticks' scale
+func (b *svgBuilder) yAxis(y scale, ticks []float64, label string) {
+	for _, t := range ticks {
+		py := y.at(t)
+		fmt.Fprintf(b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" class="grid"/>`, marginLeft, chartWidth-marginRight, py, py)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, marginLeft-6, py+4, formatTick(t))
+	}
+	if label != "" {
+		fmt.Fprintf(b, `<text x="14" y="%d" class="axis" transform="rotate(-90 14 %d)" text-anchor="middle">%s</text>`,
+			(marginTop+chartHeight-marginBot)/2, (marginTop+chartHeight-marginBot)/2, esc(label))
+	}
+}
+
+func (b *svgBuilder) legend(names []string) {
+	x := marginLeft + 8
+	for i, name := range names {
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`, x, marginTop, palette[i%len(palette)])
+		fmt.Fprintf(b, `<text x="%d" y="%d" class="tick">%s</text>`, x+16, marginTop+10, esc(name))
+		x += 28 + 7*len(name)
+	}
+}
+
+// LineChart draws one line per series
+func LineChart(title, xLabel, yLabel string, series []LineSeries) template.HTML {
+	minX, maxX, maxY := math.Inf(1), math.Inf(-1), 0.0
+	for _, s := range series {
+		for i := range s.X {
+			minX = math.Min(minX, s.X[i])
+			maxX = math.Max(maxX, s.X[i])
+			maxY = math.Max(maxY, s.Y[i])
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return ""
+	}
+	yTicks := niceTicks(0, maxY, 5)
+	x := scale{minX, maxX, marginLeft, chartWidth - marginRight}
+	y := scale{0, yTicks[len(yTicks)-1], chartHeight - marginBot, marginTop + 20}
+
+	b := newSVG(chartWidth, chartHeight, title)
+	b.yAxis(y, yTicks, yLabel)
+	for _, t := range niceTicks(minX, maxX, 10) {
+		if t < minX || t > maxX {
+			continue
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, x.at(t), chartHeight-marginBot+16, formatTick(t))
+	}
+	fmt.Fprintf(b, `<text x="%d" y="%d" class="axis" text-anchor="middle">%s</text>`, (marginLeft+chartWidth-marginRight)/2, chartHeight-8, esc(xLabel))
+
+	names := make([]string, len(series))
+	for i, s := range series {
+		names[i] = s.Name
+		var points []string
+		for j := range s.X {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x.at(s.X[j]), y.at(s.Y[j])))
+		}
+		fmt.Fprintf(b, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, palette[i%len(palette)], strings.Join(points, " "))
+	}
+	b.legend(names)
+	return b.html()
+}
+
+// BarChart draws one vertical bar per label
+func BarChart(title, yLabel string, labels []string, values []float64) template.HTML {
+	if len(values) == 0 {
+		return ""
+	}
+	maxY := 0.0
+	for _, v := range values {
+		maxY = math.Max(maxY, v)
+	}
+	yTicks := niceTicks(0, maxY, 5)
+	y := scale{0, yTicks[len(yTicks)-1], chartHeight - marginBot, marginTop}
+	slot := float64(chartWidth-marginLeft-marginRight) / float64(len(values))
+
+	b := newSVG(chartWidth, chartHeight, title)
+	b.yAxis(y, yTicks, yLabel)
+	for i, v := range values {
+		x := float64(marginLeft) + float64(i)*slot
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s</title></rect>`,
+			x+slot*0.1, y.at(v), slot*0.8, y.at(0)-y.at(v), palette[0], esc(labels[i]), formatTick(v))
+		lx := x + slot/2
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" class="tick" text-anchor="end" transform="rotate(-45 %.1f %d)">%s</text>`,
+			lx, chartHeight-marginBot+14, lx, chartHeight-marginBot+14, esc(labels[i]))
+	}
+	return b.html()
+}
+
+// CIChart draws horizontal bars from 0 to 100% with their confidence interval as whiskers
+// and a reference line at 50%
+func CIChart(title string, items []CIItem) template.HTML {
+	if len(items) == 0 {
+		return ""
+	}
+	const rowHeight, labelWidth = 26, 260
+	height := marginTop + rowHeight*len(items) + 32
+	x := scale{0, 100, labelWidth, chartWidth - marginRight}
+
+	b := newSVG(chartWidth, height, title)
+	for _, t := range []float64{0, 25, 50, 75, 100} {
+		px := x.at(t)
+		class := "grid"
+		if t == 50 {
+			class = "ref"
+		}
+		fmt.Fprintf(b, `<line x1="%.1f" x2="%.1f" y1="%d" y2="%d" class="%s"/>`, px, px, marginTop, height-28, class)
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%.0f%%</text>`, px, height-12, t)
+	}
+	for i, it := range items {
+		top := float64(marginTop + i*rowHeight)
+		mid := top + rowHeight/2
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, labelWidth-8, mid+4, esc(it.Label))
+		fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s" opacity="0.75"><title>%.1f%% [%.1f%%, %.1f%%]</title></rect>`,
+			x.at(0), top+5, x.at(it.Value)-x.at(0), rowHeight-10, palette[i%2], it.Value, it.Low, it.High)
+		fmt.Fprintf(b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" class="ci"/>`, x.at(it.Low), x.at(it.High), mid, mid)
+		for _, v := range []float64{it.Low, it.High} {
+			fmt.Fprintf(b, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" class="ci"/>`, x.at(v), x.at(v), mid-6, mid+6)
+		}
+	}
+	return b.html()
+}
+
+// Heatmap draws a matrix of win rates in percent (NaN for empty cells); rows and columns
+// share the names
+func Heatmap(title string, names []string, values [][]float64) template.HTML {
+	n := len(names)
+	if n == 0 {
+		return ""
+	}
+	const labelWidth = 160
+	cell := math.Min(56, float64(chartWidth-labelWidth-marginRight)/float64(n))
+	width := labelWidth + int(cell*float64(n)) + marginRight
+	height := labelWidth + int(cell*float64(n)) + 8
+
+	b := newSVG(width, height, title)
+	for j, name := range names {
+		cx := labelWidth + cell*float64(j) + cell/2
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" class="tick" transform="rotate(-60 %.1f %d)">%s</text>`,
+			cx, labelWidth-6, cx, labelWidth-6, esc(name))
+	}
+	for i, name := range names {
+		top := float64(labelWidth) + cell*float64(i)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, labelWidth-6, top+cell/2+4, esc(name))
+		for j := range names {
+			v := values[i][j]
+			left := float64(labelWidth) + cell*float64(j)
+			if math.IsNaN(v) {
+				fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#eee"/>`, left, top, cell, cell)
+				continue
+			}
+			fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s vs %s: %.1f%%</title></rect>`,
+				left, top, cell, cell, heatColor(v), esc(name), esc(names[j]), v)
+			fmt.Fprintf(b, `<text x="%.1f" y="%.1f" class="cell" text-anchor="middle">%.0f</text>`, left+cell/2, top+cell/2+4, v)
+		}
+	}
+	return b.html()
+}
+
+// heatColor maps 0..100% onto red - white - blue
+func heatColor(v float64) string {
+	t := math.Max(0, math.Min(1, v/100))
+	lerp := func(a, b, t float64) int { return int(math.Round(a + (b-a)*t)) }
+	if t < 0.5 {
+		u := t * 2
+		return fmt.Sprintf("rgb(%d,%d,%d)", lerp(214, 255, u), lerp(39, 255, u), lerp(40, 255, u))
+	}
+	u := (t - 0.5) * 2
+	return fmt.Sprintf("rgb(%d,%d,%d)", lerp(255, 31, u), lerp(255, 119, u), lerp(255, 180, u))
+}
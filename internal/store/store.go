@@ -0,0 +1,322 @@
+package store
+
+import (
+	"database/sql"
+	"dbg_abm/internal/engine"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver
+)
+
+// Run statuses
+const (
+	StatusRunning     = "running"
+	StatusCompleted   = "completed"
+	StatusInterrupted = "interrupted"
+	StatusFailed      = "failed"
+)
+
+// schema creates the tables of a results database. Every CLI invocation is a run; a run
+// has one matchup (batch mode) or one per tournament pairing; games and rounds hang off
+// their matchup. The views aggregate the games for quick queries.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id                 INTEGER PRIMARY KEY,
+	kind               TEXT NOT NULL,            -- batch or tournament
+	status             TEXT NOT NULL,            -- running, completed, interrupted, failed
+	started_at         TEXT NOT NULL,
+	finished_at        TEXT,
+	export_dir         TEXT,
+	command_line       TEXT,
+	git_version        TEXT,
+	go_version         TEXT,
+	seed               INTEGER,
+	rules_hash         TEXT,
+	rules_json         TEXT,
+	distributions_hash TEXT,
+	config_json        TEXT
+);
+CREATE TABLE IF NOT EXISTS matchups (
+	id             INTEGER PRIMARY KEY,
+	run_id         INTEGER NOT NULL REFERENCES runs(id),
+	matchup_index  INTEGER NOT NULL,
+	team1_name     TEXT NOT NULL,
+	team1_strategy TEXT NOT NULL,
+	team2_name     TEXT NOT NULL,
+	team2_strategy TEXT NOT NULL,
+	UNIQUE (run_id, matchup_index)
+);
+CREATE TABLE IF NOT EXISTS games (
+	id          INTEGER PRIMARY KEY,
+	matchup_id  INTEGER NOT NULL REFERENCES matchups(id),
+	game_id     TEXT NOT NULL,
+	seed        INTEGER,
+	team1_score INTEGER NOT NULL,
+	team2_score INTEGER NOT NULL,
+	team1_won   INTEGER NOT NULL,
+	rounds      INTEGER NOT NULL,
+	ot_count    INTEGER NOT NULL,
+	draw        INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS games_matchup ON games(matchup_id);
+CREATE TABLE IF NOT EXISTS rounds (
+	game_id              INTEGER NOT NULL REFERENCES games(id),
+	round_number         INTEGER NOT NULL,
+	is_t1_ct             INTEGER NOT NULL,
+	is_t1_winner         INTEGER NOT NULL,
+	is_ot                INTEGER NOT NULL,
+	outcome_reason_code  INTEGER NOT NULL,
+	outcome_bomb_planted INTEGER NOT NULL,
+	t1_score_start       INTEGER NOT NULL,
+	t1_spent             REAL NOT NULL,
+	t1_earned            REAL NOT NULL,
+	t1_funds_start       REAL NOT NULL,
+	t1_rs_eq             REAL NOT NULL,
+	t1_fte_eq            REAL NOT NULL,
+	t1_re_eq             REAL NOT NULL,
+	t1_survivors         INTEGER NOT NULL,
+	t1_loss_bonus_level  INTEGER NOT NULL,
+	t2_score_start       INTEGER NOT NULL,
+	t2_spent             REAL NOT NULL,
+	t2_earned            REAL NOT NULL,
+	t2_funds_start       REAL NOT NULL,
+	t2_rs_eq             REAL NOT NULL,
+	t2_fte_eq            REAL NOT NULL,
+	t2_re_eq             REAL NOT NULL,
+	t2_survivors         INTEGER NOT NULL,
+	t2_loss_bonus_level  INTEGER NOT NULL,
+	t1_buy_type          TEXT,
+	t2_buy_type          TEXT,
+	PRIMARY KEY (game_id, round_number)
+) WITHOUT ROWID;
+`
+
+// resultsView is recreated after the migrations so that it always uses the current columns
+const resultsView = `
+DROP VIEW IF EXISTS matchup_results;
+CREATE VIEW matchup_results AS
+SELECT m.run_id, m.id AS matchup_id, m.matchup_index,
+	m.team1_strategy, m.team2_strategy,
+	COUNT(g.id) AS games,
+	SUM(g.team1_won) AS team1_wins,
+	COUNT(g.id) - SUM(g.team1_won) - SUM(g.draw) AS team2_wins,
+	SUM(g.draw) AS draws,
+	AVG(g.team1_won) AS team1_win_rate,
+	AVG(g.rounds) AS avg_rounds,
+	AVG(g.ot_count > 0) AS overtime_rate
+FROM matchups m LEFT JOIN games g ON g.matchup_id = m.id
+GROUP BY m.id;
+`
+
+// DB is a results database. It is safe for use by one writer at a time.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens (or creates) the results database at path and makes sure the schema exists
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(10000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create results database schema in %s: %w", path, err)
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to update results database schema in %s: %w", path, err)
+	}
+	if _, err := db.Exec(resultsView); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to update results database schema in %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// migrations adds columns introduced after a table was first created; the columns are
+// appended, so positional inserts work for old and new databases alike
+var migrations = []struct{ table, column, definition string }{
+	{"rounds", "t1_buy_type", "TEXT"},
+	{"rounds", "t2_buy_type", "TEXT"},
+	{"games", "draw", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+func migrate(db *sql.DB) error {
+	for _, m := range migrations {
+		var n int
+		err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, m.table, m.column).Scan(&n)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// RunInfo is the metadata recorded for a run
+type RunInfo struct {
+	Kind      string // batch or tournament
+	ExportDir string
+	Seed      int64
+	Rules     engine.GameRules
+	Config    interface{} // Marshalled to config_json
+}
+
+// StartRun records a new run and returns its id
+func (d *DB) StartRun(info RunInfo) (int64, error) {
+	rulesJSON, err := json.Marshal(info.Rules)
+	if err != nil {
+		return 0, err
+	}
+	configJSON, err := json.Marshal(info.Config)
+	if err != nil {
+		return 0, err
+	}
+	var seed interface{}
+	if info.Seed != 0 {
+		seed = info.Seed
+	}
+	res, err := d.db.Exec(`INSERT INTO runs (kind, status, started_at, export_dir, command_line, git_version, go_version,
+		seed, rules_hash, rules_json, distributions_hash, config_json) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		info.Kind, StatusRunning, time.Now().UTC().Format(time.RFC3339), info.ExportDir, strings.Join(os.Args, " "),
+		gitVersion(), goVersion(), seed, info.Rules.Hash(), string(rulesJSON), engine.DistributionsHash(), string(configJSON))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record run: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// FinishRun sets the final status of a run
+func (d *DB) FinishRun(runID int64, status string) error {
+	_, err := d.db.Exec(`UPDATE runs SET status = ?, finished_at = ? WHERE id = ?`,
+		status, time.Now().UTC().Format(time.RFC3339), runID)
+	return err
+}
+
+// Matchup returns the id of matchup index of a run, creating it on first use
+func (d *DB) Matchup(runID int64, index int, team1Name, team1Strategy, team2Name, team2Strategy string) (int64, error) {
+	_, err := d.db.Exec(`INSERT OR IGNORE INTO matchups (run_id, matchup_index, team1_name, team1_strategy, team2_name, team2_strategy)
+		VALUES (?, ?, ?, ?, ?, ?)`, runID, index, team1Name, team1Strategy, team2Name, team2Strategy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record matchup: %w", err)
+	}
+	var id int64
+	err = d.db.QueryRow(`SELECT id FROM matchups WHERE run_id = ? AND matchup_index = ?`, runID, index).Scan(&id)
+	return id, err
+}
+
+// GameSink writes the games of one matchup inside a single transaction. The games are only
+// visible once Close commits them; Abort discards them (an interrupted batch is re-run on resume).
+type GameSink struct {
+	tx        *sql.Tx
+	matchupID int64
+	game      *sql.Stmt
+	round     *sql.Stmt
+}
+
+// NewGameSink starts a transaction for the games of a matchup
+func (d *DB) NewGameSink(matchupID int64) (*GameSink, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	s := &GameSink{tx: tx, matchupID: matchupID}
+	s.game, err = tx.Prepare(`INSERT INTO games (matchup_id, game_id, seed, team1_score, team2_score, team1_won, rounds, ot_count, draw)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err == nil {
+		s.round, err = tx.Prepare(`INSERT INTO rounds VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteGame inserts a game and its rounds
+func (s *GameSink) WriteGame(game *engine.Game) error {
+	res, err := s.game.Exec(s.matchupID, game.ID, game.Seed, game.Score[0], game.Score[1],
+		game.Outcome.Team1Won(), len(game.Rounds), game.OTcounter, game.Outcome.IsDraw())
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i := range game.Rounds {
+		round := &game.Rounds[i]
+		t1 := game.Team1.RoundData[i]
+		t2 := game.Team2.RoundData[i]
+		if _, err := s.round.Exec(id, round.RoundNumber, round.IsT1CT, round.IsT1WinnerTeam, round.OT,
+			round.Calc_Outcome.ReasonCode, round.Calc_Outcome.BombPlanted,
+			t1.Score_Start, t1.Spent, t1.Earned, t1.Funds_start, t1.RS_Eq_value, t1.FTE_Eq_value, t1.RE_Eq_value, t1.Survivors, t1.LossBonusLevel,
+			t2.Score_Start, t2.Spent, t2.Earned, t2.Funds_start, t2.RS_Eq_value, t2.FTE_Eq_value, t2.RE_Eq_value, t2.Survivors, t2.LossBonusLevel,
+			t1.BuyType, t2.BuyType,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close commits the games written so far
+func (s *GameSink) Close() error {
+	s.game.Close()
+	s.round.Close()
+	return s.tx.Commit()
+}
+
+// Abort discards the games written so far
+func (s *GameSink) Abort() error {
+	s.game.Close()
+	s.round.Close()
+	return s.tx.Rollback()
+}
+
+// gitVersion returns the VCS revision the binary was built from ("+dirty" for modified trees)
+func gitVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var rev, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if rev == "" {
+		return info.Main.Version
+	}
+	if modified == "true" {
+		rev += "+dirty"
+	}
+	return rev
+}
+
+func goVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.GoVersion
+	}
+	return ""
+}
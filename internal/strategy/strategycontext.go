@@ -26,6 +26,8 @@ type StrategyContext_simple struct {
 	EnemySurvivors                     int  // Number of enemy survivors in the previous RoundEndReason
 	RoundEndReason                     int  // Reason for the end of the last round
 	Is_BombPlanted                     bool // Whether the bomb was planted in the last round
+	TimeoutsLeft                       int  // Timeouts the team has left in the current half
+	TimeoutsLeft_opponent              int  // Timeouts the opponent has left in the current half
 	RNG                                *rand.Rand
 	GameRules_strategy                 GameRules_strategymanager
 	Funds_opponent_forbidden           float64 // Opponent funds (technically forbidden, for testing purposes)
@@ -0,0 +1,17 @@
+package strategy
+
+// TimeoutFunc decides before the buy phase whether the team calls a timeout. It is only
+// asked while the team has timeouts left in the current half.
+type TimeoutFunc func(StrategyContext_simple) bool
+
+// TimeoutRegistry maps strategy names to their timeout policies. Strategies without one,
+// currently all of them, use the policy of the game rules (a timeout after
+// callAfterLosses lost rounds in a row), so every strategy is compared under the same
+// timeout behaviour unless it registers its own.
+var TimeoutRegistry = map[string]TimeoutFunc{}
+
+// GetTimeoutPolicy returns the timeout policy of a strategy (false if it has none)
+func GetTimeoutPolicy(name string) (TimeoutFunc, bool) {
+	fn, exists := TimeoutRegistry[name]
+	return fn, exists
+}
@@ -365,3 +365,21 @@ func GetBuyTypeByEncodedValue(encodedValue int) BuyTypeDefinition {
 	}
 	return BuyTypeDefinition{}
 }
+
+// BuyTypes lists the keys of BuyTypeDefinitions from the cheapest to the strongest buy
+var BuyTypes = []string{"eco", "low_buy", "half_buy", "hero_low", "hero_half", "full_buy"}
+
+// ClassifyBuyType returns the buy type (key of BuyTypeDefinitions) of a team-round from its
+// round start equipment and the money spent. Ranges include their minimum and exclude their
+// maximum; the combinations the definitions leave open (e.g. 3k+ equipment and 17-17.5k spent)
+// all add up to more than 20k and count as full buy.
+func ClassifyBuyType(startEquipment, spent float64) string {
+	for _, name := range BuyTypes {
+		profile := BuyTypeDefinitions[name]
+		if startEquipment >= profile.MinStartingEquipment && startEquipment < profile.MaxStartingEquipment &&
+			spent >= profile.MinSpend && spent < profile.MaxSpend {
+			return name
+		}
+	}
+	return "full_buy"
+}
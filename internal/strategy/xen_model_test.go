@@ -0,0 +1,39 @@
+package strategy
+
+import "testing"
+
+func TestClassifyBuyType(t *testing.T) {
+	tests := []struct {
+		equipment, spent float64
+		want             string
+	}{
+		{0, 0, "eco"},
+		{2999, 1999, "eco"},
+		{0, 2000, "low_buy"}, // Minimums are included
+		{2999, 7499, "low_buy"},
+		{0, 7500, "half_buy"},
+		{2999, 19999, "half_buy"},
+		{0, 20000, "full_buy"}, // Beyond the spend range of the low equipment buys
+		{3000, 0, "hero_low"},
+		{19999, 7499, "hero_low"},
+		{3000, 7500, "hero_half"},
+		{19999, 16999, "hero_half"},
+		{3000, 17000, "full_buy"}, // Between hero half and full buy
+		{3000, 17500, "full_buy"},
+		{20000, 0, "full_buy"}, // Maximums are excluded
+	}
+	for _, tt := range tests {
+		if got := ClassifyBuyType(tt.equipment, tt.spent); got != tt.want {
+			t.Errorf("ClassifyBuyType(%g, %g) = %s, want %s", tt.equipment, tt.spent, got, tt.want)
+		}
+	}
+
+	for _, name := range BuyTypes {
+		if _, ok := BuyTypeDefinitions[name]; !ok {
+			t.Errorf("buy type %s has no definition", name)
+		}
+	}
+	if len(BuyTypes) != len(BuyTypeDefinitions) {
+		t.Errorf("%d buy types listed, %d defined", len(BuyTypes), len(BuyTypeDefinitions))
+	}
+}
@@ -0,0 +1,285 @@
+package tournament
+
+import (
+	"dbg_abm/internal/engine"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ManifestFile is the name of the tournament checkpoint manifest inside the checkpoint directory
+const ManifestFile = "tournament_manifest.json"
+
+// DefaultCheckpointEvery is the number of games a fixed-size matchup plays between two checkpoints
+const DefaultCheckpointEvery = 1000
+
+// Manifest records the progress of a tournament so that an interrupted run can be resumed.
+// Per-game outcomes are kept next to it in checkpoint/matchup_NNN.csv; GamesPlayed is
+// authoritative, rows beyond it (from a batch that never finished) are discarded on resume.
+type Manifest struct {
+	Version           int                 `json:"version"`
+	RulesHash         string              `json:"rules_hash"`
+	DistributionsHash string              `json:"distributions_hash"`
+	Format            Format              `json:"format"`
+	Participants      []string            `json:"participants"`
+	NumGames          int                 `json:"num_games"`
+	Seed              int64               `json:"seed,omitempty"`
+	Sampling          SamplingSpec        `json:"sampling"`
+	Options           map[string]string   `json:"options,omitempty"`
+	Matchups          []MatchupCheckpoint `json:"matchups"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+}
+
+// MatchupCheckpoint is the persisted state of one matchup
+type MatchupCheckpoint struct {
+	Match          MatchSpec `json:"match"`
+	GamesPlayed    int       `json:"games_played"`
+	GamesRequested int       `json:"games_requested,omitempty"` // Games (and seeds) handed to the runner, including failed games
+	Completed      bool      `json:"completed"`
+	StopReason     string    `json:"stop_reason,omitempty"` // Adaptive sampling: why the matchup stopped
+}
+
+// requested returns the games handed to the runner so far, the offset of the next seed
+// (manifests written before GamesRequested existed only know the games played)
+func (mc MatchupCheckpoint) requested() int {
+	return max(mc.GamesRequested, mc.GamesPlayed)
+}
+
+// LoadManifest reads the tournament manifest from a checkpoint directory
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tournament manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse tournament manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// checkpointer keeps the manifest and the per-matchup outcome files up to date.
+// A nil checkpointer is valid and does nothing.
+type checkpointer struct {
+	dir      string
+	manifest Manifest
+}
+
+// newCheckpointer prepares the checkpoint directory. With resume set it loads the
+// existing manifest and verifies that it belongs to the same tournament.
+func newCheckpointer(cfg TournamentConfig, matches []MatchSpec) (*checkpointer, error) {
+	if cfg.CheckpointDir == "" {
+		return nil, nil
+	}
+	cp := &checkpointer{
+		dir: cfg.CheckpointDir,
+		manifest: Manifest{
+			Version:           1,
+			RulesHash:         cfg.Rules.Hash(),
+			DistributionsHash: engine.DistributionsHash(),
+			Format:            cfg.Format,
+			Participants:      cfg.Participants,
+			NumGames:          cfg.Series.NumGames,
+			Seed:              cfg.Series.Seed,
+			Sampling:          cfg.Sampling,
+			Options:           cfg.Options,
+			Matchups:          make([]MatchupCheckpoint, len(matches)),
+		},
+	}
+	for i, m := range matches {
+		cp.manifest.Matchups[i].Match = m
+	}
+
+	if cfg.Resume {
+		prev, err := LoadManifest(cfg.CheckpointDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := cp.manifest.compatible(prev); err != nil {
+			return nil, fmt.Errorf("cannot resume from %s: %w", cfg.CheckpointDir, err)
+		}
+		cp.manifest.Matchups = prev.Matchups
+	} else if err := os.RemoveAll(filepath.Join(cp.dir, "checkpoint")); err != nil {
+		// A fresh run must not append to the outcomes of an earlier one
+		return nil, fmt.Errorf("failed to clear checkpoint directory: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(cp.dir, "checkpoint"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return cp, cp.save()
+}
+
+// compatible reports why prev cannot be resumed under the manifest m (nil if it can)
+func (m Manifest) compatible(prev *Manifest) error {
+	switch {
+	case prev.RulesHash != m.RulesHash:
+		return fmt.Errorf("game rules changed (hash %.12s, manifest has %.12s)", m.RulesHash, prev.RulesHash)
+	case prev.DistributionsHash != m.DistributionsHash:
+		return fmt.Errorf("distributions changed (hash %.12s, manifest has %.12s)", m.DistributionsHash, prev.DistributionsHash)
+	case prev.Format != m.Format:
+		return fmt.Errorf("format changed (%s, manifest has %s)", m.Format, prev.Format)
+	case prev.NumGames != m.NumGames:
+		return fmt.Errorf("games per matchup changed (%d, manifest has %d)", m.NumGames, prev.NumGames)
+	case prev.Seed != m.Seed:
+		return fmt.Errorf("seed changed (%d, manifest has %d)", m.Seed, prev.Seed)
+	case prev.Sampling != m.Sampling:
+		return fmt.Errorf("sampling changed (%s, manifest has %s)", m.Sampling.Mode, prev.Sampling.Mode)
+	case len(prev.Options) != len(m.Options):
+		return fmt.Errorf("options changed (%v, manifest has %v)", m.Options, prev.Options)
+	case len(prev.Matchups) != len(m.Matchups):
+		return fmt.Errorf("participants changed (%d matchups, manifest has %d)", len(m.Matchups), len(prev.Matchups))
+	}
+	for k, v := range m.Options {
+		if prev.Options[k] != v {
+			return fmt.Errorf("option %s changed (%s, manifest has %s)", k, v, prev.Options[k])
+		}
+	}
+	for i := range m.Matchups {
+		if prev.Matchups[i].Match != m.Matchups[i].Match {
+			return fmt.Errorf("participants changed (matchup %d is %s vs %s, manifest has %s vs %s)", i+1,
+				m.Matchups[i].Match.Team1Strategy, m.Matchups[i].Match.Team2Strategy,
+				prev.Matchups[i].Match.Team1Strategy, prev.Matchups[i].Match.Team2Strategy)
+		}
+	}
+	return nil
+}
+
+// state returns the games a matchup already played in an earlier run
+func (cp *checkpointer) state(i int) (MatchupCheckpoint, []GameOutcome, error) {
+	if cp == nil {
+		return MatchupCheckpoint{}, nil, nil
+	}
+	mc := cp.manifest.Matchups[i]
+	if mc.GamesPlayed == 0 {
+		return mc, nil, nil
+	}
+	games, err := readOutcomes(cp.outcomePath(i), mc.GamesPlayed)
+	if err != nil {
+		return mc, nil, err
+	}
+	if len(games) < mc.GamesPlayed {
+		return mc, nil, fmt.Errorf("checkpoint for matchup %d has %d games, manifest expects %d", i+1, len(games), mc.GamesPlayed)
+	}
+	// Drop rows of a batch that was interrupted before the manifest was updated
+	if err := writeOutcomes(cp.outcomePath(i), games, false); err != nil {
+		return mc, nil, err
+	}
+	return mc, games, nil
+}
+
+// record appends a finished batch of matchup i, for which the runner was asked to play
+// requested games, and updates the manifest
+func (cp *checkpointer) record(i int, batch []GameOutcome, requested int, completed bool, stopReason string) error {
+	if cp == nil {
+		return nil
+	}
+	if err := writeOutcomes(cp.outcomePath(i), batch, true); err != nil {
+		return fmt.Errorf("failed to checkpoint matchup %d: %w", i+1, err)
+	}
+	mc := &cp.manifest.Matchups[i]
+	mc.GamesRequested = mc.requested() + requested
+	mc.GamesPlayed += len(batch)
+	mc.Completed = completed
+	mc.StopReason = stopReason
+	return cp.save()
+}
+
+// save writes the manifest atomically (temp file + rename)
+func (cp *checkpointer) save() error {
+	cp.manifest.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(cp.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(cp.dir, ManifestFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tournament manifest: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (cp *checkpointer) outcomePath(i int) string {
+	return filepath.Join(cp.dir, "checkpoint", fmt.Sprintf("matchup_%03d.csv", i+1))
+}
+
+// writeOutcomes writes game outcomes as CSV rows (t1_won, team1_score, team2_score, rounds, ot_count, seed, draw)
+func writeOutcomes(path string, games []GameOutcome, appendRows bool) error {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendRows {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	for _, g := range games {
+		w.Write([]string{
+			strconv.FormatBool(g.T1Wins),
+			strconv.Itoa(g.Score[0]),
+			strconv.Itoa(g.Score[1]),
+			strconv.Itoa(g.Rounds),
+			strconv.Itoa(g.OTCount),
+			strconv.FormatInt(g.Seed, 10),
+			strconv.FormatBool(g.Draw),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// readOutcomes reads up to limit game outcomes written by writeOutcomes
+func readOutcomes(path string, limit int) ([]GameOutcome, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1 // Checkpoints written before draws existed have no draw column
+	games := make([]GameOutcome, 0, limit)
+	for len(games) < limit {
+		rec, err := r.Read()
+		if err != nil || len(rec) < 6 || len(rec) > 7 {
+			// A torn last row from a crash ends the readable part of the file
+			break
+		}
+		var g GameOutcome
+		var perr error
+		parseInt := func(s string) int {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				perr = err
+			}
+			return v
+		}
+		g.T1Wins, err = strconv.ParseBool(rec[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid row %d in %s: %w", len(games)+1, path, err)
+		}
+		g.Score = [2]int{parseInt(rec[1]), parseInt(rec[2])}
+		g.Rounds = parseInt(rec[3])
+		g.OTCount = parseInt(rec[4])
+		g.Seed, err = strconv.ParseInt(rec[5], 10, 64)
+		if len(rec) == 7 && err == nil {
+			g.Draw, err = strconv.ParseBool(rec[6])
+		}
+		if perr != nil || err != nil {
+			return nil, fmt.Errorf("invalid row %d in %s", len(games)+1, path)
+		}
+		games = append(games, g)
+	}
+	return games, nil
+}
@@ -0,0 +1,356 @@
+package tournament
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"dbg_abm/internal/strategy"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MatchupRunner plays all games of one matchup and returns their outcomes.
+// index is the 0-based position of the matchup in the schedule. With adaptive
+// sampling or checkpointing a matchup is played in several batches; spec.Offset
+// is then the number of games already requested for the matchup (failed games
+// included), so that no seed is played twice. A runner may return fewer games than
+// spec.NumGames when games fail.
+type MatchupRunner func(ctx context.Context, index int, m MatchSpec, rules engine.GameRules, spec SeriesSpec) (SeriesResult, error)
+
+// TournamentConfig describes a tournament run by Run
+type TournamentConfig struct {
+	Format       Format
+	Series       SeriesSpec
+	Participants []string         // strategy names
+	Rules        engine.GameRules // Game rules used for every game
+	Sampling     SamplingSpec     // Fixed (default) or adaptive games per matchup
+
+	// Runner plays a single matchup. Defaults to RunMatchup (in-process engine games).
+	// The CLI plugs in its own runner to reuse the worker pool and CSV exports.
+	Runner MatchupRunner
+
+	// Progress, if set, receives an Event for every step of the tournament.
+	// Sends are blocking, so the receiver must keep draining it until Run returns.
+	Progress chan<- Event
+
+	// CheckpointDir, if set, receives a manifest and the per-game outcomes after every
+	// batch. With Resume the tournament continues from the manifest found there.
+	CheckpointDir   string
+	Resume          bool
+	CheckpointEvery int // Games per batch for fixed-size matchups when checkpointing (default 1000)
+
+	// Options are caller settings stored in the manifest that must match on resume
+	// (e.g. the CLI's CSV export mode)
+	Options map[string]string
+}
+
+// EventType identifies a tournament progress event
+type EventType string
+
+const (
+	EventTournamentStart EventType = "tournament_start"
+	EventMatchupStart    EventType = "matchup_start"
+	EventMatchupDone     EventType = "matchup_done"
+	EventMatchupTopUp    EventType = "matchup_top_up" // Adaptive sampling gave a matchup extra games
+	EventTournamentDone  EventType = "tournament_done"
+)
+
+// Event reports tournament progress to the caller of Run
+type Event struct {
+	Type         EventType
+	Matchup      int // 0-based index into Results.Matches (-1 for tournament-level events)
+	TotalMatchup int
+	Match        MatchSpec
+	Summary      *SeriesSummary // Set for EventMatchupDone
+	Resumed      bool           // The matchup was (partly) restored from a checkpoint
+}
+
+// Results holds everything a tournament produced
+type Results struct {
+	Participants []string       `json:"participants"`
+	Matches      []MatchSpec    `json:"matches"`
+	Series       []SeriesResult `json:"series"`
+	Standings    Standings      `json:"standings"`
+}
+
+// ParseParticipants splits a comma-separated strategy list, dropping empty entries
+func ParseParticipants(csv string) []string {
+	list := []string{}
+	for _, s := range strings.Split(csv, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		list = append(list, s)
+	}
+	return list
+}
+
+// Validate checks the participants and format before any game is played
+func (c TournamentConfig) Validate() error {
+	if len(c.Participants) < 2 {
+		return fmt.Errorf("need at least two strategies for a tournament")
+	}
+	seen := make(map[string]bool, len(c.Participants))
+	for _, p := range c.Participants {
+		if err := strategy.ValidateStrategy(p); err != nil {
+			return err
+		}
+		if seen[p] {
+			return fmt.Errorf("strategy '%s' is listed more than once", p)
+		}
+		seen[p] = true
+	}
+	if _, err := Schedule(c.Format, c.Participants); err != nil {
+		return err
+	}
+	return c.Sampling.Validate()
+}
+
+// Run plays a complete tournament: it schedules the matchups, runs each one with
+// the configured runner and computes the standings (series wins, then round differential).
+// On cancellation the matchups finished so far are returned together with ctx.Err().
+func Run(ctx context.Context, cfg TournamentConfig) (*Results, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	matches, _ := Schedule(cfg.Format, cfg.Participants)
+
+	cp, err := newCheckpointer(cfg, matches)
+	if err != nil {
+		return nil, err
+	}
+
+	runner := cfg.Runner
+	if runner == nil {
+		runner = func(ctx context.Context, _ int, m MatchSpec, rules engine.GameRules, spec SeriesSpec) (SeriesResult, error) {
+			return RunMatchup(ctx, m, rules, spec)
+		}
+	}
+
+	res := &Results{
+		Participants: cfg.Participants,
+		Matches:      matches,
+		Series:       make([]SeriesResult, 0, len(matches)),
+	}
+
+	cfg.emit(Event{Type: EventTournamentStart, Matchup: -1, TotalMatchup: len(matches)})
+
+	if cfg.Sampling.Adaptive() {
+		if err := runAdaptive(ctx, cfg, matches, runner, cp, res); err != nil {
+			res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+			return res, err
+		}
+		res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+		cfg.emit(Event{Type: EventTournamentDone, Matchup: -1, TotalMatchup: len(matches)})
+		return res, nil
+	}
+
+	sampling := cfg.Sampling.withDefaults(cfg.Series.NumGames)
+	every := cfg.Series.NumGames
+	if cp != nil {
+		every = cfg.CheckpointEvery
+		if every <= 0 {
+			every = DefaultCheckpointEvery
+		}
+	}
+	for i, m := range matches {
+		if err := ctx.Err(); err != nil {
+			res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+			return res, err
+		}
+		state, played, err := cp.state(i)
+		if err != nil {
+			res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+			return res, err
+		}
+		resumed := state.GamesPlayed > 0
+		cfg.emit(Event{Type: EventMatchupStart, Matchup: i, TotalMatchup: len(matches), Match: m, Resumed: resumed})
+
+		series := SeriesResult{Match: m, GameResults: played}
+		// Seeds continue after the games requested so far, failed games included
+		for offset := state.requested(); !state.Completed && offset < cfg.Series.NumGames; offset += every {
+			spec := cfg.Series
+			spec.Offset = offset
+			spec.NumGames = every
+			if rest := cfg.Series.NumGames - offset; spec.NumGames > rest {
+				spec.NumGames = rest
+			}
+			batch, err := runner(ctx, i, m, cfg.Rules, spec)
+			if err != nil {
+				res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+				return res, fmt.Errorf("matchup %d/%d (%s vs %s): %w", i+1, len(matches), m.Team1Strategy, m.Team2Strategy, err)
+			}
+			series.GameResults = append(series.GameResults, batch.GameResults...)
+			if err := cp.record(i, batch.GameResults, spec.NumGames, offset+spec.NumGames >= cfg.Series.NumGames, ""); err != nil {
+				res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+				return res, err
+			}
+		}
+		series.Match = m
+		series.SeriesWins = seriesWinner(series)
+		precision := sampling.precision(series, "fixed")
+		series.Precision = &precision
+		res.Series = append(res.Series, series)
+
+		sum := series.Summarize()
+		cfg.emit(Event{Type: EventMatchupDone, Matchup: i, TotalMatchup: len(matches), Match: m, Summary: &sum, Resumed: resumed})
+	}
+
+	res.Standings = ComputeSeriesStandings(cfg.Participants, res.Series)
+	cfg.emit(Event{Type: EventTournamentDone, Matchup: -1, TotalMatchup: len(matches)})
+	return res, nil
+}
+
+// runAdaptive plays every matchup in batches until its stopping rule fires or it
+// used its share of the budget (NumGames), then spends the games saved on lopsided
+// matchups on the undecided matchups closest to 50%.
+func runAdaptive(ctx context.Context, cfg TournamentConfig, matches []MatchSpec, runner MatchupRunner, cp *checkpointer, res *Results) error {
+	sampling := cfg.Sampling.withDefaults(cfg.Series.NumGames)
+	budget := cfg.Series.NumGames * len(matches)
+	used := 0
+
+	series := make([]SeriesResult, len(matches))
+	stop := make([]string, len(matches))
+	resumed := make([]bool, len(matches))
+	requested := make([]int, len(matches)) // Games handed to the runner; failed games return no outcome
+	// wins returns Team1's wins and the decisive games of matchup i, draws are left out
+	wins := func(i int) (int, int) {
+		sum := series[i].Summarize()
+		return sum.Team1Wins, sum.Team1Wins + sum.Team2Wins
+	}
+
+	// Restore the batches of an earlier run
+	for i := range matches {
+		state, played, err := cp.state(i)
+		if err != nil {
+			return err
+		}
+		series[i].GameResults = played
+		stop[i] = state.StopReason
+		resumed[i] = state.GamesPlayed > 0
+		requested[i] = state.requested()
+		used += requested[i]
+	}
+
+	// playBatch runs n more games of matchup i and updates its stopping state. A batch
+	// without a single game stops the tournament, it would be requested again forever.
+	playBatch := func(i int, n int) error {
+		spec := cfg.Series
+		spec.NumGames = n
+		spec.Offset = requested[i] // Seeds of failed games are not used again
+		batch, err := runner(ctx, i, matches[i], cfg.Rules, spec)
+		if err != nil {
+			return fmt.Errorf("matchup %d/%d (%s vs %s): %w", i+1, len(matches), matches[i].Team1Strategy, matches[i].Team2Strategy, err)
+		}
+		series[i].GameResults = append(series[i].GameResults, batch.GameResults...)
+		requested[i] += n
+		used += n
+		w, g := wins(i)
+		stop[i] = sampling.stopReason(w, g, requested[i])
+		if err := cp.record(i, batch.GameResults, n, stop[i] != "", stop[i]); err != nil {
+			return err
+		}
+		if len(batch.GameResults) == 0 {
+			return fmt.Errorf("matchup %d/%d (%s vs %s): all %d games of the batch failed", i+1, len(matches), matches[i].Team1Strategy, matches[i].Team2Strategy, n)
+		}
+		return nil
+	}
+
+	finish := func() {
+		res.Series = res.Series[:0]
+		for i := range series {
+			if len(series[i].GameResults) == 0 && stop[i] == "" {
+				continue
+			}
+			reason := stop[i]
+			if reason == "" {
+				reason = "budget"
+			}
+			series[i].Match = matches[i]
+			series[i].SeriesWins = seriesWinner(series[i])
+			precision := sampling.precision(series[i], reason)
+			series[i].Precision = &precision
+			res.Series = append(res.Series, series[i])
+		}
+	}
+	defer finish()
+
+	// Phase 1: every matchup gets up to its fair share of the budget
+	share := cfg.Series.NumGames
+	if share > sampling.MaxGames {
+		share = sampling.MaxGames
+	}
+	for i, m := range matches {
+		cfg.emit(Event{Type: EventMatchupStart, Matchup: i, TotalMatchup: len(matches), Match: m, Resumed: resumed[i]})
+		series[i].Match = m
+		for stop[i] == "" {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			n := share - requested[i]
+			if n <= 0 {
+				break
+			}
+			if n > sampling.BatchSize {
+				n = sampling.BatchSize
+			}
+			if err := playBatch(i, n); err != nil {
+				return err
+			}
+		}
+		sum := series[i].Summarize()
+		cfg.emit(Event{Type: EventMatchupDone, Matchup: i, TotalMatchup: len(matches), Match: m, Summary: &sum, Resumed: resumed[i]})
+	}
+
+	// Phase 2: redistribute the saved budget to the closest undecided matchups
+	for used < budget {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next := -1
+		closest := 1.0
+		for i := range matches {
+			if stop[i] != "" {
+				continue
+			}
+			w, g := wins(i)
+			if g == 0 {
+				continue
+			}
+			if d := math.Abs(float64(w)/float64(g) - 0.5); next == -1 || d < closest {
+				next, closest = i, d
+			}
+		}
+		if next == -1 {
+			break
+		}
+		n := sampling.BatchSize
+		if rest := budget - used; n > rest {
+			n = rest
+		}
+		if rest := sampling.MaxGames - requested[next]; n > rest {
+			n = rest
+		}
+		if n <= 0 {
+			// Failed games used up the matchup's cap before it played MaxGames
+			stop[next] = "max_games"
+			if err := cp.record(next, nil, 0, true, stop[next]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := playBatch(next, n); err != nil {
+			return err
+		}
+		sum := series[next].Summarize()
+		cfg.emit(Event{Type: EventMatchupTopUp, Matchup: next, TotalMatchup: len(matches), Match: matches[next], Summary: &sum})
+	}
+	return nil
+}
+
+func (c TournamentConfig) emit(e Event) {
+	if c.Progress != nil {
+		c.Progress <- e
+	}
+}
@@ -0,0 +1,134 @@
+package tournament
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// lossyRunner returns a runner that drops the last `drop` games of every batch (as if
+// they failed) and records the offsets it was asked to play
+func lossyRunner(drop int) (MatchupRunner, func() [][2]int) {
+	var mu sync.Mutex
+	var calls [][2]int // [offset, numGames]
+	runner := func(_ context.Context, _ int, m MatchSpec, _ engine.GameRules, spec SeriesSpec) (SeriesResult, error) {
+		mu.Lock()
+		calls = append(calls, [2]int{spec.Offset, spec.NumGames})
+		mu.Unlock()
+		res := SeriesResult{Match: m}
+		for i := 0; i < spec.NumGames-drop; i++ {
+			res.GameResults = append(res.GameResults, NewGameOutcome(engine.OutcomeTeam1Win, [2]int{16, 14}, 30, 0, int64(spec.Offset+i)))
+		}
+		return res, nil
+	}
+	return runner, func() [][2]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func adaptiveConfig(runner MatchupRunner) TournamentConfig {
+	return TournamentConfig{
+		Format:       FormatRoundRobin,
+		Series:       SeriesSpec{NumGames: 200, Seed: 1},
+		Participants: []string{"all_in", "half"},
+		Rules:        engine.GameRules{},
+		Sampling:     SamplingSpec{Mode: SamplingWilson, TargetHalfWidth: 0.0001, MinGames: 10, BatchSize: 50},
+		Runner:       runner,
+	}
+}
+
+func TestRunAdaptiveFailedGamesTerminateWithoutSeedReuse(t *testing.T) {
+	runner, calls := lossyRunner(1)
+	res, err := Run(context.Background(), adaptiveConfig(runner))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Every batch starts after the games requested before it, failed ones included
+	next := 0
+	requested := 0
+	for _, c := range calls() {
+		if c[0] != next {
+			t.Fatalf("batch offset %d, want %d (seeds reused)", c[0], next)
+		}
+		next += c[1]
+		requested += c[1]
+	}
+	if requested != 200 {
+		t.Errorf("requested %d games, want the budget of 200", requested)
+	}
+	if got, want := len(res.Series[0].GameResults), 200-len(calls()); got != want {
+		t.Errorf("played %d games, want %d", got, want)
+	}
+}
+
+func TestRunAdaptiveFailedGamesReachMaxGames(t *testing.T) {
+	// Phase 1 requests the whole cap of 100 games but gets 98; phase 2 must stop the
+	// matchup instead of asking for a batch of zero games
+	runner, calls := lossyRunner(1)
+	cfg := adaptiveConfig(runner)
+	cfg.Sampling.MaxGames = 100
+	res, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, c := range calls() {
+		if c[1] <= 0 {
+			t.Fatalf("runner asked for %d games at offset %d", c[1], c[0])
+		}
+	}
+	if len(res.Series) != len(res.Matches) {
+		t.Fatalf("%d series for %d matchups", len(res.Series), len(res.Matches))
+	}
+	for _, s := range res.Series {
+		if s.Precision == nil || s.Precision.StopReason != "max_games" || len(s.GameResults) != 98 {
+			t.Errorf("%s vs %s: %d games, precision %+v; want 98 games stopped at max_games", s.Match.Team1Strategy, s.Match.Team2Strategy, len(s.GameResults), s.Precision)
+		}
+	}
+}
+
+func TestRunAdaptiveStopsWhenBatchReturnsNoGames(t *testing.T) {
+	runner, calls := lossyRunner(1000)
+	_, err := Run(context.Background(), adaptiveConfig(runner))
+	if err == nil || !strings.Contains(err.Error(), "all 50 games of the batch failed") {
+		t.Fatalf("Run error = %v, want a failed batch error", err)
+	}
+	if n := len(calls()); n != 1 {
+		t.Errorf("runner called %d times, want 1", n)
+	}
+}
+
+func TestRunFixedResumeContinuesAfterRequestedGames(t *testing.T) {
+	runner, calls := lossyRunner(1)
+	cfg := adaptiveConfig(runner)
+	cfg.Sampling = SamplingSpec{}
+	cfg.Series.NumGames = 100
+	cfg.CheckpointDir = t.TempDir()
+	cfg.CheckpointEvery = 40
+
+	if _, err := Run(context.Background(), cfg); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	want := [][2]int{{0, 40}, {40, 40}, {80, 20}}
+	got := calls()
+	if len(got) != len(want) {
+		t.Fatalf("batches %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("batches %v, want %v", got, want)
+		}
+	}
+
+	m, err := LoadManifest(cfg.CheckpointDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mc := m.Matchups[0]; mc.GamesRequested != 100 || mc.GamesPlayed != 97 || !mc.Completed {
+		t.Errorf("checkpoint %+v, want 100 requested, 97 played, completed", mc)
+	}
+}
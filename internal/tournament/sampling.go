@@ -0,0 +1,201 @@
+package tournament
+
+import (
+	"fmt"
+	"math"
+)
+
+// SamplingMode selects how many games a matchup gets
+type SamplingMode string
+
+const (
+	SamplingFixed  SamplingMode = "fixed"  // Every matchup plays exactly NumGames
+	SamplingWilson SamplingMode = "wilson" // Stop once the Wilson interval is narrower than TargetHalfWidth
+	SamplingSPRT   SamplingMode = "sprt"   // Stop once a sequential probability ratio test reaches a decision
+)
+
+// SamplingSpec configures adaptive sample allocation. The total budget is
+// SeriesSpec.NumGames per matchup; games saved on lopsided matchups are
+// redistributed to the closest undecided ones.
+type SamplingSpec struct {
+	Mode            SamplingMode `json:"mode"`
+	TargetHalfWidth float64      `json:"target_half_width,omitempty"` // Wilson: stop when the CI half width is at most this (default 0.02)
+	Confidence      float64      `json:"confidence,omitempty"`        // Confidence level of the reported interval (default 0.95)
+	SPRTDelta       float64      `json:"sprt_delta,omitempty"`        // SPRT: tests p=0.5-delta against p=0.5+delta (default 0.05)
+	Alpha           float64      `json:"alpha,omitempty"`             // SPRT type I error (default 0.05)
+	Beta            float64      `json:"beta,omitempty"`              // SPRT type II error (default 0.05)
+	MinGames        int          `json:"min_games,omitempty"`         // Games every matchup plays before a stop is allowed (default 100)
+	MaxGames        int          `json:"max_games,omitempty"`         // Per-matchup cap (default 4x NumGames)
+	BatchSize       int          `json:"batch_size,omitempty"`        // Games run between two stopping checks (default 100)
+}
+
+// Precision reports how precisely a matchup's win rate was estimated. Draws say nothing
+// about which team is stronger, so the win rate and interval cover the decisive games.
+type Precision struct {
+	Games      int     `json:"games"`
+	Draws      int     `json:"draws,omitempty"`
+	WinRate    float64 `json:"team1_win_rate"` // Share of the decisive games won by Team1
+	CILow      float64 `json:"ci_low"`
+	CIHigh     float64 `json:"ci_high"`
+	HalfWidth  float64 `json:"ci_half_width"`
+	Confidence float64 `json:"confidence"`
+	Decision   string  `json:"decision,omitempty"`    // SPRT: "team1", "team2" or "undecided"
+	StopReason string  `json:"stop_reason,omitempty"` // "fixed", "ci_target", "sprt", "max_games" or "budget"
+}
+
+// Adaptive reports whether games are allocated sequentially instead of a fixed count per matchup
+func (s SamplingSpec) Adaptive() bool {
+	return s.Mode == SamplingWilson || s.Mode == SamplingSPRT
+}
+
+// withDefaults fills unset fields; numGames is the average per-matchup budget
+func (s SamplingSpec) withDefaults(numGames int) SamplingSpec {
+	if s.Mode == "" {
+		s.Mode = SamplingFixed
+	}
+	if s.TargetHalfWidth <= 0 {
+		s.TargetHalfWidth = 0.02
+	}
+	if s.Confidence <= 0 || s.Confidence >= 1 {
+		s.Confidence = 0.95
+	}
+	if s.SPRTDelta <= 0 || s.SPRTDelta >= 0.5 {
+		s.SPRTDelta = 0.05
+	}
+	if s.Alpha <= 0 || s.Alpha >= 1 {
+		s.Alpha = 0.05
+	}
+	if s.Beta <= 0 || s.Beta >= 1 {
+		s.Beta = 0.05
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = 100
+	}
+	if s.MinGames <= 0 {
+		s.MinGames = 100
+	}
+	if s.MaxGames <= 0 {
+		s.MaxGames = 4 * numGames
+	}
+	if s.MinGames > s.MaxGames {
+		s.MinGames = s.MaxGames
+	}
+	return s
+}
+
+// Validate checks the sampling mode
+func (s SamplingSpec) Validate() error {
+	switch s.Mode {
+	case "", SamplingFixed, SamplingWilson, SamplingSPRT:
+		return nil
+	default:
+		return fmt.Errorf("unsupported sampling mode: %s (use fixed, wilson or sprt)", s.Mode)
+	}
+}
+
+// stopReason returns why a matchup may stop sampling, or "" if it should continue.
+// wins and decisive count Team1's wins and the games with a winner; requested counts
+// every game handed to the runner, failed ones included, and is capped by MaxGames.
+func (s SamplingSpec) stopReason(wins, decisive, requested int) string {
+	if requested >= s.MaxGames {
+		return "max_games"
+	}
+	if decisive < s.MinGames {
+		return ""
+	}
+	games := decisive
+	switch s.Mode {
+	case SamplingWilson:
+		low, high := WilsonInterval(wins, games, s.Confidence)
+		if (high-low)/2 <= s.TargetHalfWidth {
+			return "ci_target"
+		}
+	case SamplingSPRT:
+		if s.sprtDecision(wins, games) != "undecided" {
+			return "sprt"
+		}
+	}
+	return ""
+}
+
+// sprtDecision runs Wald's SPRT on Team1's win rate with H0: p=0.5-delta and H1: p=0.5+delta
+func (s SamplingSpec) sprtDecision(wins, games int) string {
+	p0 := 0.5 - s.SPRTDelta
+	p1 := 0.5 + s.SPRTDelta
+	losses := games - wins
+	llr := float64(wins)*math.Log(p1/p0) + float64(losses)*math.Log((1-p1)/(1-p0))
+	upper := math.Log((1 - s.Beta) / s.Alpha)
+	lower := math.Log(s.Beta / (1 - s.Alpha))
+	switch {
+	case llr >= upper:
+		return "team1"
+	case llr <= lower:
+		return "team2"
+	default:
+		return "undecided"
+	}
+}
+
+// precision computes the reported precision for a series
+func (s SamplingSpec) precision(series SeriesResult, stopReason string) Precision {
+	sum := series.Summarize()
+	decisive := sum.Team1Wins + sum.Team2Wins
+	low, high := WilsonInterval(sum.Team1Wins, decisive, s.Confidence)
+	p := Precision{
+		Games:      sum.Games,
+		Draws:      sum.Draws,
+		CILow:      low,
+		CIHigh:     high,
+		HalfWidth:  (high - low) / 2,
+		Confidence: s.Confidence,
+		StopReason: stopReason,
+	}
+	if decisive > 0 {
+		p.WinRate = float64(sum.Team1Wins) / float64(decisive)
+	}
+	if s.Mode == SamplingSPRT {
+		p.Decision = s.sprtDecision(sum.Team1Wins, decisive)
+	}
+	return p
+}
+
+// WilsonInterval returns the Wilson score interval for wins successes out of n trials
+func WilsonInterval(wins, n int, confidence float64) (float64, float64) {
+	if n <= 0 {
+		return 0, 1
+	}
+	z := NormalQuantile(1 - (1-confidence)/2)
+	p := float64(wins) / float64(n)
+	nf := float64(n)
+	denom := 1 + z*z/nf
+	center := (p + z*z/(2*nf)) / denom
+	margin := z * math.Sqrt(p*(1-p)/nf+z*z/(4*nf*nf)) / denom
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// NormalQuantile returns the inverse CDF of the standard normal distribution (Acklam's approximation)
+func NormalQuantile(p float64) float64 {
+	if p <= 0 {
+		return math.Inf(-1)
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	a := [6]float64{-3.969683028665376e+01, 2.209460984245205e+02, -2.759285104469687e+02, 1.383577518672690e+02, -3.066479806614716e+01, 2.506628277459239e+00}
+	b := [5]float64{-5.447609879822406e+01, 1.615858368580409e+02, -1.556989798598866e+02, 6.680131188771972e+01, -1.328068155288572e+01}
+	c := [6]float64{-7.784894002430293e-03, -3.223964580411365e-01, -2.400758277161838e+00, -2.549732539343734e+00, 4.374664141464968e+00, 2.938163982698783e+00}
+	d := [4]float64{7.784695709041462e-03, 3.224671290700398e-01, 2.445134137142996e+00, 3.754408661907416e+00}
+	const pLow = 0.02425
+	switch {
+	case p < pLow:
+		q := math.Sqrt(-2 * math.Log(p))
+		return (((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) / ((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	case p <= 1-pLow:
+		q := p - 0.5
+		r := q * q
+		return (((((a[0]*r+a[1])*r+a[2])*r+a[3])*r+a[4])*r + a[5]) * q / (((((b[0]*r+b[1])*r+b[2])*r+b[3])*r+b[4])*r + 1)
+	default:
+		q := math.Sqrt(-2 * math.Log(1-p))
+		return -(((((c[0]*q+c[1])*q+c[2])*q+c[3])*q+c[4])*q + c[5]) / ((((d[0]*q+d[1])*q+d[2])*q+d[3])*q + 1)
+	}
+}
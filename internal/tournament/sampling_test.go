@@ -0,0 +1,138 @@
+package tournament
+
+import (
+	"dbg_abm/internal/engine"
+	"math"
+	"testing"
+)
+
+func TestNormalQuantile(t *testing.T) {
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0.5, 0},
+		{0.9, 1.2815515655446008},
+		{0.975, 1.9599639845400536},
+		{0.025, -1.9599639845400538},
+		{0.995, 2.5758293035489},
+		{0.01, -2.3263478740408408}, // Lower tail region of the approximation
+		{0.001, -3.090232306167813},
+		{0, math.Inf(-1)},
+		{1, math.Inf(1)},
+	}
+	for _, tt := range tests {
+		got := NormalQuantile(tt.p)
+		if math.IsInf(tt.want, 0) {
+			if got != tt.want {
+				t.Errorf("NormalQuantile(%g) = %g, want %g", tt.p, got, tt.want)
+			}
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-8 {
+			t.Errorf("NormalQuantile(%g) = %.12f, want %.12f", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestWilsonInterval(t *testing.T) {
+	// Reference values from the closed form with the exact normal quantile
+	tests := []struct {
+		wins, n    int
+		confidence float64
+		low, high  float64
+	}{
+		{0, 10, 0.95, 0, 0.27753279986288915},
+		{10, 10, 0.95, 0.7224672001371109, 1},
+		{1, 10, 0.95, 0.017876213095072896, 0.40415002679523837},
+		{5, 10, 0.95, 0.23659309051256405, 0.763406909487436},
+		{3, 7, 0.95, 0.1582198552514697, 0.7495416354723428},
+		{50, 100, 0.95, 0.4038315303659957, 0.5961684696340044},
+		{1, 1000, 0.95, 0.00017654637062607809, 0.005642558597957934},
+		{0, 1, 0.95, 0, 0.7934506856227626},
+		{5, 10, 0.99, 0.1842255182472355, 0.8157744817527646},
+		{0, 0, 0.95, 0, 1}, // No games: the whole range
+	}
+	for _, tt := range tests {
+		low, high := WilsonInterval(tt.wins, tt.n, tt.confidence)
+		if math.Abs(low-tt.low) > 1e-8 || math.Abs(high-tt.high) > 1e-8 {
+			t.Errorf("WilsonInterval(%d, %d, %g) = [%.10f, %.10f], want [%.10f, %.10f]",
+				tt.wins, tt.n, tt.confidence, low, high, tt.low, tt.high)
+		}
+	}
+}
+
+func TestSPRTDecision(t *testing.T) {
+	// Defaults: delta 0.05, alpha = beta = 0.05. Each net win moves the log likelihood
+	// ratio by ln(0.55/0.45), so a decision needs a margin of 15 games.
+	s := SamplingSpec{Mode: SamplingSPRT}.withDefaults(1000)
+	tests := []struct {
+		wins, games int
+		want        string
+	}{
+		{50, 100, "undecided"},
+		{57, 100, "undecided"}, // Margin 14
+		{58, 100, "team1"},     // Margin 16
+		{42, 100, "team2"},
+		{43, 100, "undecided"},
+		{15, 15, "team1"},
+		{0, 15, "team2"},
+	}
+	for _, tt := range tests {
+		if got := s.sprtDecision(tt.wins, tt.games); got != tt.want {
+			t.Errorf("sprtDecision(%d, %d) = %q, want %q", tt.wins, tt.games, got, tt.want)
+		}
+	}
+}
+
+func TestStopReason(t *testing.T) {
+	wilson := SamplingSpec{Mode: SamplingWilson, TargetHalfWidth: 0.05}.withDefaults(1000)
+	sprt := SamplingSpec{Mode: SamplingSPRT}.withDefaults(1000)
+	fixed := SamplingSpec{Mode: SamplingFixed}.withDefaults(1000)
+	tests := []struct {
+		name                      string
+		spec                      SamplingSpec
+		wins, decisive, requested int
+		want                      string
+	}{
+		{"wilson below min games", wilson, 50, 50, 50, ""},
+		{"wilson interval too wide", wilson, 190, 380, 380, ""}, // Half width 0.05002
+		{"wilson target reached", wilson, 195, 390, 390, "ci_target"},
+		{"wilson lopsided", wilson, 95, 100, 100, "ci_target"},
+		{"wilson max games", wilson, 2000, 4000, 4000, "max_games"},
+		{"sprt below min games", sprt, 20, 20, 20, ""},
+		{"sprt undecided", sprt, 57, 100, 100, ""},
+		{"sprt decided", sprt, 58, 100, 100, "sprt"},
+		{"sprt decided for team2", sprt, 30, 100, 100, "sprt"},
+		{"sprt max games", sprt, 2000, 4000, 4000, "max_games"},
+		{"fixed never stops early", fixed, 100, 100, 100, ""},
+		{"failed games count towards max games", sprt, 1900, 3990, 4000, "max_games"},
+		{"draws do not count towards min games", sprt, 58, 99, 300, ""},
+		{"draws leave the decision to decisive games", sprt, 58, 100, 400, "sprt"},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.stopReason(tt.wins, tt.decisive, tt.requested); got != tt.want {
+			t.Errorf("%s: stopReason(%d, %d, %d) = %q, want %q", tt.name, tt.wins, tt.decisive, tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestPrecisionLeavesOutDraws(t *testing.T) {
+	series := SeriesResult{}
+	for i := 0; i < 30; i++ {
+		outcome := engine.OutcomeDraw
+		switch {
+		case i < 15:
+			outcome = engine.OutcomeTeam1Win
+		case i < 20:
+			outcome = engine.OutcomeTeam2Win
+		}
+		series.GameResults = append(series.GameResults, NewGameOutcome(outcome, [2]int{12, 12}, 24, 0, int64(i)))
+	}
+	s := SamplingSpec{Mode: SamplingWilson}.withDefaults(100)
+	p := s.precision(series, "budget")
+	low, high := WilsonInterval(15, 20, s.Confidence)
+	if p.Games != 30 || p.Draws != 10 || p.WinRate != 0.75 || p.CILow != low || p.CIHigh != high {
+		t.Errorf("precision %+v, want 30 games, 10 draws, win rate 0.75 in [%g, %g]", p, low, high)
+	}
+}
@@ -3,13 +3,17 @@ package tournament
 import (
 	"context"
 	"dbg_abm/internal/engine"
+	"fmt"
+	"math/rand"
+	"sort"
 	"time"
 )
 
 type Format string
 
 const (
-	FormatRoundRobin Format = "roundrobin"
+	FormatRoundRobin       Format = "roundrobin"
+	FormatDoubleRoundRobin Format = "doubleroundrobin"
 )
 
 type SeriesSpec struct {
@@ -17,42 +21,134 @@ type SeriesSpec struct {
 	Seed           int64
 	MaxConcurrent  int
 	TimeoutPerGame time.Duration
+	Offset         int  // Index of the first game when a matchup is played in several batches
+	RandomSeeds    bool // Seed every game randomly instead of with Seed+Offset+i (the seeds are recorded)
 }
 
 type MatchSpec struct {
-	Team1Name     string
-	Team1Strategy string
-	Team2Name     string
-	Team2Strategy string
+	Team1Name     string `json:"team1_name"`
+	Team1Strategy string `json:"team1_strategy"`
+	Team2Name     string `json:"team2_name"`
+	Team2Strategy string `json:"team2_strategy"`
 }
 
-type TournamentConfig struct {
-	Format       Format
-	Series       SeriesSpec
-	Participants []string // strategy names
+// GameOutcome is the compact result of a single game within a matchup
+type GameOutcome struct {
+	T1Wins  bool   `json:"t1_wins"`
+	Draw    bool   `json:"draw,omitempty"` // Tied game, T1Wins is false
+	Score   [2]int `json:"score"`          // [Team1, Team2]
+	Rounds  int    `json:"rounds"`         // Total rounds played
+	OTCount int    `json:"ot_count"`       // Number of overtime periods played
+	Seed    int64  `json:"seed"`           // RNG seed, replays the game exactly
 }
 
-type GameOutcome struct {
-	T1Wins bool
-	Score  [2]int
+// Result returns the outcome of the game as a win of either team or a draw
+func (g GameOutcome) Result() engine.Outcome {
+	switch {
+	case g.Draw:
+		return engine.OutcomeDraw
+	case g.T1Wins:
+		return engine.OutcomeTeam1Win
+	}
+	return engine.OutcomeTeam2Win
+}
+
+// NewGameOutcome builds the compact outcome of a game from its result
+func NewGameOutcome(outcome engine.Outcome, score [2]int, rounds, otCount int, seed int64) GameOutcome {
+	return GameOutcome{
+		T1Wins:  outcome.Team1Won(),
+		Draw:    outcome.IsDraw(),
+		Score:   score,
+		Rounds:  rounds,
+		OTCount: otCount,
+		Seed:    seed,
+	}
 }
 
 type SeriesResult struct {
-	Match       MatchSpec
-	SeriesWins  [2]int
-	GameResults []GameOutcome
+	Match       MatchSpec     `json:"match"`
+	SeriesWins  [2]int        `json:"series_wins"`
+	Precision   *Precision    `json:"precision,omitempty"` // Achieved precision of the Team1 win rate
+	GameResults []GameOutcome `json:"-"`                   // Exported separately, can be millions of rows
+}
+
+// SeriesSummary aggregates the game outcomes of a series
+type SeriesSummary struct {
+	Games          int            `json:"games"`
+	Team1Wins      int            `json:"team1_wins"`
+	Team2Wins      int            `json:"team2_wins"`
+	Draws          int            `json:"draws,omitempty"`
+	Team1WinRate   float64        `json:"team1_win_rate"`
+	Team1Rounds    int            `json:"team1_rounds_won"`
+	Team2Rounds    int            `json:"team2_rounds_won"`
+	RoundDiff      int            `json:"round_diff"`           // Team1 rounds won minus Team2 rounds won
+	AvgRoundDiff   float64        `json:"avg_round_diff"`       // Per game, from Team1's perspective
+	AvgRounds      float64        `json:"avg_rounds"`           // Average rounds per game
+	OvertimeGames  int            `json:"overtime_games"`       // Games that went to at least one overtime
+	OvertimeRate   float64        `json:"overtime_rate"`        // Share of games that went to overtime
+	AvgOTPeriods   float64        `json:"avg_overtime_periods"` // Average number of overtime periods per game
+	ScoreLineCount map[string]int `json:"score_lines"`          // "16-10" (Team1-Team2) -> count
 }
 
+// Standings points per result (3-1-0)
+const (
+	PointsWin  = 3
+	PointsDraw = 1
+)
+
 type StandingsRow struct {
-	Strategy string
-	Wins     int
-	Losses   int
-	MapWins  int
-	MapLoss  int
+	Strategy   string `json:"strategy"`
+	Points     int    `json:"points"` // PointsWin per win, PointsDraw per draw
+	Wins       int    `json:"wins"`
+	Losses     int    `json:"losses"`
+	Draws      int    `json:"draws,omitempty"`
+	MapWins    int    `json:"map_wins"`
+	MapLoss    int    `json:"map_losses"`
+	RoundsWon  int    `json:"rounds_won"`
+	RoundsLost int    `json:"rounds_lost"`
+	RoundDiff  int    `json:"round_diff"`
 }
 
 type Standings struct {
-	Rows []StandingsRow
+	Rows []StandingsRow `json:"rows"`
+}
+
+// Summarize computes score-line, round differential and overtime aggregates for a series
+func (s SeriesResult) Summarize() SeriesSummary {
+	sum := SeriesSummary{
+		Games:          len(s.GameResults),
+		ScoreLineCount: make(map[string]int),
+	}
+	otPeriods := 0
+	totalRounds := 0
+	for _, g := range s.GameResults {
+		switch g.Result() {
+		case engine.OutcomeDraw:
+			sum.Draws++
+		case engine.OutcomeTeam1Win:
+			sum.Team1Wins++
+		default:
+			sum.Team2Wins++
+		}
+		sum.Team1Rounds += g.Score[0]
+		sum.Team2Rounds += g.Score[1]
+		totalRounds += g.Rounds
+		if g.OTCount > 0 {
+			sum.OvertimeGames++
+			otPeriods += g.OTCount
+		}
+		sum.ScoreLineCount[fmt.Sprintf("%d-%d", g.Score[0], g.Score[1])]++
+	}
+	sum.RoundDiff = sum.Team1Rounds - sum.Team2Rounds
+	if sum.Games > 0 {
+		n := float64(sum.Games)
+		sum.Team1WinRate = float64(sum.Team1Wins) / n
+		sum.AvgRoundDiff = float64(sum.RoundDiff) / n
+		sum.AvgRounds = float64(totalRounds) / n
+		sum.OvertimeRate = float64(sum.OvertimeGames) / n
+		sum.AvgOTPeriods = float64(otPeriods) / n
+	}
+	return sum
 }
 
 // RoundRobinSchedule pairs each strategy with every other,
@@ -62,25 +158,52 @@ func RoundRobinSchedule(strategies []string) []MatchSpec {
 	for i := 0; i < len(strategies); i++ {
 		for j := i + 1; j < len(strategies); j++ {
 			// A vs B
-			matches = append(matches, MatchSpec{
-				Team1Name:     strategies[i],
-				Team1Strategy: strategies[i],
-				Team2Name:     strategies[j],
-				Team2Strategy: strategies[j],
-			})
+			matches = append(matches, newMatchSpec(strategies[i], strategies[j]))
 			// B vs A
-			matches = append(matches, MatchSpec{
-				Team1Name:     strategies[j],
-				Team1Strategy: strategies[j],
-				Team2Name:     strategies[i],
-				Team2Strategy: strategies[i],
-			})
+			matches = append(matches, newMatchSpec(strategies[j], strategies[i]))
+		}
+	}
+	return matches
+}
+
+// SingleRoundRobinSchedule pairs each strategy with every other once (A vs B only).
+// One ordering is enough since the starting side is randomized in every game.
+func SingleRoundRobinSchedule(strategies []string) []MatchSpec {
+	var matches []MatchSpec
+	for i := 0; i < len(strategies); i++ {
+		for j := i + 1; j < len(strategies); j++ {
+			matches = append(matches, newMatchSpec(strategies[i], strategies[j]))
 		}
 	}
 	return matches
 }
 
-// RunMatchup executes many independent ABM games for a matchup to estimate performance
+// Schedule builds the list of matchups for a tournament format: roundrobin plays every
+// pair once, doubleroundrobin in both orderings
+func Schedule(format Format, strategies []string) ([]MatchSpec, error) {
+	switch format {
+	case FormatRoundRobin, "":
+		return SingleRoundRobinSchedule(strategies), nil
+	case FormatDoubleRoundRobin:
+		return RoundRobinSchedule(strategies), nil
+	default:
+		return nil, fmt.Errorf("unsupported tournament format: %s", format)
+	}
+}
+
+func newMatchSpec(team1, team2 string) MatchSpec {
+	return MatchSpec{
+		Team1Name:     team1,
+		Team1Strategy: team1,
+		Team2Name:     team2,
+		Team2Strategy: team2,
+	}
+}
+
+// RunMatchup executes many independent ABM games for a matchup to estimate performance.
+// Game i is seeded with spec.Seed+spec.Offset+i, also for spec.Seed == 0; with spec.RandomSeeds
+// every game gets a random seed.
+// Games exceeding spec.TimeoutPerGame are dropped from the result.
 func RunMatchup(ctx context.Context, m MatchSpec, rules engine.GameRules, spec SeriesSpec) (SeriesResult, error) {
 	res := SeriesResult{Match: m}
 	if spec.NumGames <= 0 {
@@ -89,58 +212,103 @@ func RunMatchup(ctx context.Context, m MatchSpec, rules engine.GameRules, spec S
 	if spec.MaxConcurrent <= 0 {
 		spec.MaxConcurrent = 1
 	}
-	type item struct{ idx int }
-	jobs := make(chan item)
-	results := make(chan GameOutcome)
+
+	// Child context so workers stop when we return early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		game GameOutcome
+		ok   bool
+	}
+	jobs := make(chan int)
+	results := make(chan outcome, spec.MaxConcurrent)
 	// Workers
 	for w := 0; w < spec.MaxConcurrent; w++ {
 		go func() {
-			for it := range jobs {
-				// Allow cancel
-				select {
-				case <-ctx.Done():
-					return
-				default:
+			for idx := range jobs {
+				seed := spec.Seed + int64(spec.Offset+idx)
+				if spec.RandomSeeds {
+					seed = rand.Int63()
 				}
-				game := engine.NewGame("", m.Team1Name, m.Team1Strategy, m.Team2Name, m.Team2Strategy, rules)
-				game.SetSeed(spec.Seed + int64(it.idx))
+				game := engine.NewSeededGame("", m.Team1Name, m.Team1Strategy, m.Team2Name, m.Team2Strategy, rules, seed)
 				done := make(chan struct{})
 				go func() { game.Start(); close(done) }()
+
+				var timer *time.Timer
+				var timeout <-chan time.Time
 				if spec.TimeoutPerGame > 0 {
-					timer := time.NewTimer(spec.TimeoutPerGame)
-					select {
-					case <-done:
-						timer.Stop()
-					case <-timer.C:
-						// timeout -> treat as no result; skip
-					}
-				} else {
-					<-done
+					timer = time.NewTimer(spec.TimeoutPerGame)
+					timeout = timer.C
+				}
+
+				out := outcome{}
+				select {
+				case <-done:
+					out = outcome{game: outcomeFromGame(game), ok: true}
+				case <-timeout:
+					// timeout -> treat as no result; skip
+				case <-ctx.Done():
+					return
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				select {
+				case results <- out:
+				case <-ctx.Done():
+					return
 				}
-				results <- GameOutcome{T1Wins: game.Is_T1_Winner, Score: game.Score}
 			}
 		}()
 	}
 	// Feed jobs
 	go func() {
+		defer close(jobs)
 		for i := 0; i < spec.NumGames; i++ {
-			jobs <- item{idx: i}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(jobs)
 	}()
 	// Collect
+	res.GameResults = make([]GameOutcome, 0, spec.NumGames)
 	for i := 0; i < spec.NumGames; i++ {
 		select {
 		case <-ctx.Done():
 			return res, ctx.Err()
 		case out := <-results:
-			res.GameResults = append(res.GameResults, out)
+			if out.ok {
+				res.GameResults = append(res.GameResults, out.game)
+			}
 		}
 	}
+	res.SeriesWins = seriesWinner(res)
 	return res, nil
 }
 
-// ComputeStandings aggregates series results into a table
+// outcomeFromGame extracts the compact outcome of a finished game
+func outcomeFromGame(game *engine.Game) GameOutcome {
+	return NewGameOutcome(game.Outcome, game.Score, len(game.Rounds), game.OTcounter, game.Seed)
+}
+
+// seriesWinner awards the series to the team that won the majority of its games,
+// a series with as many wins for both teams is drawn ({0, 0})
+func seriesWinner(s SeriesResult) [2]int {
+	sum := s.Summarize()
+	switch {
+	case sum.Team1Wins > sum.Team2Wins:
+		return [2]int{1, 0}
+	case sum.Team2Wins > sum.Team1Wins:
+		return [2]int{0, 1}
+	}
+	return [2]int{0, 0}
+}
+
+// ComputeStandings aggregates series results into a table.
+// Every game counts as a win, loss or draw; rows are ranked by points (3-1-0), then round differential.
 func ComputeStandings(strategies []string, series []SeriesResult) Standings {
 	idx := map[string]int{}
 	rows := make([]StandingsRow, 0, len(strategies))
@@ -152,21 +320,91 @@ func ComputeStandings(strategies []string, series []SeriesResult) Standings {
 		i1 := idx[sr.Match.Team1Strategy]
 		i2 := idx[sr.Match.Team2Strategy]
 		for _, g := range sr.GameResults {
-			if g.T1Wins {
+			switch g.Result() {
+			case engine.OutcomeDraw:
+				rows[i1].Draws++
+				rows[i2].Draws++
+			case engine.OutcomeTeam1Win:
 				rows[i1].Wins++
 				rows[i2].Losses++
 				rows[i1].MapWins++
 				rows[i2].MapLoss++
-			} else {
+			default:
 				rows[i2].Wins++
 				rows[i1].Losses++
 				rows[i2].MapWins++
 				rows[i1].MapLoss++
 			}
+			addRounds(&rows[i1], g.Score[0], g.Score[1])
+			addRounds(&rows[i2], g.Score[1], g.Score[0])
 		}
 	}
+	SortStandings(rows)
 	return Standings{Rows: rows}
 }
 
+// ComputeSeriesStandings aggregates series results into a table where a series
+// counts as one win for the strategy that won the majority of its games (a draw if
+// both won as many).
+// MapWins/MapLoss hold the individual games; rows are ranked by series points
+// (3-1-0), then round differential, then map wins.
+func ComputeSeriesStandings(strategies []string, series []SeriesResult) Standings {
+	idx := map[string]int{}
+	rows := make([]StandingsRow, 0, len(strategies))
+	for _, s := range strategies {
+		idx[s] = len(rows)
+		rows = append(rows, StandingsRow{Strategy: s})
+	}
+	for _, sr := range series {
+		i1 := idx[sr.Match.Team1Strategy]
+		i2 := idx[sr.Match.Team2Strategy]
+		sum := sr.Summarize()
+
+		rows[i1].MapWins += sum.Team1Wins
+		rows[i1].MapLoss += sum.Team2Wins
+		rows[i2].MapWins += sum.Team2Wins
+		rows[i2].MapLoss += sum.Team1Wins
+		addRounds(&rows[i1], sum.Team1Rounds, sum.Team2Rounds)
+		addRounds(&rows[i2], sum.Team2Rounds, sum.Team1Rounds)
+
+		switch {
+		case sum.Team1Wins > sum.Team2Wins:
+			rows[i1].Wins++
+			rows[i2].Losses++
+		case sum.Team2Wins > sum.Team1Wins:
+			rows[i2].Wins++
+			rows[i1].Losses++
+		default:
+			rows[i1].Draws++
+			rows[i2].Draws++
+		}
+	}
+	SortStandings(rows)
+	return Standings{Rows: rows}
+}
+
+func addRounds(row *StandingsRow, won, lost int) {
+	row.RoundsWon += won
+	row.RoundsLost += lost
+	row.RoundDiff = row.RoundsWon - row.RoundsLost
+}
+
+// SortStandings awards the points of every row and ranks rows by points, then round
+// differential, then map wins
+func SortStandings(rows []StandingsRow) {
+	for i := range rows {
+		rows[i].Points = rows[i].Wins*PointsWin + rows[i].Draws*PointsDraw
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		if rows[a].Points != rows[b].Points {
+			return rows[a].Points > rows[b].Points
+		}
+		if rows[a].RoundDiff != rows[b].RoundDiff {
+			return rows[a].RoundDiff > rows[b].RoundDiff
+		}
+		return rows[a].MapWins > rows[b].MapWins
+	})
+}
+
 // GetRows exposes rows for simple CSV exporting without introducing new types in analysis
 func (s Standings) GetRows() []StandingsRow { return s.Rows }
@@ -0,0 +1,146 @@
+package tournament
+
+import (
+	"context"
+	"dbg_abm/internal/engine"
+	"sort"
+	"testing"
+)
+
+// Three games between a and b: a wins 13-5, b wins 13-11, then 12-12 is drawn.
+// c beats b 13-0 and draws 12-12 with a.
+func drawSeries() []SeriesResult {
+	ab := SeriesResult{
+		Match: newMatchSpec("a", "b"),
+		GameResults: []GameOutcome{
+			NewGameOutcome(engine.OutcomeTeam1Win, [2]int{13, 5}, 18, 0, 1),
+			NewGameOutcome(engine.OutcomeTeam2Win, [2]int{11, 13}, 24, 0, 2),
+			NewGameOutcome(engine.OutcomeDraw, [2]int{12, 12}, 24, 0, 3),
+		},
+	}
+	cb := SeriesResult{
+		Match:       newMatchSpec("c", "b"),
+		GameResults: []GameOutcome{NewGameOutcome(engine.OutcomeTeam1Win, [2]int{13, 0}, 13, 0, 4)},
+	}
+	ca := SeriesResult{
+		Match:       newMatchSpec("c", "a"),
+		GameResults: []GameOutcome{NewGameOutcome(engine.OutcomeDraw, [2]int{12, 12}, 24, 0, 5)},
+	}
+	return []SeriesResult{ab, cb, ca}
+}
+
+func TestComputeStandingsDraws(t *testing.T) {
+	st := ComputeStandings([]string{"a", "b", "c"}, drawSeries())
+
+	// c: 1 win, 1 draw = 4 points; a: 1 win, 1 loss, 2 draws = 5 points; b: 1 win, 2 losses, 1 draw = 4 points
+	want := []StandingsRow{
+		{Strategy: "a", Points: 5, Wins: 1, Losses: 1, Draws: 2, MapWins: 1, MapLoss: 1, RoundsWon: 48, RoundsLost: 42, RoundDiff: 6},
+		{Strategy: "c", Points: 4, Wins: 1, Draws: 1, MapWins: 1, RoundsWon: 25, RoundsLost: 12, RoundDiff: 13},
+		{Strategy: "b", Points: 4, Wins: 1, Losses: 2, Draws: 1, MapWins: 1, MapLoss: 2, RoundsWon: 30, RoundsLost: 49, RoundDiff: -19},
+	}
+	if len(st.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(st.Rows), len(want))
+	}
+	for i := range want {
+		if st.Rows[i] != want[i] {
+			t.Errorf("row %d:\n got %+v\nwant %+v", i, st.Rows[i], want[i])
+		}
+	}
+}
+
+func TestComputeSeriesStandingsDraws(t *testing.T) {
+	st := ComputeSeriesStandings([]string{"a", "b", "c"}, drawSeries())
+
+	// a-b is 1-1 in games and therefore a drawn series, c wins against b and draws with a
+	points := map[string][3]int{} // strategy -> wins, draws, points
+	for _, r := range st.Rows {
+		points[r.Strategy] = [3]int{r.Wins, r.Draws, r.Points}
+	}
+	for strategy, want := range map[string][3]int{"c": {1, 1, 4}, "a": {0, 2, 2}, "b": {0, 1, 1}} {
+		if got := points[strategy]; got != want {
+			t.Errorf("%s: wins, draws, points %v, want %v", strategy, got, want)
+		}
+	}
+	if st.Rows[0].Strategy != "c" || st.Rows[2].Strategy != "b" {
+		t.Errorf("order %s, %s, %s; want c first and b last", st.Rows[0].Strategy, st.Rows[1].Strategy, st.Rows[2].Strategy)
+	}
+}
+
+func TestSortStandingsTieBreaks(t *testing.T) {
+	rows := []StandingsRow{
+		{Strategy: "draws", Draws: 3, RoundDiff: 0},          // 3 points
+		{Strategy: "win", Wins: 1, Losses: 2, RoundDiff: -4}, // 3 points, worse round differential
+		{Strategy: "maps", Wins: 1, RoundDiff: 0, MapWins: 2},
+		{Strategy: "top", Wins: 2},
+	}
+	SortStandings(rows)
+
+	order := ""
+	for _, r := range rows {
+		order += r.Strategy + " "
+	}
+	if order != "top maps draws win " {
+		t.Errorf("order %q, want points, then round differential, then map wins", order)
+	}
+	if rows[0].Points != 2*PointsWin || rows[2].Points != 3*PointsDraw {
+		t.Errorf("points %d and %d", rows[0].Points, rows[2].Points)
+	}
+}
+
+func TestSeriesSummaryCountsDraws(t *testing.T) {
+	sum := drawSeries()[0].Summarize()
+	if sum.Games != 3 || sum.Team1Wins != 1 || sum.Team2Wins != 1 || sum.Draws != 1 {
+		t.Errorf("games %d, team 1 wins %d, team 2 wins %d, draws %d", sum.Games, sum.Team1Wins, sum.Team2Wins, sum.Draws)
+	}
+	if w := seriesWinner(drawSeries()[0]); w != [2]int{0, 0} {
+		t.Errorf("seriesWinner of a 1-1 series = %v, want a draw", w)
+	}
+	for _, o := range []engine.Outcome{engine.OutcomeTeam1Win, engine.OutcomeTeam2Win, engine.OutcomeDraw} {
+		if got := NewGameOutcome(o, [2]int{}, 0, 0, 0).Result(); got != o {
+			t.Errorf("Result() of a %v game = %v", o, got)
+		}
+	}
+}
+
+func TestRunMatchupSeeds(t *testing.T) {
+	if err := engine.LoadDistributions("../../distributions.json"); err != nil {
+		t.Skipf("distributions not available: %v", err)
+	}
+	rules, err := engine.ProfileRules("cs2_mr12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMatchSpec("all_in", "min_max_v4")
+	seeds := func(spec SeriesSpec) []int64 {
+		res, err := RunMatchup(context.Background(), m, rules, spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var seeds []int64
+		for _, g := range res.GameResults {
+			seeds = append(seeds, g.Seed)
+		}
+		sort.Slice(seeds, func(i, j int) bool { return seeds[i] < seeds[j] })
+		return seeds
+	}
+
+	// Seed 0 is a seed like any other: game i gets Offset+i
+	for _, spec := range []SeriesSpec{{NumGames: 4, MaxConcurrent: 2}, {NumGames: 4, Seed: 100, Offset: 10, MaxConcurrent: 2}} {
+		got := seeds(spec)
+		for i, seed := range got {
+			if want := spec.Seed + int64(spec.Offset+i); seed != want {
+				t.Errorf("seed %d, offset %d: game %d has seed %d, want %d", spec.Seed, spec.Offset, i, seed, want)
+			}
+		}
+	}
+
+	random := seeds(SeriesSpec{NumGames: 4, Seed: 100, MaxConcurrent: 2, RandomSeeds: true})
+	if len(random) != 4 || random[0] == 100 && random[3] == 103 {
+		t.Errorf("random seeds %v", random)
+	}
+	for i := 1; i < len(random); i++ {
+		if random[i] == random[i-1] {
+			t.Errorf("seed %d used twice", random[i])
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package util
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Export files are compressed transparently based on their extension: *.gz is written
+// with gzip, *.zst with zstd, everything else as plain text. Appending to a compressed
+// file adds a new gzip member / zstd frame, which the gzip and zstd tools read as one
+// continuous stream.
+
+// Compression names accepted by SetCompression (--compress)
+const (
+	CompressNone = "none"
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
+var compressionExts = map[string]string{
+	CompressGzip: ".gz",
+	CompressZstd: ".zst",
+}
+
+// compressionExt is added to generated export file names (see CompressedName)
+var compressionExt string
+
+// SetCompression selects the compression of generated export files: none, gzip or zstd
+func SetCompression(name string) error {
+	if name == "" || name == CompressNone {
+		compressionExt = ""
+		return nil
+	}
+	ext, ok := compressionExts[name]
+	if !ok {
+		return fmt.Errorf("unknown compression %q (use none, gzip or zstd)", name)
+	}
+	compressionExt = ext
+	return nil
+}
+
+// CompressedName adds the extension of the selected compression to a generated file name
+// (all_games_minimal.csv -> all_games_minimal.csv.gz)
+func CompressedName(name string) string {
+	if compressionExt == "" || CompressionExt(name) != "" {
+		return name
+	}
+	return name + compressionExt
+}
+
+// CompressionExt returns the compression extension of path (".gz", ".zst" or "")
+func CompressionExt(path string) string {
+	for _, ext := range compressionExts {
+		if strings.HasSuffix(path, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// InsertSuffix inserts suffix before the file extension, ignoring a compression extension
+// (all_games.csv.gz + "_partial" -> all_games_partial.csv.gz)
+func InsertSuffix(path, suffix string) string {
+	cext := CompressionExt(path)
+	base := strings.TrimSuffix(path, cext)
+	ext := ""
+	if i := strings.LastIndexByte(base, '.'); i > strings.LastIndexAny(base, `/\`) {
+		ext = base[i:]
+	}
+	return strings.TrimSuffix(base, ext) + suffix + ext + cext
+}
+
+// CreateFile creates (truncates) path for writing, compressed according to its extension
+func CreateFile(path string) (io.WriteCloser, error) {
+	return openFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC)
+}
+
+// AppendFile opens path for appending (creating it if needed), compressed according to its extension
+func AppendFile(path string) (io.WriteCloser, error) {
+	return openFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND)
+}
+
+// OpenFile opens path for reading and decompresses it according to its extension
+func OpenFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	switch CompressionExt(path) {
+	case ".gz":
+		zr, err := gzip.NewReader(bufio.NewReader(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &compressedReader{r: zr, closeR: zr.Close, file: file}, nil
+	case ".zst":
+		zr, err := zstd.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &compressedReader{r: zr, closeR: func() error { zr.Close(); return nil }, file: file}, nil
+	}
+	return file, nil
+}
+
+// WriteFile writes data to path like os.WriteFile, compressed according to its extension
+func WriteFile(path string, data []byte) error {
+	w, err := CreateFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func openFile(path string, flags int) (io.WriteCloser, error) {
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, err
+	}
+	switch CompressionExt(path) {
+	case ".gz":
+		return &compressedWriter{w: gzip.NewWriter(file), file: file}, nil
+	case ".zst":
+		zw, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &compressedWriter{w: zw, file: file}, nil
+	}
+	return file, nil
+}
+
+// compressedWriter closes the compressor (writing its trailer) before the file
+type compressedWriter struct {
+	w    io.WriteCloser
+	file *os.File
+}
+
+func (c *compressedWriter) Write(p []byte) (int, error) { return c.w.Write(p) }
+
+func (c *compressedWriter) Close() error {
+	err := c.w.Close()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+type compressedReader struct {
+	r      io.Reader
+	closeR func() error
+	file   *os.File
+}
+
+func (c *compressedReader) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+func (c *compressedReader) Close() error {
+	err := c.closeR()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"bufio"
+	"dbg_abm/internal/engine"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// EventWriter writes game events as JSON Lines while games run, so the event log does not
+// need the finished engine.Game. It can be shared by concurrently running games; lines of
+// different games interleave and are told apart by game_id.
+type EventWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	closer io.Closer // nil for stdout
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	err    error // First write error
+}
+
+// NewEventWriter writes events to path (compressed for *.gz / *.zst), or to stdout if path is "-"
+func NewEventWriter(path string) (*EventWriter, error) {
+	w := &EventWriter{out: os.Stdout}
+	if path != "-" {
+		file, err := CreateFile(path)
+		if err != nil {
+			return nil, err
+		}
+		w.out = file
+		w.closer = file
+	}
+	w.buf = bufio.NewWriter(w.out)
+	w.enc = json.NewEncoder(w.buf)
+	return w, nil
+}
+
+// Handle writes one event; use it as the engine.EventHandler of games.
+// The buffer is flushed at the end of every game.
+func (w *EventWriter) Handle(ev engine.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+	w.err = w.enc.Encode(ev)
+	if w.err == nil && ev.Type == engine.EventGameEnd {
+		w.err = w.buf.Flush()
+	}
+}
+
+// Close flushes the remaining events and closes the file. It returns the first write error.
+func (w *EventWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.buf.Flush(); w.err == nil {
+		w.err = err
+	}
+	if w.closer != nil {
+		if err := w.closer.Close(); w.err == nil {
+			w.err = err
+		}
+	}
+	return w.err
+}
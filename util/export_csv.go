@@ -4,6 +4,7 @@ import (
 	"dbg_abm/internal/engine"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -23,12 +24,31 @@ func joinFloatSlice(vals []float64) string {
 	return b.String()
 }
 
+// openCombinedCSV creates a combined CSV file, or opens it for appending.
+// The returned flag tells whether the header still has to be written.
+// Files ending in .gz or .zst are compressed (see CreateFile).
+func openCombinedCSV(path string, appendRows bool) (io.WriteCloser, bool, error) {
+	if !appendRows {
+		file, err := CreateFile(path)
+		return file, true, err
+	}
+	writeHeader := true
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		writeHeader = false
+	}
+	file, err := AppendFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return file, writeHeader, nil
+}
+
 // 1. Export each game individually with all round data (all info per row)
 func ExportGameAllDataCSV(game *engine.Game, path string) error {
 	if path == "" {
 		path = fmt.Sprintf("%s.csv", game.ID)
 	}
-	file, err := os.Create(path)
+	file, err := CreateFile(path)
 	if err != nil {
 		return err
 	}
@@ -98,6 +118,8 @@ func ExportGameAllDataCSV(game *engine.Game, path string) error {
 		"t2_name",
 		"t2_strategy",
 		"game_id",
+		"t1_buy_type",
+		"t2_buy_type",
 	}
 	writer.Write(headers)
 
@@ -165,6 +187,8 @@ func ExportGameAllDataCSV(game *engine.Game, path string) error {
 			game.Team2.Name,
 			game.Team2.Strategy,
 			game.ID,
+			t1.BuyType,
+			t2.BuyType,
 		}
 		writer.Write(row)
 	}
@@ -173,151 +197,168 @@ func ExportGameAllDataCSV(game *engine.Game, path string) error {
 
 // 2. Export all games in one file with all round data (all info per row)
 func ExportAllGamesAllDataCSV(games []*engine.Game, path string) error {
+	return exportAllGamesAllDataCSV(games, path, false)
+}
+
+// AppendAllGamesAllDataCSV appends the rows of games to an existing combined file (header only if the file is new)
+func AppendAllGamesAllDataCSV(games []*engine.Game, path string) error {
+	return exportAllGamesAllDataCSV(games, path, true)
+}
+
+func exportAllGamesAllDataCSV(games []*engine.Game, path string, appendRows bool) error {
 	if path == "" {
 		path = "all_games_full.csv"
 	}
-	file, err := os.Create(path)
+	sink, err := NewAllDataCSVSink(path, appendRows)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	writer := csv.NewWriter(file)
-	writer.Comma = ';'
-	defer writer.Flush()
-
-	// Write header row
-	headers := []string{
-		"round_number",
-		"is_t1_ct",
-		"is_t1_winner",
-		"is_ot",
-		"is_ct_winner",
-		"outcome_reason_code",
-		"outcome_ct_wins",
-		"outcome_bomb_planted",
-		"outcome_ct_survivors",
-		"outcome_t_survivors",
-		"csf",
-		"csf_key",
-		"ct_equipment_share_per_player",
-		"t_equipment_share_per_player",
-		"ct_equipment_per_player",
-		"t_equipment_per_player",
-		"rng_csf",
-		"rng_round_outcome",
-		"rng_bombplant",
-		"rng_survivors_ct",
-		"rng_survivors_t",
-		"rng_equipment_ct",
-		"rng_equipment_t",
-		"t1_funds",
-		"t1_funds_start",
-		"t1_earned",
-		"t1_rs_eq_value",
-		"t1_fte_eq_value",
-		"t1_re_eq_value",
-		"t1_survivors",
-		"t1_score_start",
-		"t1_score_end",
-		"t1_consecutive_loss",
-		"t1_consecutive_losses_start",
-		"t1_consecutive_wins",
-		"t1_consecutive_wins_start",
-		"t1_loss_bonus_level",
-		"t1_spent",
-		"t2_funds",
-		"t2_funds_start",
-		"t2_earned",
-		"t2_rs_eq_value",
-		"t2_fte_eq_value",
-		"t2_re_eq_value",
-		"t2_survivors",
-		"t2_score_start",
-		"t2_score_end",
-		"t2_consecutive_loss",
-		"t2_consecutive_losses_start",
-		"t2_consecutive_wins",
-		"t2_consecutive_wins_start",
-		"t2_loss_bonus_level",
-		"t2_spent",
-		"t1_name",
-		"t1_strategy",
-		"t2_name",
-		"t2_strategy",
-		"game_id",
+	for _, game := range games {
+		if err := sink.WriteGame(game); err != nil {
+			sink.Close()
+			return err
+		}
 	}
-	writer.Write(headers)
+	return sink.Close()
+}
 
-	for _, game := range games {
-		for i := range game.Rounds {
-			round := &game.Rounds[i]
-			t1 := game.Team1.RoundData[i]
-			t2 := game.Team2.RoundData[i]
-			isCTWinner := round.IsT1CT == round.IsT1WinnerTeam
-			row := []string{
-				fmt.Sprintf("%d", round.RoundNumber),
-				fmt.Sprintf("%t", round.IsT1CT),
-				fmt.Sprintf("%t", round.IsT1WinnerTeam),
-				fmt.Sprintf("%t", round.OT),
-				fmt.Sprintf("%t", isCTWinner),
-				fmt.Sprintf("%d", round.Calc_Outcome.ReasonCode),
-				fmt.Sprintf("%t", round.Calc_Outcome.CTWins),
-				fmt.Sprintf("%t", round.Calc_Outcome.BombPlanted),
-				fmt.Sprintf("%d", round.Calc_Outcome.CTSurvivors),
-				fmt.Sprintf("%d", round.Calc_Outcome.TSurvivors),
-				fmt.Sprintf("%.6f", round.Calc_Outcome.CSF),
-				round.Calc_Outcome.CSFKey,
-				joinFloatSlice(round.Calc_Outcome.CTEquipmentSharePerPlayer),
-				joinFloatSlice(round.Calc_Outcome.TEquipmentSharePerPlayer),
-				joinFloatSlice(round.Calc_Outcome.CTEquipmentPerPlayer),
-				joinFloatSlice(round.Calc_Outcome.TEquipmentPerPlayer),
-				fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_CSF),
-				fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_RoundOutcome),
-				fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_Bombplant),
-				fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_SurvivorsCT),
-				fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_SurvivorsT),
-				joinFloatSlice(round.Calc_Outcome.StochasticValues.RNG_EquipmentCT),
-				joinFloatSlice(round.Calc_Outcome.StochasticValues.RNG_EquipmentT),
-				fmt.Sprintf("%.2f", t1.Funds),
-				fmt.Sprintf("%.2f", t1.Funds_start),
-				fmt.Sprintf("%.2f", t1.Earned),
-				fmt.Sprintf("%.2f", t1.RS_Eq_value),
-				fmt.Sprintf("%.2f", t1.FTE_Eq_value),
-				fmt.Sprintf("%.2f", t1.RE_Eq_value),
-				fmt.Sprintf("%d", t1.Survivors),
-				fmt.Sprintf("%d", t1.Score_Start),
-				fmt.Sprintf("%d", t1.Score_End),
-				fmt.Sprintf("%d", t1.Consecutiveloss),
-				fmt.Sprintf("%d", t1.Consecutiveloss_start),
-				fmt.Sprintf("%d", t1.Consecutivewins),
-				fmt.Sprintf("%d", t1.Consecutivewins_start),
-				fmt.Sprintf("%d", t1.LossBonusLevel),
-				fmt.Sprintf("%.2f", t1.Spent),
-				fmt.Sprintf("%.2f", t2.Funds),
-				fmt.Sprintf("%.2f", t2.Funds_start),
-				fmt.Sprintf("%.2f", t2.Earned),
-				fmt.Sprintf("%.2f", t2.RS_Eq_value),
-				fmt.Sprintf("%.2f", t2.FTE_Eq_value),
-				fmt.Sprintf("%.2f", t2.RE_Eq_value),
-				fmt.Sprintf("%d", t2.Survivors),
-				fmt.Sprintf("%d", t2.Score_Start),
-				fmt.Sprintf("%d", t2.Score_End),
-				fmt.Sprintf("%d", t2.Consecutiveloss),
-				fmt.Sprintf("%d", t2.Consecutiveloss_start),
-				fmt.Sprintf("%d", t2.Consecutivewins),
-				fmt.Sprintf("%d", t2.Consecutivewins_start),
-				fmt.Sprintf("%d", t2.LossBonusLevel),
-				fmt.Sprintf("%.2f", t2.Spent),
-				game.Team1.Name,
-				game.Team1.Strategy,
-				game.Team2.Name,
-				game.Team2.Strategy,
-				game.ID,
-			}
-			writer.Write(row)
+// Column layout of the combined full CSV (mode 2)
+var allDataHeaders = []string{
+	"round_number",
+	"is_t1_ct",
+	"is_t1_winner",
+	"is_ot",
+	"is_ct_winner",
+	"outcome_reason_code",
+	"outcome_ct_wins",
+	"outcome_bomb_planted",
+	"outcome_ct_survivors",
+	"outcome_t_survivors",
+	"csf",
+	"csf_key",
+	"ct_equipment_share_per_player",
+	"t_equipment_share_per_player",
+	"ct_equipment_per_player",
+	"t_equipment_per_player",
+	"rng_csf",
+	"rng_round_outcome",
+	"rng_bombplant",
+	"rng_survivors_ct",
+	"rng_survivors_t",
+	"rng_equipment_ct",
+	"rng_equipment_t",
+	"t1_funds",
+	"t1_funds_start",
+	"t1_earned",
+	"t1_rs_eq_value",
+	"t1_fte_eq_value",
+	"t1_re_eq_value",
+	"t1_survivors",
+	"t1_score_start",
+	"t1_score_end",
+	"t1_consecutive_loss",
+	"t1_consecutive_losses_start",
+	"t1_consecutive_wins",
+	"t1_consecutive_wins_start",
+	"t1_loss_bonus_level",
+	"t1_spent",
+	"t2_funds",
+	"t2_funds_start",
+	"t2_earned",
+	"t2_rs_eq_value",
+	"t2_fte_eq_value",
+	"t2_re_eq_value",
+	"t2_survivors",
+	"t2_score_start",
+	"t2_score_end",
+	"t2_consecutive_loss",
+	"t2_consecutive_losses_start",
+	"t2_consecutive_wins",
+	"t2_consecutive_wins_start",
+	"t2_loss_bonus_level",
+	"t2_spent",
+	"t1_name",
+	"t1_strategy",
+	"t2_name",
+	"t2_strategy",
+	"game_id",
+	"t1_buy_type",
+	"t2_buy_type",
+}
+
+// allDataRows builds the combined full-CSV rows (one per round) of a game
+func allDataRows(game *engine.Game) [][]string {
+	rows := make([][]string, 0, len(game.Rounds))
+	for i := range game.Rounds {
+		round := &game.Rounds[i]
+		t1 := game.Team1.RoundData[i]
+		t2 := game.Team2.RoundData[i]
+		isCTWinner := round.IsT1CT == round.IsT1WinnerTeam
+		row := []string{
+			fmt.Sprintf("%d", round.RoundNumber),
+			fmt.Sprintf("%t", round.IsT1CT),
+			fmt.Sprintf("%t", round.IsT1WinnerTeam),
+			fmt.Sprintf("%t", round.OT),
+			fmt.Sprintf("%t", isCTWinner),
+			fmt.Sprintf("%d", round.Calc_Outcome.ReasonCode),
+			fmt.Sprintf("%t", round.Calc_Outcome.CTWins),
+			fmt.Sprintf("%t", round.Calc_Outcome.BombPlanted),
+			fmt.Sprintf("%d", round.Calc_Outcome.CTSurvivors),
+			fmt.Sprintf("%d", round.Calc_Outcome.TSurvivors),
+			fmt.Sprintf("%.6f", round.Calc_Outcome.CSF),
+			round.Calc_Outcome.CSFKey,
+			joinFloatSlice(round.Calc_Outcome.CTEquipmentSharePerPlayer),
+			joinFloatSlice(round.Calc_Outcome.TEquipmentSharePerPlayer),
+			joinFloatSlice(round.Calc_Outcome.CTEquipmentPerPlayer),
+			joinFloatSlice(round.Calc_Outcome.TEquipmentPerPlayer),
+			fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_CSF),
+			fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_RoundOutcome),
+			fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_Bombplant),
+			fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_SurvivorsCT),
+			fmt.Sprintf("%.6f", round.Calc_Outcome.StochasticValues.RNG_SurvivorsT),
+			joinFloatSlice(round.Calc_Outcome.StochasticValues.RNG_EquipmentCT),
+			joinFloatSlice(round.Calc_Outcome.StochasticValues.RNG_EquipmentT),
+			fmt.Sprintf("%.2f", t1.Funds),
+			fmt.Sprintf("%.2f", t1.Funds_start),
+			fmt.Sprintf("%.2f", t1.Earned),
+			fmt.Sprintf("%.2f", t1.RS_Eq_value),
+			fmt.Sprintf("%.2f", t1.FTE_Eq_value),
+			fmt.Sprintf("%.2f", t1.RE_Eq_value),
+			fmt.Sprintf("%d", t1.Survivors),
+			fmt.Sprintf("%d", t1.Score_Start),
+			fmt.Sprintf("%d", t1.Score_End),
+			fmt.Sprintf("%d", t1.Consecutiveloss),
+			fmt.Sprintf("%d", t1.Consecutiveloss_start),
+			fmt.Sprintf("%d", t1.Consecutivewins),
+			fmt.Sprintf("%d", t1.Consecutivewins_start),
+			fmt.Sprintf("%d", t1.LossBonusLevel),
+			fmt.Sprintf("%.2f", t1.Spent),
+			fmt.Sprintf("%.2f", t2.Funds),
+			fmt.Sprintf("%.2f", t2.Funds_start),
+			fmt.Sprintf("%.2f", t2.Earned),
+			fmt.Sprintf("%.2f", t2.RS_Eq_value),
+			fmt.Sprintf("%.2f", t2.FTE_Eq_value),
+			fmt.Sprintf("%.2f", t2.RE_Eq_value),
+			fmt.Sprintf("%d", t2.Survivors),
+			fmt.Sprintf("%d", t2.Score_Start),
+			fmt.Sprintf("%d", t2.Score_End),
+			fmt.Sprintf("%d", t2.Consecutiveloss),
+			fmt.Sprintf("%d", t2.Consecutiveloss_start),
+			fmt.Sprintf("%d", t2.Consecutivewins),
+			fmt.Sprintf("%d", t2.Consecutivewins_start),
+			fmt.Sprintf("%d", t2.LossBonusLevel),
+			fmt.Sprintf("%.2f", t2.Spent),
+			game.Team1.Name,
+			game.Team1.Strategy,
+			game.Team2.Name,
+			game.Team2.Strategy,
+			game.ID,
+			t1.BuyType,
+			t2.BuyType,
 		}
+		rows = append(rows, row)
 	}
-	return writer.Error()
+	return rows
 }
 
 // 3. Export each game individually with only relevant columns (minimal info per row)
@@ -325,7 +366,7 @@ func ExportGameMinimalCSV(game *engine.Game, path string) error {
 	if path == "" {
 		path = fmt.Sprintf("%s_minimal.csv", game.ID)
 	}
-	file, err := os.Create(path)
+	file, err := CreateFile(path)
 	if err != nil {
 		return err
 	}
@@ -368,6 +409,8 @@ func ExportGameMinimalCSV(game *engine.Game, path string) error {
 		"t2_consecutive_wins",
 		"t2_consecutive_wins_start",
 		"t2_loss_bonus_level",
+		"t1_buy_type",
+		"t2_buy_type",
 	}
 	writer.Write(headers)
 
@@ -410,6 +453,8 @@ func ExportGameMinimalCSV(game *engine.Game, path string) error {
 			fmt.Sprintf("%d", t2.Consecutivewins),
 			fmt.Sprintf("%d", t2.Consecutivewins_start),
 			fmt.Sprintf("%d", t2.LossBonusLevel),
+			t1.BuyType,
+			t2.BuyType,
 		}
 		writer.Write(row)
 	}
@@ -418,102 +463,119 @@ func ExportGameMinimalCSV(game *engine.Game, path string) error {
 
 // 4. Export all games in one file in minimal format
 func ExportAllGamesMinimalCSV(games []*engine.Game, path string) error {
+	return exportAllGamesMinimalCSV(games, path, false)
+}
+
+// AppendAllGamesMinimalCSV appends the rows of games to an existing combined file (header only if the file is new)
+func AppendAllGamesMinimalCSV(games []*engine.Game, path string) error {
+	return exportAllGamesMinimalCSV(games, path, true)
+}
+
+func exportAllGamesMinimalCSV(games []*engine.Game, path string, appendRows bool) error {
 	if path == "" {
 		path = "all_games_minimal.csv"
 	}
-	file, err := os.Create(path)
+	sink, err := NewMinimalCSVSink(path, appendRows)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	writer := csv.NewWriter(file)
-	writer.Comma = ';'
-	defer writer.Flush()
-
-	// Write header row
-	headers := []string{
-		"round_number",
-		"is_t1_winner",
-		"is_t1_ct",
-		"is_ot",
-		"outcome_reason_code",
-		"outcome_bomb_planted",
-		"t1_score_start",
-		"t1_score_end",
-		"t1_spent",
-		"t1_earned",
-		"t1_funds_start",
-		"t1_rs_eq",
-		"t1_fte_eq",
-		"t1_re_eq",
-		"t1_survivors",
-		"t1_consecutive_losses",
-		"t1_consecutive_losses_start",
-		"t1_consecutive_wins",
-		"t1_consecutive_wins_start",
-		"t1_loss_bonus_level",
-		"t2_score_start",
-		"t2_score_end",
-		"t2_spent",
-		"t2_earned",
-		"t2_funds_start",
-		"t2_rs_eq",
-		"t2_fte_eq",
-		"t2_re_eq",
-		"t2_survivors",
-		"t2_consecutive_losses",
-		"t2_consecutive_losses_start",
-		"t2_consecutive_wins",
-		"t2_consecutive_wins_start",
-		"t2_loss_bonus_level",
-		"game_id",
+	for _, game := range games {
+		if err := sink.WriteGame(game); err != nil {
+			sink.Close()
+			return err
+		}
 	}
-	writer.Write(headers)
+	return sink.Close()
+}
 
-	for _, game := range games {
-		for i := range game.Rounds {
-			round := &game.Rounds[i]
-			t1 := game.Team1.RoundData[i]
-			t2 := game.Team2.RoundData[i]
-			row := []string{
-				fmt.Sprintf("%d", round.RoundNumber),
-				fmt.Sprintf("%t", round.IsT1WinnerTeam),
-				fmt.Sprintf("%t", round.IsT1CT),
-				fmt.Sprintf("%t", round.OT),
-				fmt.Sprintf("%d", round.Calc_Outcome.ReasonCode),
-				fmt.Sprintf("%t", round.Calc_Outcome.BombPlanted),
-				fmt.Sprintf("%d", t1.Score_Start),
-				fmt.Sprintf("%d", t1.Score_End),
-				fmt.Sprintf("%.2f", t1.Spent),
-				fmt.Sprintf("%.2f", t1.Earned),
-				fmt.Sprintf("%.2f", t1.Funds_start),
-				fmt.Sprintf("%.2f", t1.RS_Eq_value),
-				fmt.Sprintf("%.2f", t1.FTE_Eq_value),
-				fmt.Sprintf("%.2f", t1.RE_Eq_value),
-				fmt.Sprintf("%d", t1.Survivors),
-				fmt.Sprintf("%d", t1.Consecutiveloss),
-				fmt.Sprintf("%d", t1.Consecutiveloss_start),
-				fmt.Sprintf("%d", t1.Consecutivewins),
-				fmt.Sprintf("%d", t1.Consecutivewins_start),
-				fmt.Sprintf("%d", t1.LossBonusLevel),
-				fmt.Sprintf("%d", t2.Score_Start),
-				fmt.Sprintf("%d", t2.Score_End),
-				fmt.Sprintf("%.2f", t2.Spent),
-				fmt.Sprintf("%.2f", t2.Earned),
-				fmt.Sprintf("%.2f", t2.Funds_start),
-				fmt.Sprintf("%.2f", t2.RS_Eq_value),
-				fmt.Sprintf("%.2f", t2.FTE_Eq_value),
-				fmt.Sprintf("%.2f", t2.RE_Eq_value),
-				fmt.Sprintf("%d", t2.Survivors),
-				fmt.Sprintf("%d", t2.Consecutiveloss),
-				fmt.Sprintf("%d", t2.Consecutiveloss_start),
-				fmt.Sprintf("%d", t2.Consecutivewins),
-				fmt.Sprintf("%d", t2.Consecutivewins_start),
-				fmt.Sprintf("%d", t2.LossBonusLevel),
-				game.ID,
-			}
-			writer.Write(row)
+// Column layout of the combined minimal CSV (mode 4)
+var minimalHeaders = []string{
+	"round_number",
+	"is_t1_winner",
+	"is_t1_ct",
+	"is_ot",
+	"outcome_reason_code",
+	"outcome_bomb_planted",
+	"t1_score_start",
+	"t1_score_end",
+	"t1_spent",
+	"t1_earned",
+	"t1_funds_start",
+	"t1_rs_eq",
+	"t1_fte_eq",
+	"t1_re_eq",
+	"t1_survivors",
+	"t1_consecutive_losses",
+	"t1_consecutive_losses_start",
+	"t1_consecutive_wins",
+	"t1_consecutive_wins_start",
+	"t1_loss_bonus_level",
+	"t2_score_start",
+	"t2_score_end",
+	"t2_spent",
+	"t2_earned",
+	"t2_funds_start",
+	"t2_rs_eq",
+	"t2_fte_eq",
+	"t2_re_eq",
+	"t2_survivors",
+	"t2_consecutive_losses",
+	"t2_consecutive_losses_start",
+	"t2_consecutive_wins",
+	"t2_consecutive_wins_start",
+	"t2_loss_bonus_level",
+	"game_id",
+	"t1_buy_type",
+	"t2_buy_type",
+}
+
+// minimalRows builds the combined minimal-CSV rows (one per round) of a game
+func minimalRows(game *engine.Game) [][]string {
+	rows := make([][]string, 0, len(game.Rounds))
+	for i := range game.Rounds {
+		round := &game.Rounds[i]
+		t1 := game.Team1.RoundData[i]
+		t2 := game.Team2.RoundData[i]
+		row := []string{
+			fmt.Sprintf("%d", round.RoundNumber),
+			fmt.Sprintf("%t", round.IsT1WinnerTeam),
+			fmt.Sprintf("%t", round.IsT1CT),
+			fmt.Sprintf("%t", round.OT),
+			fmt.Sprintf("%d", round.Calc_Outcome.ReasonCode),
+			fmt.Sprintf("%t", round.Calc_Outcome.BombPlanted),
+			fmt.Sprintf("%d", t1.Score_Start),
+			fmt.Sprintf("%d", t1.Score_End),
+			fmt.Sprintf("%.2f", t1.Spent),
+			fmt.Sprintf("%.2f", t1.Earned),
+			fmt.Sprintf("%.2f", t1.Funds_start),
+			fmt.Sprintf("%.2f", t1.RS_Eq_value),
+			fmt.Sprintf("%.2f", t1.FTE_Eq_value),
+			fmt.Sprintf("%.2f", t1.RE_Eq_value),
+			fmt.Sprintf("%d", t1.Survivors),
+			fmt.Sprintf("%d", t1.Consecutiveloss),
+			fmt.Sprintf("%d", t1.Consecutiveloss_start),
+			fmt.Sprintf("%d", t1.Consecutivewins),
+			fmt.Sprintf("%d", t1.Consecutivewins_start),
+			fmt.Sprintf("%d", t1.LossBonusLevel),
+			fmt.Sprintf("%d", t2.Score_Start),
+			fmt.Sprintf("%d", t2.Score_End),
+			fmt.Sprintf("%.2f", t2.Spent),
+			fmt.Sprintf("%.2f", t2.Earned),
+			fmt.Sprintf("%.2f", t2.Funds_start),
+			fmt.Sprintf("%.2f", t2.RS_Eq_value),
+			fmt.Sprintf("%.2f", t2.FTE_Eq_value),
+			fmt.Sprintf("%.2f", t2.RE_Eq_value),
+			fmt.Sprintf("%d", t2.Survivors),
+			fmt.Sprintf("%d", t2.Consecutiveloss),
+			fmt.Sprintf("%d", t2.Consecutiveloss_start),
+			fmt.Sprintf("%d", t2.Consecutivewins),
+			fmt.Sprintf("%d", t2.Consecutivewins_start),
+			fmt.Sprintf("%d", t2.LossBonusLevel),
+			game.ID,
+			t1.BuyType,
+			t2.BuyType,
 		}
+		rows = append(rows, row)
 	}
-	return writer.Error()
+	return rows
 }
@@ -0,0 +1,372 @@
+package util
+
+import (
+	"dbg_abm/internal/engine"
+	"fmt"
+	"os"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRowGroupSize bounds the rows buffered by a Parquet sink before they are written out
+const parquetRowGroupSize = 64 * 1024
+
+// Parquet files use the same columns as the combined CSVs, but typed: numbers stay
+// numbers and the per-player arrays are LIST columns instead of pipe-joined strings.
+
+// ParquetRoundFull is one row of the full round table (mode 2)
+type ParquetRoundFull struct {
+	RoundNumber               int       `parquet:"round_number"`
+	IsT1CT                    bool      `parquet:"is_t1_ct"`
+	IsT1Winner                bool      `parquet:"is_t1_winner"`
+	IsOT                      bool      `parquet:"is_ot"`
+	IsCTWinner                bool      `parquet:"is_ct_winner"`
+	OutcomeReasonCode         int       `parquet:"outcome_reason_code"`
+	OutcomeCTWins             bool      `parquet:"outcome_ct_wins"`
+	OutcomeBombPlanted        bool      `parquet:"outcome_bomb_planted"`
+	OutcomeCTSurvivors        int       `parquet:"outcome_ct_survivors"`
+	OutcomeTSurvivors         int       `parquet:"outcome_t_survivors"`
+	CSF                       float64   `parquet:"csf"`
+	CSFKey                    string    `parquet:"csf_key,dict"`
+	CTEquipmentSharePerPlayer []float64 `parquet:"ct_equipment_share_per_player,list"`
+	TEquipmentSharePerPlayer  []float64 `parquet:"t_equipment_share_per_player,list"`
+	CTEquipmentPerPlayer      []float64 `parquet:"ct_equipment_per_player,list"`
+	TEquipmentPerPlayer       []float64 `parquet:"t_equipment_per_player,list"`
+	RNGCSF                    float64   `parquet:"rng_csf"`
+	RNGRoundOutcome           float64   `parquet:"rng_round_outcome"`
+	RNGBombplant              float64   `parquet:"rng_bombplant"`
+	RNGSurvivorsCT            float64   `parquet:"rng_survivors_ct"`
+	RNGSurvivorsT             float64   `parquet:"rng_survivors_t"`
+	RNGEquipmentCT            []float64 `parquet:"rng_equipment_ct,list"`
+	RNGEquipmentT             []float64 `parquet:"rng_equipment_t,list"`
+
+	T1Funds                  float64 `parquet:"t1_funds"`
+	T1FundsStart             float64 `parquet:"t1_funds_start"`
+	T1Earned                 float64 `parquet:"t1_earned"`
+	T1RSEqValue              float64 `parquet:"t1_rs_eq_value"`
+	T1FTEEqValue             float64 `parquet:"t1_fte_eq_value"`
+	T1REEqValue              float64 `parquet:"t1_re_eq_value"`
+	T1Survivors              int     `parquet:"t1_survivors"`
+	T1ScoreStart             int     `parquet:"t1_score_start"`
+	T1ScoreEnd               int     `parquet:"t1_score_end"`
+	T1ConsecutiveLoss        int     `parquet:"t1_consecutive_loss"`
+	T1ConsecutiveLossesStart int     `parquet:"t1_consecutive_losses_start"`
+	T1ConsecutiveWins        int     `parquet:"t1_consecutive_wins"`
+	T1ConsecutiveWinsStart   int     `parquet:"t1_consecutive_wins_start"`
+	T1LossBonusLevel         int     `parquet:"t1_loss_bonus_level"`
+	T1Spent                  float64 `parquet:"t1_spent"`
+	T2Funds                  float64 `parquet:"t2_funds"`
+	T2FundsStart             float64 `parquet:"t2_funds_start"`
+	T2Earned                 float64 `parquet:"t2_earned"`
+	T2RSEqValue              float64 `parquet:"t2_rs_eq_value"`
+	T2FTEEqValue             float64 `parquet:"t2_fte_eq_value"`
+	T2REEqValue              float64 `parquet:"t2_re_eq_value"`
+	T2Survivors              int     `parquet:"t2_survivors"`
+	T2ScoreStart             int     `parquet:"t2_score_start"`
+	T2ScoreEnd               int     `parquet:"t2_score_end"`
+	T2ConsecutiveLoss        int     `parquet:"t2_consecutive_loss"`
+	T2ConsecutiveLossesStart int     `parquet:"t2_consecutive_losses_start"`
+	T2ConsecutiveWins        int     `parquet:"t2_consecutive_wins"`
+	T2ConsecutiveWinsStart   int     `parquet:"t2_consecutive_wins_start"`
+	T2LossBonusLevel         int     `parquet:"t2_loss_bonus_level"`
+	T2Spent                  float64 `parquet:"t2_spent"`
+	T1Name                   string  `parquet:"t1_name,dict"`
+	T1Strategy               string  `parquet:"t1_strategy,dict"`
+	T2Name                   string  `parquet:"t2_name,dict"`
+	T2Strategy               string  `parquet:"t2_strategy,dict"`
+	GameID                   string  `parquet:"game_id"`
+	T1BuyType                string  `parquet:"t1_buy_type,dict"`
+	T2BuyType                string  `parquet:"t2_buy_type,dict"`
+}
+
+// ParquetRoundMinimal is one row of the minimal round table (mode 4)
+type ParquetRoundMinimal struct {
+	RoundNumber        int  `parquet:"round_number"`
+	IsT1Winner         bool `parquet:"is_t1_winner"`
+	IsT1CT             bool `parquet:"is_t1_ct"`
+	IsOT               bool `parquet:"is_ot"`
+	OutcomeReasonCode  int  `parquet:"outcome_reason_code"`
+	OutcomeBombPlanted bool `parquet:"outcome_bomb_planted"`
+
+	T1ScoreStart             int     `parquet:"t1_score_start"`
+	T1ScoreEnd               int     `parquet:"t1_score_end"`
+	T1Spent                  float64 `parquet:"t1_spent"`
+	T1Earned                 float64 `parquet:"t1_earned"`
+	T1FundsStart             float64 `parquet:"t1_funds_start"`
+	T1RSEq                   float64 `parquet:"t1_rs_eq"`
+	T1FTEEq                  float64 `parquet:"t1_fte_eq"`
+	T1REEq                   float64 `parquet:"t1_re_eq"`
+	T1Survivors              int     `parquet:"t1_survivors"`
+	T1ConsecutiveLosses      int     `parquet:"t1_consecutive_losses"`
+	T1ConsecutiveLossesStart int     `parquet:"t1_consecutive_losses_start"`
+	T1ConsecutiveWins        int     `parquet:"t1_consecutive_wins"`
+	T1ConsecutiveWinsStart   int     `parquet:"t1_consecutive_wins_start"`
+	T1LossBonusLevel         int     `parquet:"t1_loss_bonus_level"`
+	T2ScoreStart             int     `parquet:"t2_score_start"`
+	T2ScoreEnd               int     `parquet:"t2_score_end"`
+	T2Spent                  float64 `parquet:"t2_spent"`
+	T2Earned                 float64 `parquet:"t2_earned"`
+	T2FundsStart             float64 `parquet:"t2_funds_start"`
+	T2RSEq                   float64 `parquet:"t2_rs_eq"`
+	T2FTEEq                  float64 `parquet:"t2_fte_eq"`
+	T2REEq                   float64 `parquet:"t2_re_eq"`
+	T2Survivors              int     `parquet:"t2_survivors"`
+	T2ConsecutiveLosses      int     `parquet:"t2_consecutive_losses"`
+	T2ConsecutiveLossesStart int     `parquet:"t2_consecutive_losses_start"`
+	T2ConsecutiveWins        int     `parquet:"t2_consecutive_wins"`
+	T2ConsecutiveWinsStart   int     `parquet:"t2_consecutive_wins_start"`
+	T2LossBonusLevel         int     `parquet:"t2_loss_bonus_level"`
+	GameID                   string  `parquet:"game_id"`
+	T1BuyType                string  `parquet:"t1_buy_type,dict"`
+	T2BuyType                string  `parquet:"t2_buy_type,dict"`
+}
+
+// fullRounds converts the rounds of a game to rows of the full round table
+func fullRounds(game *engine.Game) []ParquetRoundFull {
+	rows := make([]ParquetRoundFull, 0, len(game.Rounds))
+	for i := range game.Rounds {
+		round := &game.Rounds[i]
+		out := &round.Calc_Outcome
+		rng := &out.StochasticValues
+		t1 := game.Team1.RoundData[i]
+		t2 := game.Team2.RoundData[i]
+		rows = append(rows, ParquetRoundFull{
+			RoundNumber:               round.RoundNumber,
+			IsT1CT:                    round.IsT1CT,
+			IsT1Winner:                round.IsT1WinnerTeam,
+			IsOT:                      round.OT,
+			IsCTWinner:                round.IsT1CT == round.IsT1WinnerTeam,
+			OutcomeReasonCode:         out.ReasonCode,
+			OutcomeCTWins:             out.CTWins,
+			OutcomeBombPlanted:        out.BombPlanted,
+			OutcomeCTSurvivors:        out.CTSurvivors,
+			OutcomeTSurvivors:         out.TSurvivors,
+			CSF:                       out.CSF,
+			CSFKey:                    out.CSFKey,
+			CTEquipmentSharePerPlayer: out.CTEquipmentSharePerPlayer,
+			TEquipmentSharePerPlayer:  out.TEquipmentSharePerPlayer,
+			CTEquipmentPerPlayer:      out.CTEquipmentPerPlayer,
+			TEquipmentPerPlayer:       out.TEquipmentPerPlayer,
+			RNGCSF:                    rng.RNG_CSF,
+			RNGRoundOutcome:           rng.RNG_RoundOutcome,
+			RNGBombplant:              rng.RNG_Bombplant,
+			RNGSurvivorsCT:            rng.RNG_SurvivorsCT,
+			RNGSurvivorsT:             rng.RNG_SurvivorsT,
+			RNGEquipmentCT:            rng.RNG_EquipmentCT,
+			RNGEquipmentT:             rng.RNG_EquipmentT,
+
+			T1Funds:                  t1.Funds,
+			T1FundsStart:             t1.Funds_start,
+			T1Earned:                 t1.Earned,
+			T1RSEqValue:              t1.RS_Eq_value,
+			T1FTEEqValue:             t1.FTE_Eq_value,
+			T1REEqValue:              t1.RE_Eq_value,
+			T1Survivors:              t1.Survivors,
+			T1ScoreStart:             t1.Score_Start,
+			T1ScoreEnd:               t1.Score_End,
+			T1ConsecutiveLoss:        t1.Consecutiveloss,
+			T1ConsecutiveLossesStart: t1.Consecutiveloss_start,
+			T1ConsecutiveWins:        t1.Consecutivewins,
+			T1ConsecutiveWinsStart:   t1.Consecutivewins_start,
+			T1LossBonusLevel:         t1.LossBonusLevel,
+			T1Spent:                  t1.Spent,
+			T2Funds:                  t2.Funds,
+			T2FundsStart:             t2.Funds_start,
+			T2Earned:                 t2.Earned,
+			T2RSEqValue:              t2.RS_Eq_value,
+			T2FTEEqValue:             t2.FTE_Eq_value,
+			T2REEqValue:              t2.RE_Eq_value,
+			T2Survivors:              t2.Survivors,
+			T2ScoreStart:             t2.Score_Start,
+			T2ScoreEnd:               t2.Score_End,
+			T2ConsecutiveLoss:        t2.Consecutiveloss,
+			T2ConsecutiveLossesStart: t2.Consecutiveloss_start,
+			T2ConsecutiveWins:        t2.Consecutivewins,
+			T2ConsecutiveWinsStart:   t2.Consecutivewins_start,
+			T2LossBonusLevel:         t2.LossBonusLevel,
+			T2Spent:                  t2.Spent,
+			T1Name:                   game.Team1.Name,
+			T1Strategy:               game.Team1.Strategy,
+			T2Name:                   game.Team2.Name,
+			T2Strategy:               game.Team2.Strategy,
+			GameID:                   game.ID,
+			T1BuyType:                t1.BuyType,
+			T2BuyType:                t2.BuyType,
+		})
+	}
+	return rows
+}
+
+// minimalRounds converts the rounds of a game to rows of the minimal round table
+func minimalRounds(game *engine.Game) []ParquetRoundMinimal {
+	rows := make([]ParquetRoundMinimal, 0, len(game.Rounds))
+	for i := range game.Rounds {
+		round := &game.Rounds[i]
+		t1 := game.Team1.RoundData[i]
+		t2 := game.Team2.RoundData[i]
+		rows = append(rows, ParquetRoundMinimal{
+			RoundNumber:        round.RoundNumber,
+			IsT1Winner:         round.IsT1WinnerTeam,
+			IsT1CT:             round.IsT1CT,
+			IsOT:               round.OT,
+			OutcomeReasonCode:  round.Calc_Outcome.ReasonCode,
+			OutcomeBombPlanted: round.Calc_Outcome.BombPlanted,
+
+			T1ScoreStart:             t1.Score_Start,
+			T1ScoreEnd:               t1.Score_End,
+			T1Spent:                  t1.Spent,
+			T1Earned:                 t1.Earned,
+			T1FundsStart:             t1.Funds_start,
+			T1RSEq:                   t1.RS_Eq_value,
+			T1FTEEq:                  t1.FTE_Eq_value,
+			T1REEq:                   t1.RE_Eq_value,
+			T1Survivors:              t1.Survivors,
+			T1ConsecutiveLosses:      t1.Consecutiveloss,
+			T1ConsecutiveLossesStart: t1.Consecutiveloss_start,
+			T1ConsecutiveWins:        t1.Consecutivewins,
+			T1ConsecutiveWinsStart:   t1.Consecutivewins_start,
+			T1LossBonusLevel:         t1.LossBonusLevel,
+			T2ScoreStart:             t2.Score_Start,
+			T2ScoreEnd:               t2.Score_End,
+			T2Spent:                  t2.Spent,
+			T2Earned:                 t2.Earned,
+			T2FundsStart:             t2.Funds_start,
+			T2RSEq:                   t2.RS_Eq_value,
+			T2FTEEq:                  t2.FTE_Eq_value,
+			T2REEq:                   t2.RE_Eq_value,
+			T2Survivors:              t2.Survivors,
+			T2ConsecutiveLosses:      t2.Consecutiveloss,
+			T2ConsecutiveLossesStart: t2.Consecutiveloss_start,
+			T2ConsecutiveWins:        t2.Consecutivewins,
+			T2ConsecutiveWinsStart:   t2.Consecutivewins_start,
+			T2LossBonusLevel:         t2.LossBonusLevel,
+			GameID:                   game.ID,
+			T1BuyType:                t1.BuyType,
+			T2BuyType:                t2.BuyType,
+		})
+	}
+	return rows
+}
+
+// gameRows converts a game to its row of the game table
+func gameRows(game *engine.Game) []GameRecord {
+	return []GameRecord{NewGameRecord(game)}
+}
+
+// ParquetSink streams games into a ZSTD-compressed Parquet file with the row type T.
+// Parquet files cannot be appended to; use AppendParquet to merge two files.
+type ParquetSink[T any] struct {
+	file   *os.File
+	writer *parquet.GenericWriter[T]
+	rows   func(game *engine.Game) []T
+}
+
+// NewAllDataParquetSink streams the rounds of every game into the full round table (mode 2)
+func NewAllDataParquetSink(path string) (*ParquetSink[ParquetRoundFull], error) {
+	return newParquetSink(path, fullRounds)
+}
+
+// NewMinimalParquetSink streams the rounds of every game into the minimal round table (mode 4)
+func NewMinimalParquetSink(path string) (*ParquetSink[ParquetRoundMinimal], error) {
+	return newParquetSink(path, minimalRounds)
+}
+
+// NewGamesParquetSink streams one row per game (the fields of GameRecord)
+func NewGamesParquetSink(path string) (*ParquetSink[GameRecord], error) {
+	return newParquetSink(path, gameRows)
+}
+
+func newParquetSink[T any](path string, rows func(*engine.Game) []T) (*ParquetSink[T], error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer := parquet.NewGenericWriter[T](file,
+		parquet.Compression(&parquet.Zstd),
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize))
+	return &ParquetSink[T]{file: file, writer: writer, rows: rows}, nil
+}
+
+// WriteGame writes the rows of a game
+func (s *ParquetSink[T]) WriteGame(game *engine.Game) error {
+	_, err := s.writer.Write(s.rows(game))
+	return err
+}
+
+// Close writes the remaining rows and the file footer and closes the file
+func (s *ParquetSink[T]) Close() error {
+	err := s.writer.Close()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// AppendParquet appends the rows of the Parquet file src to dst (same schema) and removes
+// src. dst is rewritten row group by row group, so memory use does not grow with its size.
+// If dst does not exist, src is renamed to dst.
+func AppendParquet(src, dst string) error {
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		return os.Rename(src, dst)
+	}
+
+	dstFile, closeDst, err := openParquet(dst)
+	if err != nil {
+		return err
+	}
+	defer closeDst()
+	srcFile, closeSrc, err := openParquet(src)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+	if dstFile.Schema().String() != srcFile.Schema().String() {
+		return fmt.Errorf("cannot append %s to %s: different schema", src, dst)
+	}
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	writer := parquet.NewWriter(out, dstFile.Schema(), parquet.Compression(&parquet.Zstd))
+	for _, f := range []*parquet.File{dstFile, srcFile} {
+		for _, rg := range f.RowGroups() {
+			if _, err := writer.WriteRowGroup(rg); err != nil {
+				out.Close()
+				os.Remove(tmp)
+				return fmt.Errorf("failed to append %s to %s: %w", src, dst, err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+func openParquet(path string) (*parquet.File, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return pf, func() { f.Close() }, nil
+}
@@ -3,7 +3,6 @@ package util
 import (
 	"dbg_abm/internal/engine"
 	"encoding/json"
-	"os"
 	"runtime"
 )
 
@@ -19,7 +18,7 @@ func ExportResultsToJSON(results interface{}, path string) error {
 	}
 
 	// Write to file
-	err = os.WriteFile(path, jsonData, 0644)
+	err = WriteFile(path, jsonData)
 
 	// Clear the large JSON data from memory
 	jsonData = nil
@@ -62,19 +61,24 @@ type RoundExportSimple struct {
 	Team2ConsecLoss     int     `json:"team2_consecutive_loss"`
 	Team1LossBonusLevel int     `json:"team1_loss_bonus_level"`
 	Team2LossBonusLevel int     `json:"team2_loss_bonus_level"`
+	Team1BuyType        string  `json:"team1_buy_type"`
+	Team2BuyType        string  `json:"team2_buy_type"`
+	Team1Timeout        bool    `json:"team1_timeout,omitempty"`
+	Team2Timeout        bool    `json:"team2_timeout,omitempty"`
 }
 
 // GameRoundsExport represents all rounds for a complete game
 type GameRoundsExport struct {
-	GameID         string        `json:"game_id"`
-	Team1Name      string        `json:"team1_name"`
-	Team1Strategy  string        `json:"team1_strategy"`
-	Team2Name      string        `json:"team2_name"`
-	Team2Strategy  string        `json:"team2_strategy"`
-	FinalScore     [2]int        `json:"final_score"` // [Team1Score, Team2Score]
-	WentToOvertime bool          `json:"went_to_overtime"`
-	TotalRounds    int           `json:"total_rounds"`
-	Rounds         []RoundExport `json:"rounds"`
+	GameID         string         `json:"game_id"`
+	Team1Name      string         `json:"team1_name"`
+	Team1Strategy  string         `json:"team1_strategy"`
+	Team2Name      string         `json:"team2_name"`
+	Team2Strategy  string         `json:"team2_strategy"`
+	FinalScore     [2]int         `json:"final_score"` // [Team1Score, Team2Score]
+	Outcome        engine.Outcome `json:"outcome"`     // "team1", "team2" or "draw"
+	WentToOvertime bool           `json:"went_to_overtime"`
+	TotalRounds    int            `json:"total_rounds"`
+	Rounds         []RoundExport  `json:"rounds"`
 }
 
 // GameRoundsExportSimple represents all rounds with minimal data
@@ -85,6 +89,7 @@ type GameRoundsExportSimple struct {
 	Team2Name      string              `json:"team2_name"`
 	Team2Strategy  string              `json:"team2_strategy"`
 	FinalScore     [2]int              `json:"final_score"` // [Team1Score, Team2Score]
+	Outcome        engine.Outcome      `json:"outcome"`     // "team1", "team2" or "draw"
 	WentToOvertime bool                `json:"went_to_overtime"`
 	TotalRounds    int                 `json:"total_rounds"`
 	Rounds         []RoundExportSimple `json:"rounds"`
@@ -104,6 +109,7 @@ func ExportRoundsToJSON(game *engine.Game, path string) error {
 		Team2Name:      game.Team2.Name,
 		Team2Strategy:  game.Team2.Strategy,
 		FinalScore:     game.Score,
+		Outcome:        game.Outcome,
 		WentToOvertime: game.OT,
 		TotalRounds:    len(game.Rounds),
 		Rounds:         make([]RoundExport, 0, len(game.Rounds)),
@@ -153,7 +159,7 @@ func ExportRoundsToJSON(game *engine.Game, path string) error {
 	}
 
 	// Write to file
-	err = os.WriteFile(path, jsonData, 0644)
+	err = WriteFile(path, jsonData)
 
 	// Clear from memory
 	jsonData = nil
@@ -178,6 +184,7 @@ func ExportRoundsToJSONSimple(game *engine.Game, path string) error {
 		Team2Name:      game.Team2.Name,
 		Team2Strategy:  game.Team2.Strategy,
 		FinalScore:     game.Score,
+		Outcome:        game.Outcome,
 		WentToOvertime: game.OT,
 		TotalRounds:    len(game.Rounds),
 		Rounds:         make([]RoundExportSimple, 0, len(game.Rounds)),
@@ -221,6 +228,10 @@ func ExportRoundsToJSONSimple(game *engine.Game, path string) error {
 			Team2ConsecLoss:     team2Data.Consecutiveloss,
 			Team1LossBonusLevel: team1Data.LossBonusLevel,
 			Team2LossBonusLevel: team2Data.LossBonusLevel,
+			Team1BuyType:        team1Data.BuyType,
+			Team2BuyType:        team2Data.BuyType,
+			Team1Timeout:        team1Data.Timeout,
+			Team2Timeout:        team2Data.Timeout,
 		}
 
 		export.Rounds = append(export.Rounds, roundExport)
@@ -233,7 +244,7 @@ func ExportRoundsToJSONSimple(game *engine.Game, path string) error {
 	}
 
 	// Write to file
-	err = os.WriteFile(path, jsonData, 0644)
+	err = WriteFile(path, jsonData)
 
 	// Clear from memory
 	jsonData = nil
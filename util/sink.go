@@ -0,0 +1,165 @@
+package util
+
+import (
+	"bufio"
+	"dbg_abm/internal/engine"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// ResultSink receives every finished game of a run and writes it out right away,
+// so combined exports need constant memory regardless of the number of games.
+// WriteGame is called from a single goroutine (the result collector).
+type ResultSink interface {
+	WriteGame(game *engine.Game) error
+	Close() error
+}
+
+// CSVSink writes one or more CSV rows per game
+type CSVSink struct {
+	file   io.WriteCloser
+	writer *csv.Writer
+	rows   func(game *engine.Game) [][]string
+}
+
+// NewAllDataCSVSink streams games in the combined full CSV layout (mode 2).
+// With appendRows the rows are added to an existing file (header only if the file is new).
+func NewAllDataCSVSink(path string, appendRows bool) (*CSVSink, error) {
+	return newCSVSink(path, appendRows, allDataHeaders, allDataRows)
+}
+
+// NewMinimalCSVSink streams games in the combined minimal CSV layout (mode 4).
+// With appendRows the rows are added to an existing file (header only if the file is new).
+func NewMinimalCSVSink(path string, appendRows bool) (*CSVSink, error) {
+	return newCSVSink(path, appendRows, minimalHeaders, minimalRows)
+}
+
+func newCSVSink(path string, appendRows bool, headers []string, rows func(*engine.Game) [][]string) (*CSVSink, error) {
+	file, writeHeader, err := openCombinedCSV(path, appendRows)
+	if err != nil {
+		return nil, err
+	}
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+	if writeHeader {
+		writer.Write(headers)
+	}
+	return &CSVSink{file: file, writer: writer, rows: rows}, nil
+}
+
+// WriteGame writes the rows of a game
+func (s *CSVSink) WriteGame(game *engine.Game) error {
+	for _, row := range s.rows(game) {
+		if err := s.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the buffered rows and closes the file
+func (s *CSVSink) Close() error {
+	s.writer.Flush()
+	err := s.writer.Error()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// JSONLSink writes one JSON object per game (the game summary without rounds)
+type JSONLSink struct {
+	file io.WriteCloser
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+// GameRecord is the per-game line written by JSONLSink (and the row of the Parquet game table)
+type GameRecord struct {
+	GameID        string `json:"game_id" parquet:"game_id"`
+	Seed          int64  `json:"seed" parquet:"seed"`
+	Team1Name     string `json:"team1_name" parquet:"team1_name"`
+	Team1Strategy string `json:"team1_strategy" parquet:"team1_strategy"`
+	Team2Name     string `json:"team2_name" parquet:"team2_name"`
+	Team2Strategy string `json:"team2_strategy" parquet:"team2_strategy"`
+	Team1Score    int    `json:"team1_score" parquet:"team1_score"`
+	Team2Score    int    `json:"team2_score" parquet:"team2_score"`
+	Team1Won      bool   `json:"team1_won" parquet:"team1_won"`
+	Draw          bool   `json:"draw" parquet:"draw"`
+	Rounds        int    `json:"rounds" parquet:"rounds"`
+	OT            bool   `json:"ot" parquet:"ot"`
+	OTCount       int    `json:"ot_count" parquet:"ot_count"`
+}
+
+// NewJSONLSink creates (or with appendRows extends) a JSON Lines file of game records
+func NewJSONLSink(path string, appendRows bool) (*JSONLSink, error) {
+	open := CreateFile
+	if appendRows {
+		open = AppendFile
+	}
+	file, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	return &JSONLSink{file: file, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+// WriteGame writes the record of a game as one line
+func (s *JSONLSink) WriteGame(game *engine.Game) error {
+	return s.enc.Encode(NewGameRecord(game))
+}
+
+// Close flushes the buffered lines and closes the file
+func (s *JSONLSink) Close() error {
+	err := s.buf.Flush()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// NewGameRecord summarizes a finished game
+func NewGameRecord(game *engine.Game) GameRecord {
+	return GameRecord{
+		GameID:        game.ID,
+		Seed:          game.Seed,
+		Team1Name:     game.Team1.Name,
+		Team1Strategy: game.Team1.Strategy,
+		Team2Name:     game.Team2.Name,
+		Team2Strategy: game.Team2.Strategy,
+		Team1Score:    game.Score[0],
+		Team2Score:    game.Score[1],
+		Team1Won:      game.Outcome.Team1Won(),
+		Draw:          game.Outcome.IsDraw(),
+		Rounds:        len(game.Rounds),
+		OT:            game.OT,
+		OTCount:       game.OTcounter,
+	}
+}
+
+// MultiSink fans every game out to several sinks
+type MultiSink []ResultSink
+
+// WriteGame writes the game to every sink and returns the first error
+func (m MultiSink) WriteGame(game *engine.Game) error {
+	var first error
+	for _, s := range m {
+		if err := s.WriteGame(game); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close closes every sink and returns the first error
+func (m MultiSink) Close() error {
+	var first error
+	for _, s := range m {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}